@@ -0,0 +1,78 @@
+package main
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/parflesh/sonarr-operator/pkg/configxml"
+)
+
+// mergeConfigCommand runs mergeConfig instead of the operator.  Sonarr pods run it in an init container to apply
+// the settings of the Sonarr CR before Sonarr starts.
+const mergeConfigCommand = "merge-config"
+
+// configEnvPrefix marks environment variables holding settings for mergeConfig, for values kept in Secrets like the
+// API key (SONARR_CONFIG_ApiKey)
+const configEnvPrefix = "SONARR_CONFIG_"
+
+// mergeConfig merges the settings of the config.xml document at args[0], and of the configEnvPrefix environment
+// variables, into the Sonarr config.xml at args[1], creating it when Sonarr has not written one yet
+func mergeConfig(args []string) error {
+	if len(args) != 2 {
+		return fmt.Errorf("usage: %s %s MANAGED_CONFIG SONARR_CONFIG", os.Args[0], mergeConfigCommand)
+	}
+	managedPath, configPath := args[0], args[1]
+
+	managedData, err := ioutil.ReadFile(managedPath)
+	if err != nil {
+		return err
+	}
+	managed, err := configxml.Parse(managedData)
+	if err != nil {
+		return fmt.Errorf("%s: %v", managedPath, err)
+	}
+	for _, env := range os.Environ() {
+		if !strings.HasPrefix(env, configEnvPrefix) {
+			continue
+		}
+		setting := strings.SplitN(strings.TrimPrefix(env, configEnvPrefix), "=", 2)
+		if len(setting) == 2 && setting[0] != "" {
+			managed = append(managed, configxml.Setting{Name: setting[0], Value: strings.TrimSpace(setting[1])})
+		}
+	}
+
+	mode := os.FileMode(0644)
+	config, err := ioutil.ReadFile(configPath)
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	if info, err := os.Stat(configPath); err == nil {
+		mode = info.Mode()
+	}
+
+	merged, err := configxml.Merge(config, managed)
+	if err != nil {
+		return fmt.Errorf("%s: %v", configPath, err)
+	}
+
+	// Replace the file in one step, so Sonarr never reads a partial config
+	tmp, err := ioutil.TempFile(filepath.Dir(configPath), ".config.xml")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(merged); err != nil {
+		_ = tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := os.Chmod(tmp.Name(), mode); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), configPath)
+}
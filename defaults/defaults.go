@@ -1,6 +1,16 @@
 package defaults
 
 const (
-	SonarrImage        = "quay.io/parflesh/sonarr:latest"
-	OperatorRequeuTime = "1m"
+	SonarrImage         = "quay.io/parflesh/sonarr:latest"
+	OperatorRequeuTime  = "1m"
+	UpdateWindowLength  = "1h"
+	APIKeySecretKey     = "apiKey"
+	SonarrPort          = 8989
+	ConfigMountPath     = "/config"
+	BackupImage         = "registry.access.redhat.com/ubi8/ubi:latest"
+	BackupRetention     = 7
+	OperatorImage       = "quay.io/parflesh/sonarr-operator"
+	SonarrCPURequest    = "100m"
+	SonarrMemoryRequest = "512Mi"
+	SonarrMemoryLimit   = "2Gi"
 )
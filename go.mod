@@ -3,10 +3,14 @@ module github.com/parflesh/sonarr-operator
 go 1.13
 
 require (
+	github.com/Masterminds/semver/v3 v3.1.1
 	github.com/containers/image/v5 v5.2.1
 	github.com/docker/distribution v2.7.1+incompatible
+	github.com/evanphx/json-patch v4.5.0+incompatible
 	github.com/heroku/docker-registry-client v0.0.0-20190909225348-afc9e1acc3d5
+	github.com/opencontainers/go-digest v1.0.0-rc1
 	github.com/operator-framework/operator-sdk v0.15.2
+	github.com/robfig/cron/v3 v3.0.1
 	github.com/spf13/pflag v1.0.5
 	k8s.io/api v0.0.0
 	k8s.io/apimachinery v0.0.0
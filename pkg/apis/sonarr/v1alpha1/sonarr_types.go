@@ -2,7 +2,9 @@ package v1alpha1
 
 import (
 	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
 )
 
 // SonarrSpec defines the desired state of Sonarr
@@ -22,6 +24,14 @@ type SonarrSpec struct {
 	// +optional
 	DisableUpdates bool `json:"disableUpdates,omitempty"`
 
+	// Only restart Sonarr for image updates during this window
+	// +optional
+	UpdateWindow *SonarrSpecUpdateWindow `json:"updateWindow,omitempty"`
+
+	// Follow newer versions of the image tag according to this policy
+	// +optional
+	UpdatePolicy *SonarrSpecUpdatePolicy `json:"updatePolicy,omitempty"`
+
 	// Image pull secret for private container images
 	// +operator-sdk:gen-csv:customresourcedefinitions.specDescriptors=true
 	// +operator-sdk:gen-csv:customresourcedefinitions.specDescriptors.displayName="Image Pull Secret"
@@ -29,6 +39,21 @@ type SonarrSpec struct {
 	// +optional
 	ImagePullSecrets []string `json:"imagePullSecret,omitempty"`
 
+	// Back up Sonarr through its API before changing the image of a running instance
+	// +operator-sdk:gen-csv:customresourcedefinitions.specDescriptors=true
+	// +operator-sdk:gen-csv:customresourcedefinitions.specDescriptors.displayName="Backup Before Update"
+	// +operator-sdk:gen-csv:customresourcedefinitions.specDescriptors.x-descriptors="urn:alm:descriptor:com.tectonic.ui:booleanSwitch,urn:alm:descriptor:com.tectonic.ui:fieldGroup:update"
+	// +optional
+	BackupBeforeUpdate bool `json:"backupBeforeUpdate,omitempty"`
+
+	// Secret holding the Sonarr API key in its apiKey entry.  The key is written to config.xml before Sonarr starts,
+	// and the secret is created with a random key when missing.
+	// +operator-sdk:gen-csv:customresourcedefinitions.specDescriptors=true
+	// +operator-sdk:gen-csv:customresourcedefinitions.specDescriptors.displayName="API Key Secret"
+	// +operator-sdk:gen-csv:customresourcedefinitions.specDescriptors.x-descriptors="urn:alm:descriptor:io.kubernetes:Secret"
+	// +optional
+	APIKeySecret string `json:"apiKeySecret,omitempty"`
+
 	// Time to wait between checking resource status (Default: 1m)
 	// +operator-sdk:gen-csv:customresourcedefinitions.specDescriptors=true
 	// +operator-sdk:gen-csv:customresourcedefinitions.specDescriptors.displayName="Watch Frequency"
@@ -43,6 +68,42 @@ type SonarrSpec struct {
 	// +optional
 	PriorityClassName string `json:"priorityClassName,omitempty"`
 
+	// Labels of the nodes Sonarr, backup and restore pods may run on
+	// +operator-sdk:gen-csv:customresourcedefinitions.specDescriptors=true
+	// +operator-sdk:gen-csv:customresourcedefinitions.specDescriptors.displayName="Node Selector"
+	// +operator-sdk:gen-csv:customresourcedefinitions.specDescriptors.x-descriptors="urn:alm:descriptor:com.tectonic.ui:selector:core:v1:Node,urn:alm:descriptor:com.tectonic.ui:fieldGroup:pod"
+	// +optional
+	NodeSelector map[string]string `json:"nodeSelector,omitempty"`
+
+	// Node and pod affinity of Sonarr, backup and restore pods.  Backup pods also prefer the node running Sonarr.
+	// +operator-sdk:gen-csv:customresourcedefinitions.specDescriptors=true
+	// +operator-sdk:gen-csv:customresourcedefinitions.specDescriptors.displayName="Affinity"
+	// +operator-sdk:gen-csv:customresourcedefinitions.specDescriptors.x-descriptors="urn:alm:descriptor:com.tectonic.ui:nodeAffinity,urn:alm:descriptor:com.tectonic.ui:fieldGroup:pod"
+	// +optional
+	Affinity *corev1.Affinity `json:"affinity,omitempty"`
+
+	// Taints tolerated by Sonarr, backup and restore pods
+	// +operator-sdk:gen-csv:customresourcedefinitions.specDescriptors=true
+	// +operator-sdk:gen-csv:customresourcedefinitions.specDescriptors.displayName="Tolerations"
+	// +operator-sdk:gen-csv:customresourcedefinitions.specDescriptors.x-descriptors="urn:alm:descriptor:com.tectonic.ui:fieldGroup:pod"
+	// +optional
+	Tolerations []corev1.Toleration `json:"tolerations,omitempty"`
+
+	// How Sonarr pods spread across zones or nodes.  Constraints without a label selector select the Sonarr pods by the
+	// label sonarr=<name of the Sonarr>.
+	// +operator-sdk:gen-csv:customresourcedefinitions.specDescriptors=true
+	// +operator-sdk:gen-csv:customresourcedefinitions.specDescriptors.displayName="Topology Spread Constraints"
+	// +operator-sdk:gen-csv:customresourcedefinitions.specDescriptors.x-descriptors="urn:alm:descriptor:com.tectonic.ui:fieldGroup:pod"
+	// +optional
+	TopologySpreadConstraints []corev1.TopologySpreadConstraint `json:"topologySpreadConstraints,omitempty"`
+
+	// Compute resources of the Sonarr container (Default: requests of 100m CPU and 512Mi memory, limit of 2Gi memory)
+	// +operator-sdk:gen-csv:customresourcedefinitions.specDescriptors=true
+	// +operator-sdk:gen-csv:customresourcedefinitions.specDescriptors.displayName="Resources"
+	// +operator-sdk:gen-csv:customresourcedefinitions.specDescriptors.x-descriptors="urn:alm:descriptor:com.tectonic.ui:resourceRequirements,urn:alm:descriptor:com.tectonic.ui:fieldGroup:pod"
+	// +optional
+	Resources *corev1.ResourceRequirements `json:"resources,omitempty"`
+
 	// Run as User Id
 	// +operator-sdk:gen-csv:customresourcedefinitions.specDescriptors=true
 	// +operator-sdk:gen-csv:customresourcedefinitions.specDescriptors.displayName="User ID"
@@ -67,6 +128,106 @@ type SonarrSpec struct {
 	// +listType=atomic
 	// +optional
 	Volumes []SonarrSpecVolume `json:"volumes,omitempty"`
+
+	// Root folders added to Sonarr through its API.  Each must be on one of the volumes, and the API key secret must
+	// be set.
+	// +operator-sdk:gen-csv:customresourcedefinitions.specDescriptors=true
+	// +operator-sdk:gen-csv:customresourcedefinitions.specDescriptors.displayName="Root Folders"
+	// +listType=set
+	// +optional
+	RootFolders []string `json:"rootFolders,omitempty"`
+
+	// Remove root folders not listed in rootFolders from Sonarr.  Series files are kept.
+	// +operator-sdk:gen-csv:customresourcedefinitions.specDescriptors=true
+	// +operator-sdk:gen-csv:customresourcedefinitions.specDescriptors.displayName="Prune Root Folders"
+	// +operator-sdk:gen-csv:customresourcedefinitions.specDescriptors.x-descriptors="urn:alm:descriptor:com.tectonic.ui:booleanSwitch"
+	// +optional
+	PruneRootFolders bool `json:"pruneRootFolders,omitempty"`
+
+	// Media management settings applied to Sonarr through its API.  Settings left empty keep the value in Sonarr.
+	// Requires the API key secret.
+	// +optional
+	MediaManagement *SonarrSpecMediaManagement `json:"mediaManagement,omitempty"`
+
+	// How differences between the settings in Sonarr and those of the spec (config, media management, root folders,
+	// download clients, indexers, profiles and notifications) are handled: Enforce changes Sonarr to match the spec,
+	// Detect only reports them in the Drifted condition and Events, and Ignore neither compares nor changes the
+	// settings (Default: Enforce).  Settings of config are still written to config.xml when Sonarr starts.
+	// +operator-sdk:gen-csv:customresourcedefinitions.specDescriptors=true
+	// +operator-sdk:gen-csv:customresourcedefinitions.specDescriptors.displayName="Drift Mode"
+	// +operator-sdk:gen-csv:customresourcedefinitions.specDescriptors.x-descriptors="urn:alm:descriptor:com.tectonic.ui:select:Enforce,urn:alm:descriptor:com.tectonic.ui:select:Detect,urn:alm:descriptor:com.tectonic.ui:select:Ignore"
+	// +kubebuilder:validation:Enum=Enforce;Detect;Ignore
+	// +optional
+	DriftMode string `json:"driftMode,omitempty"`
+
+	// Download clients added to Sonarr through its API, identified by name.  Requires the API key secret.
+	// +operator-sdk:gen-csv:customresourcedefinitions.specDescriptors=true
+	// +operator-sdk:gen-csv:customresourcedefinitions.specDescriptors.displayName="Download Clients"
+	// +listType=map
+	// +listMapKey=name
+	// +optional
+	DownloadClients []SonarrSpecDownloadClient `json:"downloadClients,omitempty"`
+
+	// Indexers added to Sonarr through its API, identified by name.  Requires the API key secret.
+	// +operator-sdk:gen-csv:customresourcedefinitions.specDescriptors=true
+	// +operator-sdk:gen-csv:customresourcedefinitions.specDescriptors.displayName="Indexers"
+	// +listType=map
+	// +listMapKey=name
+	// +optional
+	Indexers []SonarrSpecIndexer `json:"indexers,omitempty"`
+
+	// Custom formats added to Sonarr v4 through its API, identified by name.  Requires the API key secret.
+	// +operator-sdk:gen-csv:customresourcedefinitions.specDescriptors=true
+	// +operator-sdk:gen-csv:customresourcedefinitions.specDescriptors.displayName="Custom Formats"
+	// +listType=map
+	// +listMapKey=name
+	// +optional
+	CustomFormats []SonarrSpecCustomFormat `json:"customFormats,omitempty"`
+
+	// Quality profiles added to Sonarr through its API, identified by name.  Requires the API key secret.
+	// +operator-sdk:gen-csv:customresourcedefinitions.specDescriptors=true
+	// +operator-sdk:gen-csv:customresourcedefinitions.specDescriptors.displayName="Quality Profiles"
+	// +listType=map
+	// +listMapKey=name
+	// +optional
+	QualityProfiles []SonarrSpecQualityProfile `json:"qualityProfiles,omitempty"`
+
+	// Release profiles added to Sonarr through its API, identified by name.  Requires the API key secret.
+	// +operator-sdk:gen-csv:customresourcedefinitions.specDescriptors=true
+	// +operator-sdk:gen-csv:customresourcedefinitions.specDescriptors.displayName="Release Profiles"
+	// +listType=map
+	// +listMapKey=name
+	// +optional
+	ReleaseProfiles []SonarrSpecReleaseProfile `json:"releaseProfiles,omitempty"`
+
+	// Notifications (Connect in the Sonarr UI) added to Sonarr through its API, identified by name.  Requires the API
+	// key secret.
+	// +operator-sdk:gen-csv:customresourcedefinitions.specDescriptors=true
+	// +operator-sdk:gen-csv:customresourcedefinitions.specDescriptors.displayName="Notifications"
+	// +listType=map
+	// +listMapKey=name
+	// +optional
+	Notifications []SonarrSpecNotification `json:"notifications,omitempty"`
+
+	// Scheduled backups of the Sonarr configuration volume
+	// +optional
+	Backup *SonarrSpecBackup `json:"backup,omitempty"`
+
+	// Restore the configuration volume from a backup archive.  A restore runs once for each claim and path.
+	// +optional
+	Restore *SonarrSpecRestore `json:"restore,omitempty"`
+
+	// Settings written to Sonarr's config.xml before Sonarr starts.  Settings left empty keep the value in config.xml.
+	// +optional
+	Config *SonarrSpecConfig `json:"config,omitempty"`
+
+	// Service exposing Sonarr
+	// +optional
+	Service *SonarrSpecService `json:"service,omitempty"`
+
+	// Expose Sonarr through an Ingress, or a Route on OpenShift
+	// +optional
+	Ingress *SonarrSpecIngress `json:"ingress,omitempty"`
 }
 
 type SonarrSpecVolume struct {
@@ -99,6 +260,687 @@ type SonarrSpecVolume struct {
 	SubPath string `json:"subPath,omitempty"`
 }
 
+type SonarrSpecUpdateWindow struct {
+	// Cron expression for the start of the window (e.g. "0 3 * * *")
+	// +operator-sdk:gen-csv:customresourcedefinitions.specDescriptors=true
+	// +operator-sdk:gen-csv:customresourcedefinitions.specDescriptors.displayName="Update Window Schedule"
+	// +operator-sdk:gen-csv:customresourcedefinitions.specDescriptors.x-descriptors="urn:alm:descriptor:com.tectonic.ui:text,urn:alm:descriptor:com.tectonic.ui:fieldGroup:update"
+	Schedule string `json:"schedule"`
+
+	// Time zone of the schedule (Default: UTC)
+	// +operator-sdk:gen-csv:customresourcedefinitions.specDescriptors=true
+	// +operator-sdk:gen-csv:customresourcedefinitions.specDescriptors.displayName="Update Window Time Zone"
+	// +operator-sdk:gen-csv:customresourcedefinitions.specDescriptors.x-descriptors="urn:alm:descriptor:com.tectonic.ui:text,urn:alm:descriptor:com.tectonic.ui:fieldGroup:update"
+	// +optional
+	TimeZone string `json:"timeZone,omitempty"`
+
+	// Length of the window (Default: 1h)
+	// +operator-sdk:gen-csv:customresourcedefinitions.specDescriptors=true
+	// +operator-sdk:gen-csv:customresourcedefinitions.specDescriptors.displayName="Update Window Duration"
+	// +operator-sdk:gen-csv:customresourcedefinitions.specDescriptors.x-descriptors="urn:alm:descriptor:com.tectonic.ui:text,urn:alm:descriptor:com.tectonic.ui:fieldGroup:update"
+	// +optional
+	Duration string `json:"duration,omitempty"`
+}
+
+// Update strategies, from the most to the least conservative
+const (
+	UpdateStrategyNone  = "none"
+	UpdateStrategyPatch = "patch"
+	UpdateStrategyMinor = "minor"
+	UpdateStrategyMajor = "major"
+)
+
+type SonarrSpecUpdatePolicy struct {
+	// Largest version change applied automatically, relative to the image tag: none, patch, minor or major (Default: none)
+	// Strategies other than none need an image tag holding a version, like 3.0.10.1567, where the fourth part is the
+	// build number.
+	// +operator-sdk:gen-csv:customresourcedefinitions.specDescriptors=true
+	// +operator-sdk:gen-csv:customresourcedefinitions.specDescriptors.displayName="Update Strategy"
+	// +operator-sdk:gen-csv:customresourcedefinitions.specDescriptors.x-descriptors="urn:alm:descriptor:com.tectonic.ui:select:none,urn:alm:descriptor:com.tectonic.ui:select:patch,urn:alm:descriptor:com.tectonic.ui:select:minor,urn:alm:descriptor:com.tectonic.ui:select:major,urn:alm:descriptor:com.tectonic.ui:fieldGroup:update"
+	// +kubebuilder:validation:Enum=none;patch;minor;major
+	// +optional
+	Strategy string `json:"strategy,omitempty"`
+
+	// Regular expression selecting the tags to consider, its first capture group (if any) holds the version
+	// +operator-sdk:gen-csv:customresourcedefinitions.specDescriptors=true
+	// +operator-sdk:gen-csv:customresourcedefinitions.specDescriptors.displayName="Tag Pattern"
+	// +operator-sdk:gen-csv:customresourcedefinitions.specDescriptors.x-descriptors="urn:alm:descriptor:com.tectonic.ui:text,urn:alm:descriptor:com.tectonic.ui:fieldGroup:update"
+	// +optional
+	TagPattern string `json:"tagPattern,omitempty"`
+}
+
+type SonarrSpecBackup struct {
+	// Cron expression for running backups (e.g. "0 4 * * *")
+	// +operator-sdk:gen-csv:customresourcedefinitions.specDescriptors=true
+	// +operator-sdk:gen-csv:customresourcedefinitions.specDescriptors.displayName="Backup Schedule"
+	// +operator-sdk:gen-csv:customresourcedefinitions.specDescriptors.x-descriptors="urn:alm:descriptor:com.tectonic.ui:text,urn:alm:descriptor:com.tectonic.ui:fieldGroup:backup"
+	Schedule string `json:"schedule"`
+
+	// Number of backup archives to keep (Default: 7)
+	// +operator-sdk:gen-csv:customresourcedefinitions.specDescriptors=true
+	// +operator-sdk:gen-csv:customresourcedefinitions.specDescriptors.displayName="Backup Retention"
+	// +operator-sdk:gen-csv:customresourcedefinitions.specDescriptors.x-descriptors="urn:alm:descriptor:com.tectonic.ui:number,urn:alm:descriptor:com.tectonic.ui:fieldGroup:backup"
+	// +optional
+	Retention int32 `json:"retention,omitempty"`
+
+	// Persistent Volume Claim receiving the backup archives
+	// +operator-sdk:gen-csv:customresourcedefinitions.specDescriptors=true
+	// +operator-sdk:gen-csv:customresourcedefinitions.specDescriptors.displayName="Backup Volume Claim"
+	// +operator-sdk:gen-csv:customresourcedefinitions.specDescriptors.x-descriptors="urn:alm:descriptor:io.kubernetes:PersistentVolumeClaim,urn:alm:descriptor:com.tectonic.ui:fieldGroup:backup"
+	Claim string `json:"claim"`
+
+	// Container image with sh and tar used to run backups (Default: registry.access.redhat.com/ubi8/ubi:latest)
+	// +operator-sdk:gen-csv:customresourcedefinitions.specDescriptors=true
+	// +operator-sdk:gen-csv:customresourcedefinitions.specDescriptors.displayName="Backup Image"
+	// +operator-sdk:gen-csv:customresourcedefinitions.specDescriptors.x-descriptors="urn:alm:descriptor:com.tectonic.ui:text,urn:alm:descriptor:com.tectonic.ui:fieldGroup:backup"
+	// +optional
+	Image string `json:"image,omitempty"`
+}
+
+type SonarrSpecRestore struct {
+	// Persistent Volume Claim holding the backup archive
+	// +operator-sdk:gen-csv:customresourcedefinitions.specDescriptors=true
+	// +operator-sdk:gen-csv:customresourcedefinitions.specDescriptors.displayName="Restore Volume Claim"
+	// +operator-sdk:gen-csv:customresourcedefinitions.specDescriptors.x-descriptors="urn:alm:descriptor:io.kubernetes:PersistentVolumeClaim,urn:alm:descriptor:com.tectonic.ui:fieldGroup:restore"
+	Claim string `json:"claim"`
+
+	// Path of the backup archive (Sonarr .zip backup or .tar.gz from scheduled backups) within the claim
+	// +operator-sdk:gen-csv:customresourcedefinitions.specDescriptors=true
+	// +operator-sdk:gen-csv:customresourcedefinitions.specDescriptors.displayName="Restore Archive Path"
+	// +operator-sdk:gen-csv:customresourcedefinitions.specDescriptors.x-descriptors="urn:alm:descriptor:com.tectonic.ui:text,urn:alm:descriptor:com.tectonic.ui:fieldGroup:restore"
+	Path string `json:"path"`
+
+	// Container image with sh, tar and python3 used to extract the archive (Default: registry.access.redhat.com/ubi8/ubi:latest)
+	// +operator-sdk:gen-csv:customresourcedefinitions.specDescriptors=true
+	// +operator-sdk:gen-csv:customresourcedefinitions.specDescriptors.displayName="Restore Image"
+	// +operator-sdk:gen-csv:customresourcedefinitions.specDescriptors.x-descriptors="urn:alm:descriptor:com.tectonic.ui:text,urn:alm:descriptor:com.tectonic.ui:fieldGroup:restore"
+	// +optional
+	Image string `json:"image,omitempty"`
+}
+
+type SonarrSpecConfig struct {
+	// Port Sonarr listens on (Default: 8989)
+	// +operator-sdk:gen-csv:customresourcedefinitions.specDescriptors=true
+	// +operator-sdk:gen-csv:customresourcedefinitions.specDescriptors.displayName="Port"
+	// +operator-sdk:gen-csv:customresourcedefinitions.specDescriptors.x-descriptors="urn:alm:descriptor:com.tectonic.ui:number,urn:alm:descriptor:com.tectonic.ui:fieldGroup:config"
+	// +optional
+	Port int32 `json:"port,omitempty"`
+
+	// URL base Sonarr is served on, for reverse proxies (e.g. /sonarr)
+	// +operator-sdk:gen-csv:customresourcedefinitions.specDescriptors=true
+	// +operator-sdk:gen-csv:customresourcedefinitions.specDescriptors.displayName="URL Base"
+	// +operator-sdk:gen-csv:customresourcedefinitions.specDescriptors.x-descriptors="urn:alm:descriptor:com.tectonic.ui:text,urn:alm:descriptor:com.tectonic.ui:fieldGroup:config"
+	// +optional
+	URLBase string `json:"urlBase,omitempty"`
+
+	// Authentication method: None, Basic or Forms
+	// +operator-sdk:gen-csv:customresourcedefinitions.specDescriptors=true
+	// +operator-sdk:gen-csv:customresourcedefinitions.specDescriptors.displayName="Authentication Method"
+	// +operator-sdk:gen-csv:customresourcedefinitions.specDescriptors.x-descriptors="urn:alm:descriptor:com.tectonic.ui:select:None,urn:alm:descriptor:com.tectonic.ui:select:Basic,urn:alm:descriptor:com.tectonic.ui:select:Forms,urn:alm:descriptor:com.tectonic.ui:fieldGroup:config"
+	// +kubebuilder:validation:Enum=None;Basic;Forms
+	// +optional
+	AuthenticationMethod string `json:"authenticationMethod,omitempty"`
+
+	// Log level: info, debug or trace
+	// +operator-sdk:gen-csv:customresourcedefinitions.specDescriptors=true
+	// +operator-sdk:gen-csv:customresourcedefinitions.specDescriptors.displayName="Log Level"
+	// +operator-sdk:gen-csv:customresourcedefinitions.specDescriptors.x-descriptors="urn:alm:descriptor:com.tectonic.ui:select:info,urn:alm:descriptor:com.tectonic.ui:select:debug,urn:alm:descriptor:com.tectonic.ui:select:trace,urn:alm:descriptor:com.tectonic.ui:fieldGroup:config"
+	// +kubebuilder:validation:Enum=info;debug;trace
+	// +optional
+	LogLevel string `json:"logLevel,omitempty"`
+
+	// Update branch (e.g. main, develop)
+	// +operator-sdk:gen-csv:customresourcedefinitions.specDescriptors=true
+	// +operator-sdk:gen-csv:customresourcedefinitions.specDescriptors.displayName="Branch"
+	// +operator-sdk:gen-csv:customresourcedefinitions.specDescriptors.x-descriptors="urn:alm:descriptor:com.tectonic.ui:text,urn:alm:descriptor:com.tectonic.ui:fieldGroup:config"
+	// +optional
+	Branch string `json:"branch,omitempty"`
+
+	// Send anonymous usage data to Sonarr
+	// +operator-sdk:gen-csv:customresourcedefinitions.specDescriptors=true
+	// +operator-sdk:gen-csv:customresourcedefinitions.specDescriptors.displayName="Analytics"
+	// +operator-sdk:gen-csv:customresourcedefinitions.specDescriptors.x-descriptors="urn:alm:descriptor:com.tectonic.ui:booleanSwitch,urn:alm:descriptor:com.tectonic.ui:fieldGroup:config"
+	// +optional
+	AnalyticsEnabled *bool `json:"analyticsEnabled,omitempty"`
+
+	// Serve HTTPS on the SSL port in addition to HTTP
+	// +operator-sdk:gen-csv:customresourcedefinitions.specDescriptors=true
+	// +operator-sdk:gen-csv:customresourcedefinitions.specDescriptors.displayName="Enable SSL"
+	// +operator-sdk:gen-csv:customresourcedefinitions.specDescriptors.x-descriptors="urn:alm:descriptor:com.tectonic.ui:booleanSwitch,urn:alm:descriptor:com.tectonic.ui:fieldGroup:config"
+	// +optional
+	EnableSSL *bool `json:"enableSsl,omitempty"`
+
+	// Port Sonarr serves HTTPS on
+	// +operator-sdk:gen-csv:customresourcedefinitions.specDescriptors=true
+	// +operator-sdk:gen-csv:customresourcedefinitions.specDescriptors.displayName="SSL Port"
+	// +operator-sdk:gen-csv:customresourcedefinitions.specDescriptors.x-descriptors="urn:alm:descriptor:com.tectonic.ui:number,urn:alm:descriptor:com.tectonic.ui:fieldGroup:config"
+	// +optional
+	SSLPort int32 `json:"sslPort,omitempty"`
+
+	// Path of the PFX certificate within the pod
+	// +operator-sdk:gen-csv:customresourcedefinitions.specDescriptors=true
+	// +operator-sdk:gen-csv:customresourcedefinitions.specDescriptors.displayName="SSL Certificate Path"
+	// +operator-sdk:gen-csv:customresourcedefinitions.specDescriptors.x-descriptors="urn:alm:descriptor:com.tectonic.ui:text,urn:alm:descriptor:com.tectonic.ui:fieldGroup:config"
+	// +optional
+	SSLCertPath string `json:"sslCertPath,omitempty"`
+}
+
+// Drift modes
+const (
+	DriftEnforce = "Enforce"
+	DriftDetect  = "Detect"
+	DriftIgnore  = "Ignore"
+)
+
+type SonarrSpecMediaManagement struct {
+	// Create folders for series without episode files
+	// +optional
+	CreateEmptySeriesFolders *bool `json:"createEmptySeriesFolders,omitempty"`
+
+	// Delete series and season folders left empty
+	// +optional
+	DeleteEmptyFolders *bool `json:"deleteEmptyFolders,omitempty"`
+
+	// Import completed downloads as hardlinks rather than copies, when on the same volume
+	// +optional
+	UseHardlinks *bool `json:"useHardlinks,omitempty"`
+
+	// Import extra files, like subtitles, found with the episodes
+	// +optional
+	ImportExtraFiles *bool `json:"importExtraFiles,omitempty"`
+
+	// Extensions of the extra files imported (e.g. srt, nfo)
+	// +optional
+	ExtraFileExtensions []string `json:"extraFileExtensions,omitempty"`
+
+	// Folder deleted episode files are moved to
+	// +optional
+	RecycleBin string `json:"recycleBin,omitempty"`
+
+	// Days files are kept in the recycle bin, 0 keeping them forever
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	RecycleBinCleanupDays *int32 `json:"recycleBinCleanupDays,omitempty"`
+
+	// Free space, in MB, required on the root folder to import an episode
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	MinimumFreeSpaceMB *int32 `json:"minimumFreeSpaceMB,omitempty"`
+
+	// Set the permissions of imported files and folders
+	// +optional
+	SetPermissions *bool `json:"setPermissions,omitempty"`
+
+	// Octal mode of the folders created, files getting it without the execute bits (e.g. 755)
+	// +kubebuilder:validation:Pattern=`^[0-7]{3,4}$`
+	// +optional
+	ChmodFolder string `json:"chmodFolder,omitempty"`
+}
+
+type SonarrSpecService struct {
+	// Service type: ClusterIP, NodePort or LoadBalancer (Default: ClusterIP)
+	// +operator-sdk:gen-csv:customresourcedefinitions.specDescriptors=true
+	// +operator-sdk:gen-csv:customresourcedefinitions.specDescriptors.displayName="Service Type"
+	// +operator-sdk:gen-csv:customresourcedefinitions.specDescriptors.x-descriptors="urn:alm:descriptor:com.tectonic.ui:select:ClusterIP,urn:alm:descriptor:com.tectonic.ui:select:NodePort,urn:alm:descriptor:com.tectonic.ui:select:LoadBalancer,urn:alm:descriptor:com.tectonic.ui:fieldGroup:service"
+	// +kubebuilder:validation:Enum=ClusterIP;NodePort;LoadBalancer
+	// +optional
+	Type string `json:"type,omitempty"`
+
+	// Port of the service (Default: 8989)
+	// +operator-sdk:gen-csv:customresourcedefinitions.specDescriptors=true
+	// +operator-sdk:gen-csv:customresourcedefinitions.specDescriptors.displayName="Service Port"
+	// +operator-sdk:gen-csv:customresourcedefinitions.specDescriptors.x-descriptors="urn:alm:descriptor:com.tectonic.ui:number,urn:alm:descriptor:com.tectonic.ui:fieldGroup:service"
+	// +optional
+	Port int32 `json:"port,omitempty"`
+
+	// Node port for NodePort and LoadBalancer services (Default: assigned by the cluster)
+	// +operator-sdk:gen-csv:customresourcedefinitions.specDescriptors=true
+	// +operator-sdk:gen-csv:customresourcedefinitions.specDescriptors.displayName="Node Port"
+	// +operator-sdk:gen-csv:customresourcedefinitions.specDescriptors.x-descriptors="urn:alm:descriptor:com.tectonic.ui:number,urn:alm:descriptor:com.tectonic.ui:fieldGroup:service"
+	// +optional
+	NodePort int32 `json:"nodePort,omitempty"`
+
+	// Load balancer IP for LoadBalancer services
+	// +operator-sdk:gen-csv:customresourcedefinitions.specDescriptors=true
+	// +operator-sdk:gen-csv:customresourcedefinitions.specDescriptors.displayName="Load Balancer IP"
+	// +operator-sdk:gen-csv:customresourcedefinitions.specDescriptors.x-descriptors="urn:alm:descriptor:com.tectonic.ui:text,urn:alm:descriptor:com.tectonic.ui:fieldGroup:service"
+	// +optional
+	LoadBalancerIP string `json:"loadBalancerIP,omitempty"`
+
+	// External traffic policy for NodePort and LoadBalancer services: Cluster or Local (Default: Cluster)
+	// +operator-sdk:gen-csv:customresourcedefinitions.specDescriptors=true
+	// +operator-sdk:gen-csv:customresourcedefinitions.specDescriptors.displayName="External Traffic Policy"
+	// +operator-sdk:gen-csv:customresourcedefinitions.specDescriptors.x-descriptors="urn:alm:descriptor:com.tectonic.ui:select:Cluster,urn:alm:descriptor:com.tectonic.ui:select:Local,urn:alm:descriptor:com.tectonic.ui:fieldGroup:service"
+	// +kubebuilder:validation:Enum=Cluster;Local
+	// +optional
+	ExternalTrafficPolicy string `json:"externalTrafficPolicy,omitempty"`
+
+	// Annotations added to the service
+	// +optional
+	Annotations map[string]string `json:"annotations,omitempty"`
+}
+
+type SonarrSpecIngress struct {
+	// Host name Sonarr is served on
+	// +operator-sdk:gen-csv:customresourcedefinitions.specDescriptors=true
+	// +operator-sdk:gen-csv:customresourcedefinitions.specDescriptors.displayName="Ingress Host"
+	// +operator-sdk:gen-csv:customresourcedefinitions.specDescriptors.x-descriptors="urn:alm:descriptor:com.tectonic.ui:text,urn:alm:descriptor:com.tectonic.ui:fieldGroup:ingress"
+	Host string `json:"host"`
+
+	// Path Sonarr is served on, Sonarr's URL base must match (Default: /)
+	// +operator-sdk:gen-csv:customresourcedefinitions.specDescriptors=true
+	// +operator-sdk:gen-csv:customresourcedefinitions.specDescriptors.displayName="Ingress Path"
+	// +operator-sdk:gen-csv:customresourcedefinitions.specDescriptors.x-descriptors="urn:alm:descriptor:com.tectonic.ui:text,urn:alm:descriptor:com.tectonic.ui:fieldGroup:ingress"
+	// +optional
+	Path string `json:"path,omitempty"`
+
+	// TLS secret (tls.crt and tls.key) for the host, enables HTTPS
+	// +operator-sdk:gen-csv:customresourcedefinitions.specDescriptors=true
+	// +operator-sdk:gen-csv:customresourcedefinitions.specDescriptors.displayName="TLS Secret"
+	// +operator-sdk:gen-csv:customresourcedefinitions.specDescriptors.x-descriptors="urn:alm:descriptor:io.kubernetes:Secret,urn:alm:descriptor:com.tectonic.ui:fieldGroup:ingress"
+	// +optional
+	TLSSecret string `json:"tlsSecret,omitempty"`
+
+	// Ingress class, set as the kubernetes.io/ingress.class annotation.  Not used for Routes.
+	// +operator-sdk:gen-csv:customresourcedefinitions.specDescriptors=true
+	// +operator-sdk:gen-csv:customresourcedefinitions.specDescriptors.displayName="Ingress Class"
+	// +operator-sdk:gen-csv:customresourcedefinitions.specDescriptors.x-descriptors="urn:alm:descriptor:com.tectonic.ui:text,urn:alm:descriptor:com.tectonic.ui:fieldGroup:ingress"
+	// +optional
+	IngressClass string `json:"ingressClass,omitempty"`
+
+	// Annotations added to the Ingress or Route
+	// +optional
+	Annotations map[string]string `json:"annotations,omitempty"`
+}
+
+type SonarrSpecDownloadClient struct {
+	// Name of the download client in Sonarr
+	Name string `json:"name"`
+
+	// Download client software
+	// +kubebuilder:validation:Enum=Sabnzbd;Nzbget;QBittorrent;Transmission
+	Implementation string `json:"implementation"`
+
+	// Host name of the download client, e.g. the name of its Service
+	Host string `json:"host"`
+
+	// Port of the download client web interface
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=65535
+	Port int32 `json:"port"`
+
+	// Connect to the download client with TLS
+	// +optional
+	UseSSL bool `json:"useSsl,omitempty"`
+
+	// URL base of the download client web interface
+	// +optional
+	URLBase string `json:"urlBase,omitempty"`
+
+	// Category of downloads added by Sonarr
+	// +optional
+	Category string `json:"category,omitempty"`
+
+	// Secret holding the credentials of the download client: apiKey for SABnzbd, username and password for the others
+	// +optional
+	CredentialsSecret string `json:"credentialsSecret,omitempty"`
+
+	// Priority among the download clients of the same protocol, 1 being the highest (Default: 1)
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=50
+	// +optional
+	Priority int32 `json:"priority,omitempty"`
+
+	// Keep the download client in Sonarr without using it
+	// +optional
+	Disabled bool `json:"disabled,omitempty"`
+}
+
+type SonarrSpecIndexer struct {
+	// Name of the indexer in Sonarr
+	Name string `json:"name"`
+
+	// Newznab for usenet indexers, Torznab for torrent trackers (e.g. through Jackett)
+	// +kubebuilder:validation:Enum=Newznab;Torznab
+	Implementation string `json:"implementation"`
+
+	// URL of the indexer, e.g. https://api.nzbgeek.info
+	URL string `json:"url"`
+
+	// Path of the API below the URL (Default: /api)
+	// +optional
+	APIPath string `json:"apiPath,omitempty"`
+
+	// Categories searched for series (Default: 5030, 5040)
+	// +optional
+	Categories []int32 `json:"categories,omitempty"`
+
+	// Categories searched for anime series
+	// +optional
+	AnimeCategories []int32 `json:"animeCategories,omitempty"`
+
+	// Secret holding the API key of the indexer in its apiKey entry
+	// +optional
+	APIKeySecret string `json:"apiKeySecret,omitempty"`
+
+	// Priority among the indexers, 1 being the highest (Default: 25)
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=50
+	// +optional
+	Priority int32 `json:"priority,omitempty"`
+
+	// Do not fetch new releases from the RSS feed of the indexer
+	// +optional
+	DisableRss bool `json:"disableRss,omitempty"`
+
+	// Do not search the indexer for missing episodes
+	// +optional
+	DisableAutomaticSearch bool `json:"disableAutomaticSearch,omitempty"`
+
+	// Do not search the indexer from the Sonarr UI
+	// +optional
+	DisableInteractiveSearch bool `json:"disableInteractiveSearch,omitempty"`
+}
+
+type SonarrSpecCustomFormat struct {
+	// Name of the custom format in Sonarr
+	Name string `json:"name"`
+
+	// Add the custom format to file names using the {Custom Formats} token
+	// +optional
+	IncludeWhenRenaming bool `json:"includeWhenRenaming,omitempty"`
+
+	// Conditions of the custom format
+	// +optional
+	Specifications []SonarrSpecCustomFormatSpecification `json:"specifications,omitempty"`
+
+	// ConfigMap holding the custom format as JSON, as exported by Sonarr or published in the TRaSH guides, instead of
+	// specifications
+	// +optional
+	ConfigMap *SonarrSpecConfigMapKey `json:"configMap,omitempty"`
+}
+
+type SonarrSpecCustomFormatSpecification struct {
+	// Name of the condition
+	Name string `json:"name"`
+
+	// Type of the condition, e.g. ReleaseTitleSpecification, SourceSpecification or ResolutionSpecification
+	Implementation string `json:"implementation"`
+
+	// Match releases not meeting the condition
+	// +optional
+	Negate bool `json:"negate,omitempty"`
+
+	// Releases must meet the condition to match the custom format
+	// +optional
+	Required bool `json:"required,omitempty"`
+
+	// Value of the condition: a regular expression for release titles, a number for sources and resolutions
+	Value intstr.IntOrString `json:"value"`
+}
+
+type SonarrSpecConfigMapKey struct {
+	// Name of the ConfigMap
+	Name string `json:"name"`
+
+	// Key of the ConfigMap entry
+	Key string `json:"key"`
+}
+
+type SonarrSpecQualityProfile struct {
+	// Name of the quality profile in Sonarr
+	Name string `json:"name"`
+
+	// Qualities and quality groups downloaded, by name (e.g. HDTV-720p, WEB 1080p).  Others are not downloaded.
+	// +kubebuilder:validation:MinItems=1
+	Qualities []string `json:"qualities"`
+
+	// Upgrade episodes until the cutoff quality is downloaded
+	// +optional
+	UpgradeAllowed bool `json:"upgradeAllowed,omitempty"`
+
+	// Quality or quality group to stop upgrading at (Default: the first of qualities)
+	// +optional
+	Cutoff string `json:"cutoff,omitempty"`
+
+	// Scores of custom formats, by name.  Custom formats not listed score 0.  Requires Sonarr v4.
+	// +optional
+	FormatScores map[string]int32 `json:"formatScores,omitempty"`
+
+	// Minimum custom format score of releases downloaded
+	// +optional
+	MinFormatScore int32 `json:"minFormatScore,omitempty"`
+
+	// Custom format score to stop upgrading at
+	// +optional
+	CutoffFormatScore int32 `json:"cutoffFormatScore,omitempty"`
+}
+
+type SonarrSpecReleaseProfile struct {
+	// Name of the release profile in Sonarr
+	Name string `json:"name"`
+
+	// Terms or /regular expressions/ releases must contain one of
+	// +optional
+	Required []string `json:"required,omitempty"`
+
+	// Terms or /regular expressions/ releases must not contain
+	// +optional
+	Ignored []string `json:"ignored,omitempty"`
+
+	// Keep the release profile in Sonarr without using it
+	// +optional
+	Disabled bool `json:"disabled,omitempty"`
+}
+
+// SonarrNotificationEvent is an event Sonarr sends notifications for
+// +kubebuilder:validation:Enum=Grab;Download;Upgrade;Rename;HealthIssue
+type SonarrNotificationEvent string
+
+// Notification events
+const (
+	NotificationOnGrab        SonarrNotificationEvent = "Grab"
+	NotificationOnDownload    SonarrNotificationEvent = "Download"
+	NotificationOnUpgrade     SonarrNotificationEvent = "Upgrade"
+	NotificationOnRename      SonarrNotificationEvent = "Rename"
+	NotificationOnHealthIssue SonarrNotificationEvent = "HealthIssue"
+)
+
+// SonarrSpecNotification is a connection Sonarr reports events to.  Exactly one of webhook, discord, slack, email and
+// plex must be set.
+type SonarrSpecNotification struct {
+	// Name of the notification in Sonarr
+	Name string `json:"name"`
+
+	// Events reported: Grab, Download (imports), Upgrade, Rename and HealthIssue (Default: Grab, Download, Upgrade)
+	// +listType=set
+	// +optional
+	Events []SonarrNotificationEvent `json:"events,omitempty"`
+
+	// Report health checks with warnings besides errors, with the HealthIssue event
+	// +optional
+	IncludeHealthWarnings bool `json:"includeHealthWarnings,omitempty"`
+
+	// Secret holding the credentials of the notification: webhookUrl for Discord and Slack, authToken for Plex,
+	// username and password for webhooks and email
+	// +optional
+	Secret string `json:"secret,omitempty"`
+
+	// Send events as JSON to a URL
+	// +optional
+	Webhook *SonarrSpecNotificationWebhook `json:"webhook,omitempty"`
+
+	// Post events to a Discord channel through the webhookUrl of the secret
+	// +optional
+	Discord *SonarrSpecNotificationDiscord `json:"discord,omitempty"`
+
+	// Post events to a Slack channel through the webhookUrl of the secret
+	// +optional
+	Slack *SonarrSpecNotificationSlack `json:"slack,omitempty"`
+
+	// Mail events
+	// +optional
+	Email *SonarrSpecNotificationEmail `json:"email,omitempty"`
+
+	// Update the library of a Plex Media Server on imports
+	// +optional
+	Plex *SonarrSpecNotificationPlex `json:"plex,omitempty"`
+}
+
+type SonarrSpecNotificationWebhook struct {
+	// URL events are sent to
+	URL string `json:"url"`
+
+	// HTTP method of the requests (Default: POST)
+	// +kubebuilder:validation:Enum=POST;PUT
+	// +optional
+	Method string `json:"method,omitempty"`
+}
+
+type SonarrSpecNotificationDiscord struct {
+	// Name the messages are posted as (Default: the name of the webhook)
+	// +optional
+	Username string `json:"username,omitempty"`
+}
+
+type SonarrSpecNotificationSlack struct {
+	// Channel the messages are posted to (Default: the channel of the webhook)
+	// +optional
+	Channel string `json:"channel,omitempty"`
+
+	// Name the messages are posted as (Default: the name of the webhook)
+	// +optional
+	Username string `json:"username,omitempty"`
+}
+
+type SonarrSpecNotificationEmail struct {
+	// Host name of the SMTP server
+	Server string `json:"server"`
+
+	// Port of the SMTP server (Default: 587)
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=65535
+	// +optional
+	Port int32 `json:"port,omitempty"`
+
+	// Connect to the SMTP server with TLS
+	// +optional
+	UseSSL bool `json:"useSsl,omitempty"`
+
+	// Sender address
+	From string `json:"from"`
+
+	// Recipient addresses
+	// +kubebuilder:validation:MinItems=1
+	To []string `json:"to"`
+}
+
+type SonarrSpecNotificationPlex struct {
+	// Host name of the Plex Media Server, e.g. the name of its Service
+	Host string `json:"host"`
+
+	// Port of the Plex Media Server (Default: 32400)
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=65535
+	// +optional
+	Port int32 `json:"port,omitempty"`
+
+	// Connect to the Plex Media Server with TLS
+	// +optional
+	UseSSL bool `json:"useSsl,omitempty"`
+}
+
+// Restore phases
+const (
+	RestoreScalingDown = "ScalingDown"
+	RestoreRunning     = "Restoring"
+	RestoreSucceeded   = "Succeeded"
+	RestoreFailed      = "Failed"
+)
+
+type SonarrStatusRestore struct {
+	// Persistent Volume Claim of the restored archive
+	Claim string `json:"claim"`
+
+	// Path of the restored archive
+	Path string `json:"path"`
+
+	// Progress of the restore: ScalingDown, Restoring, Succeeded or Failed
+	Phase string `json:"phase"`
+
+	// Details on a failed restore
+	Message string `json:"message,omitempty"`
+
+	StartTime      *metav1.Time `json:"startTime,omitempty"`
+	CompletionTime *metav1.Time `json:"completionTime,omitempty"`
+}
+
+// SonarrStatusConnection is the result of the connection test Sonarr ran for a download client or notification
+type SonarrStatusConnection struct {
+	Name string `json:"name"`
+
+	// Whether the last connection test passed
+	Connected bool `json:"connected"`
+
+	// Why the last connection test failed
+	Message string `json:"message,omitempty"`
+}
+
+// SonarrStatusProvider records a download client, indexer or notification applied to Sonarr
+type SonarrStatusProvider struct {
+	// DownloadClient, Indexer or Notification
+	Kind string `json:"kind"`
+
+	Name string `json:"name"`
+
+	// Keyed hash of the secret data last sent to Sonarr.  Sonarr masks secrets it returns, so they are sent again
+	// when the hash changes.
+	SecretHash string `json:"secretHash,omitempty"`
+
+	// Hash of the spec and secret data last tested.  Sonarr tests the provider again when it changes.
+	TestedHash string `json:"testedHash,omitempty"`
+
+	// When Sonarr last tested the provider
+	TestTime *metav1.Time `json:"testTime,omitempty"`
+}
+
+// SonarrStatusApplication is reported by the running Sonarr
+type SonarrStatusApplication struct {
+	// Version of Sonarr
+	Version string `json:"version,omitempty"`
+
+	// Update branch of Sonarr
+	Branch string `json:"branch,omitempty"`
+
+	// Time Sonarr started
+	StartTime *metav1.Time `json:"startTime,omitempty"`
+}
+
+// Condition types set from the Sonarr API.  Health checks failing in Sonarr are added as conditions of their source,
+// e.g. IndexerRssCheck, with status False, the check type (Notice, Warning or Error) as reason and the check message.
+const (
+	// ConditionApplicationReachable is True when the Sonarr API answered the last poll
+	ConditionApplicationReachable = "ApplicationReachable"
+
+	// ConditionApplicationHealthy is True when Sonarr reports no failing health checks besides notices
+	ConditionApplicationHealthy = "ApplicationHealthy"
+
+	// ConditionDrifted is True when the settings in Sonarr differ from the spec and the drift mode is Detect
+	ConditionDrifted = "Drifted"
+
+	// ConditionIndexerPrefix starts the type of the conditions holding the indexer tests, followed by the indexer name
+	ConditionIndexerPrefix = "Indexer/"
+)
+
+// SonarrCondition describes an aspect of the state of Sonarr
+type SonarrCondition struct {
+	Type               string                 `json:"type"`
+	Status             corev1.ConditionStatus `json:"status"`
+	Reason             string                 `json:"reason,omitempty"`
+	Message            string                 `json:"message,omitempty"`
+	LastTransitionTime metav1.Time            `json:"lastTransitionTime,omitempty"`
+}
+
 // SonarrStatus defines the observed state of Sonarr
 type SonarrStatus struct {
 	// Desired Image hash for container
@@ -107,6 +949,105 @@ type SonarrStatus struct {
 	// +operator-sdk:gen-csv:customresourcedefinitions.statusDescriptors.x-descriptors="urn:alm:descriptor:com.tectonic.ui:text"
 	Image string `json:"image,omitempty"`
 
+	// Time the image registry was last checked for a new digest or tag
+	// +operator-sdk:gen-csv:customresourcedefinitions.statusDescriptors=true
+	// +operator-sdk:gen-csv:customresourcedefinitions.statusDescriptors.displayName="Image Check Time"
+	ImageCheckTime *metav1.Time `json:"imageCheckTime,omitempty"`
+
+	// Image waiting for the next update window
+	// +operator-sdk:gen-csv:customresourcedefinitions.statusDescriptors=true
+	// +operator-sdk:gen-csv:customresourcedefinitions.statusDescriptors.displayName="Pending Image"
+	// +operator-sdk:gen-csv:customresourcedefinitions.statusDescriptors.x-descriptors="urn:alm:descriptor:com.tectonic.ui:text"
+	PendingImage string `json:"pendingImage,omitempty"`
+
+	// Last image that became available, used to roll back failed updates
+	// +operator-sdk:gen-csv:customresourcedefinitions.statusDescriptors=true
+	// +operator-sdk:gen-csv:customresourcedefinitions.statusDescriptors.displayName="Last Good Image"
+	// +operator-sdk:gen-csv:customresourcedefinitions.statusDescriptors.x-descriptors="urn:alm:descriptor:com.tectonic.ui:text"
+	LastGoodImage string `json:"lastGoodImage,omitempty"`
+
+	// Images that failed to become available and are not rolled out again
+	// +operator-sdk:gen-csv:customresourcedefinitions.statusDescriptors=true
+	// +operator-sdk:gen-csv:customresourcedefinitions.statusDescriptors.displayName="Blocked Images"
+	BlockedImages []string `json:"blockedImages,omitempty"`
+
+	// Name of the backup taken before the last image update
+	// +operator-sdk:gen-csv:customresourcedefinitions.statusDescriptors=true
+	// +operator-sdk:gen-csv:customresourcedefinitions.statusDescriptors.displayName="Pre-Update Backup"
+	// +operator-sdk:gen-csv:customresourcedefinitions.statusDescriptors.x-descriptors="urn:alm:descriptor:com.tectonic.ui:text"
+	PreUpdateBackup string `json:"preUpdateBackup,omitempty"`
+
+	// Time of the backup taken before the last image update
+	// +operator-sdk:gen-csv:customresourcedefinitions.statusDescriptors=true
+	// +operator-sdk:gen-csv:customresourcedefinitions.statusDescriptors.displayName="Pre-Update Backup Time"
+	PreUpdateBackupTime *metav1.Time `json:"preUpdateBackupTime,omitempty"`
+
+	// Image the pre-update backup was taken or is running for
+	// +operator-sdk:gen-csv:customresourcedefinitions.statusDescriptors=true
+	// +operator-sdk:gen-csv:customresourcedefinitions.statusDescriptors.displayName="Pre-Update Backup Image"
+	// +operator-sdk:gen-csv:customresourcedefinitions.statusDescriptors.x-descriptors="urn:alm:descriptor:com.tectonic.ui:text"
+	PreUpdateBackupImage string `json:"preUpdateBackupImage,omitempty"`
+
+	// Id of the running Sonarr backup command taken before an image update
+	PreUpdateBackupCommand int `json:"preUpdateBackupCommand,omitempty"`
+
+	// Completion time of the last successful scheduled backup
+	// +operator-sdk:gen-csv:customresourcedefinitions.statusDescriptors=true
+	// +operator-sdk:gen-csv:customresourcedefinitions.statusDescriptors.displayName="Last Backup"
+	LastBackupTime *metav1.Time `json:"lastBackupTime,omitempty"`
+
+	// Secret holding the Sonarr API key
+	// +operator-sdk:gen-csv:customresourcedefinitions.statusDescriptors=true
+	// +operator-sdk:gen-csv:customresourcedefinitions.statusDescriptors.displayName="API Key Secret"
+	// +operator-sdk:gen-csv:customresourcedefinitions.statusDescriptors.x-descriptors="urn:alm:descriptor:io.kubernetes:Secret"
+	APIKeySecret string `json:"apiKeySecret,omitempty"`
+
+	// Last restore from a backup archive
+	// +operator-sdk:gen-csv:customresourcedefinitions.statusDescriptors=true
+	// +operator-sdk:gen-csv:customresourcedefinitions.statusDescriptors.displayName="Restore"
+	Restore *SonarrStatusRestore `json:"restore,omitempty"`
+
+	// Version of the running image tag
+	// +operator-sdk:gen-csv:customresourcedefinitions.statusDescriptors=true
+	// +operator-sdk:gen-csv:customresourcedefinitions.statusDescriptors.displayName="Current Version"
+	// +operator-sdk:gen-csv:customresourcedefinitions.statusDescriptors.x-descriptors="urn:alm:descriptor:com.tectonic.ui:text"
+	CurrentVersion string `json:"currentVersion,omitempty"`
+
+	// Newest version found in the image registry
+	// +operator-sdk:gen-csv:customresourcedefinitions.statusDescriptors=true
+	// +operator-sdk:gen-csv:customresourcedefinitions.statusDescriptors.displayName="Available Version"
+	// +operator-sdk:gen-csv:customresourcedefinitions.statusDescriptors.x-descriptors="urn:alm:descriptor:com.tectonic.ui:text"
+	AvailableVersion string `json:"availableVersion,omitempty"`
+
+	// Root folders in Sonarr that are not listed in rootFolders
+	// +operator-sdk:gen-csv:customresourcedefinitions.statusDescriptors=true
+	// +operator-sdk:gen-csv:customresourcedefinitions.statusDescriptors.displayName="Unmanaged Root Folders"
+	UnmanagedRootFolders []string `json:"unmanagedRootFolders,omitempty"`
+
+	// Connection tests of the download clients
+	// +operator-sdk:gen-csv:customresourcedefinitions.statusDescriptors=true
+	// +operator-sdk:gen-csv:customresourcedefinitions.statusDescriptors.displayName="Download Clients"
+	DownloadClients []SonarrStatusConnection `json:"downloadClients,omitempty"`
+
+	// Connection tests of the notifications
+	// +operator-sdk:gen-csv:customresourcedefinitions.statusDescriptors=true
+	// +operator-sdk:gen-csv:customresourcedefinitions.statusDescriptors.displayName="Notifications"
+	Notifications []SonarrStatusConnection `json:"notifications,omitempty"`
+
+	// Download clients, indexers and notifications applied to Sonarr with the secrets sent and the tests run for them
+	Providers []SonarrStatusProvider `json:"providers,omitempty"`
+
+	// Version, branch and start time reported by Sonarr
+	// +operator-sdk:gen-csv:customresourcedefinitions.statusDescriptors=true
+	// +operator-sdk:gen-csv:customresourcedefinitions.statusDescriptors.displayName="Application"
+	Application *SonarrStatusApplication `json:"application,omitempty"`
+
+	// Reachability and health checks of Sonarr, polled every WatchFrequency when the API key secret is set, and the
+	// indexer tests
+	// +operator-sdk:gen-csv:customresourcedefinitions.statusDescriptors=true
+	// +operator-sdk:gen-csv:customresourcedefinitions.statusDescriptors.x-descriptors="urn:alm:descriptor:io.kubernetes.conditions"
+	Conditions []SonarrCondition `json:"conditions,omitempty"`
+
 	// Phase
 	// +operator-sdk:gen-csv:customresourcedefinitions.statusDescriptors=true
 	Phase string `json:"phase,omitempty"`
@@ -128,6 +1069,12 @@ type SonarrStatus struct {
 // +operator-sdk:gen-csv:customresourcedefinitions.displayName="Sonarr"
 // +operator-sdk:gen-csv:customresourcedefinitions.resources=`Deployment,v1,"sonarr-operator"`
 // +operator-sdk:gen-csv:customresourcedefinitions.resources=`Service,v1,"sonarr-operator"`
+// +operator-sdk:gen-csv:customresourcedefinitions.resources=`ConfigMap,v1,"sonarr-operator"`
+// +operator-sdk:gen-csv:customresourcedefinitions.resources=`Secret,v1,"sonarr-operator"`
+// +operator-sdk:gen-csv:customresourcedefinitions.resources=`CronJob,v1beta1,"sonarr-operator"`
+// +operator-sdk:gen-csv:customresourcedefinitions.resources=`Job,v1,"sonarr-operator"`
+// +operator-sdk:gen-csv:customresourcedefinitions.resources=`Ingress,v1beta1,"sonarr-operator"`
+// +operator-sdk:gen-csv:customresourcedefinitions.resources=`Route,v1,"sonarr-operator"`
 type Sonarr struct {
 	metav1.TypeMeta   `json:",inline"`
 	metav1.ObjectMeta `json:"metadata,omitempty"`
@@ -0,0 +1,148 @@
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// SonarrSeriesSpec defines the desired state of SonarrSeries
+type SonarrSeriesSpec struct {
+	// Name of the Sonarr, in the same namespace, managing the series.  Its API key secret must be set.
+	// +operator-sdk:gen-csv:customresourcedefinitions.specDescriptors=true
+	// +operator-sdk:gen-csv:customresourcedefinitions.specDescriptors.displayName="Sonarr"
+	// +operator-sdk:gen-csv:customresourcedefinitions.specDescriptors.x-descriptors="urn:alm:descriptor:com.tectonic.ui:text"
+	Sonarr string `json:"sonarr"`
+
+	// TheTVDB id of the series
+	// +operator-sdk:gen-csv:customresourcedefinitions.specDescriptors=true
+	// +operator-sdk:gen-csv:customresourcedefinitions.specDescriptors.displayName="TVDB ID"
+	// +operator-sdk:gen-csv:customresourcedefinitions.specDescriptors.x-descriptors="urn:alm:descriptor:com.tectonic.ui:number"
+	// +kubebuilder:validation:Minimum=1
+	TVDBID int32 `json:"tvdbId"`
+
+	// Name of the quality profile of the series
+	// +operator-sdk:gen-csv:customresourcedefinitions.specDescriptors=true
+	// +operator-sdk:gen-csv:customresourcedefinitions.specDescriptors.displayName="Quality Profile"
+	// +operator-sdk:gen-csv:customresourcedefinitions.specDescriptors.x-descriptors="urn:alm:descriptor:com.tectonic.ui:text"
+	QualityProfile string `json:"qualityProfile"`
+
+	// Root folder the series is added to (Default: the first root folder of Sonarr).  Only used when adding the
+	// series.
+	// +operator-sdk:gen-csv:customresourcedefinitions.specDescriptors=true
+	// +operator-sdk:gen-csv:customresourcedefinitions.specDescriptors.displayName="Root Folder"
+	// +operator-sdk:gen-csv:customresourcedefinitions.specDescriptors.x-descriptors="urn:alm:descriptor:com.tectonic.ui:text"
+	// +optional
+	RootFolder string `json:"rootFolder,omitempty"`
+
+	// Download new episodes of the series (Default: true)
+	// +operator-sdk:gen-csv:customresourcedefinitions.specDescriptors=true
+	// +operator-sdk:gen-csv:customresourcedefinitions.specDescriptors.displayName="Monitored"
+	// +operator-sdk:gen-csv:customresourcedefinitions.specDescriptors.x-descriptors="urn:alm:descriptor:com.tectonic.ui:booleanSwitch"
+	// +optional
+	Monitored *bool `json:"monitored,omitempty"`
+
+	// Episodes monitored when adding the series (Default: all)
+	// +kubebuilder:validation:Enum=all;future;missing;existing;pilot;firstSeason;latestSeason;none
+	// +optional
+	Monitor string `json:"monitor,omitempty"`
+
+	// Search for the missing episodes after adding the series
+	// +optional
+	SearchOnAdd bool `json:"searchOnAdd,omitempty"`
+
+	// Seasons monitored or not, overriding monitor.  Seasons not listed are left alone.
+	// +listType=map
+	// +listMapKey=seasonNumber
+	// +optional
+	Seasons []SonarrSeriesSpecSeason `json:"seasons,omitempty"`
+
+	// Type of the series, which changes how episodes are numbered (Default: standard)
+	// +kubebuilder:validation:Enum=standard;daily;anime
+	// +optional
+	SeriesType string `json:"seriesType,omitempty"`
+
+	// Keep the episodes of each season in their own folder (Default: true)
+	// +optional
+	SeasonFolder *bool `json:"seasonFolder,omitempty"`
+}
+
+type SonarrSeriesSpecSeason struct {
+	// Number of the season, 0 for specials
+	// +kubebuilder:validation:Minimum=0
+	SeasonNumber int32 `json:"seasonNumber"`
+
+	// Download the episodes of the season
+	Monitored bool `json:"monitored"`
+}
+
+// SonarrSeriesStatus defines the observed state of SonarrSeries
+type SonarrSeriesStatus struct {
+	// Id of the series in Sonarr
+	// +operator-sdk:gen-csv:customresourcedefinitions.statusDescriptors=true
+	// +operator-sdk:gen-csv:customresourcedefinitions.statusDescriptors.displayName="Series ID"
+	SeriesID int32 `json:"seriesId,omitempty"`
+
+	// Title of the series
+	// +operator-sdk:gen-csv:customresourcedefinitions.statusDescriptors=true
+	// +operator-sdk:gen-csv:customresourcedefinitions.statusDescriptors.displayName="Title"
+	Title string `json:"title,omitempty"`
+
+	// Path of the series
+	Path string `json:"path,omitempty"`
+
+	// Monitored episodes aired so far
+	// +operator-sdk:gen-csv:customresourcedefinitions.statusDescriptors=true
+	// +operator-sdk:gen-csv:customresourcedefinitions.statusDescriptors.displayName="Episodes"
+	EpisodeCount int32 `json:"episodeCount,omitempty"`
+
+	// Episodes downloaded
+	// +operator-sdk:gen-csv:customresourcedefinitions.statusDescriptors=true
+	// +operator-sdk:gen-csv:customresourcedefinitions.statusDescriptors.displayName="Downloaded Episodes"
+	EpisodeFileCount int32 `json:"episodeFileCount,omitempty"`
+
+	// Episodes of the series, including unaired and unmonitored ones
+	TotalEpisodeCount int32 `json:"totalEpisodeCount,omitempty"`
+
+	// Percentage of the monitored episodes downloaded
+	// +operator-sdk:gen-csv:customresourcedefinitions.statusDescriptors=true
+	// +operator-sdk:gen-csv:customresourcedefinitions.statusDescriptors.displayName="Download Progress"
+	// +operator-sdk:gen-csv:customresourcedefinitions.statusDescriptors.x-descriptors="urn:alm:descriptor:com.tectonic.ui:text"
+	DownloadProgress int32 `json:"downloadProgress,omitempty"`
+
+	// Bytes used by the downloaded episodes
+	SizeOnDisk int64 `json:"sizeOnDisk,omitempty"`
+
+	// Phase
+	// +operator-sdk:gen-csv:customresourcedefinitions.statusDescriptors=true
+	Phase string `json:"phase,omitempty"`
+
+	// Reason
+	// +operator-sdk:gen-csv:customresourcedefinitions.statusDescriptors=true
+	Reason string `json:"reason,omitempty"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// SonarrSeries is a series added to a Sonarr.  Deleting it leaves the series in Sonarr.
+// +kubebuilder:subresource:status
+// +kubebuilder:resource:path=sonarrseries,scope=Namespaced
+// +operator-sdk:gen-csv:customresourcedefinitions.displayName="Sonarr Series"
+type SonarrSeries struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   SonarrSeriesSpec   `json:"spec,omitempty"`
+	Status SonarrSeriesStatus `json:"status,omitempty"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// SonarrSeriesList contains a list of SonarrSeries
+type SonarrSeriesList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []SonarrSeries `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&SonarrSeries{}, &SonarrSeriesList{})
+}
@@ -5,7 +5,8 @@
 package v1alpha1
 
 import (
-	v1 "k8s.io/api/apps/v1"
+	appsv1 "k8s.io/api/apps/v1"
+	v1 "k8s.io/api/core/v1"
 	runtime "k8s.io/apimachinery/pkg/runtime"
 )
 
@@ -37,6 +38,23 @@ func (in *Sonarr) DeepCopyObject() runtime.Object {
 	return nil
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SonarrCondition) DeepCopyInto(out *SonarrCondition) {
+	*out = *in
+	in.LastTransitionTime.DeepCopyInto(&out.LastTransitionTime)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SonarrCondition.
+func (in *SonarrCondition) DeepCopy() *SonarrCondition {
+	if in == nil {
+		return nil
+	}
+	out := new(SonarrCondition)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *SonarrList) DeepCopyInto(out *SonarrList) {
 	*out = *in
@@ -70,19 +88,259 @@ func (in *SonarrList) DeepCopyObject() runtime.Object {
 	return nil
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SonarrSeries) DeepCopyInto(out *SonarrSeries) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	out.Status = in.Status
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SonarrSeries.
+func (in *SonarrSeries) DeepCopy() *SonarrSeries {
+	if in == nil {
+		return nil
+	}
+	out := new(SonarrSeries)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *SonarrSeries) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SonarrSeriesList) DeepCopyInto(out *SonarrSeriesList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]SonarrSeries, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SonarrSeriesList.
+func (in *SonarrSeriesList) DeepCopy() *SonarrSeriesList {
+	if in == nil {
+		return nil
+	}
+	out := new(SonarrSeriesList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *SonarrSeriesList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SonarrSeriesSpec) DeepCopyInto(out *SonarrSeriesSpec) {
+	*out = *in
+	if in.Monitored != nil {
+		in, out := &in.Monitored, &out.Monitored
+		*out = new(bool)
+		**out = **in
+	}
+	if in.Seasons != nil {
+		in, out := &in.Seasons, &out.Seasons
+		*out = make([]SonarrSeriesSpecSeason, len(*in))
+		copy(*out, *in)
+	}
+	if in.SeasonFolder != nil {
+		in, out := &in.SeasonFolder, &out.SeasonFolder
+		*out = new(bool)
+		**out = **in
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SonarrSeriesSpec.
+func (in *SonarrSeriesSpec) DeepCopy() *SonarrSeriesSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(SonarrSeriesSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SonarrSeriesSpecSeason) DeepCopyInto(out *SonarrSeriesSpecSeason) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SonarrSeriesSpecSeason.
+func (in *SonarrSeriesSpecSeason) DeepCopy() *SonarrSeriesSpecSeason {
+	if in == nil {
+		return nil
+	}
+	out := new(SonarrSeriesSpecSeason)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SonarrSeriesStatus) DeepCopyInto(out *SonarrSeriesStatus) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SonarrSeriesStatus.
+func (in *SonarrSeriesStatus) DeepCopy() *SonarrSeriesStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(SonarrSeriesStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *SonarrSpec) DeepCopyInto(out *SonarrSpec) {
 	*out = *in
+	if in.UpdateWindow != nil {
+		in, out := &in.UpdateWindow, &out.UpdateWindow
+		*out = new(SonarrSpecUpdateWindow)
+		**out = **in
+	}
+	if in.UpdatePolicy != nil {
+		in, out := &in.UpdatePolicy, &out.UpdatePolicy
+		*out = new(SonarrSpecUpdatePolicy)
+		**out = **in
+	}
 	if in.ImagePullSecrets != nil {
 		in, out := &in.ImagePullSecrets, &out.ImagePullSecrets
 		*out = make([]string, len(*in))
 		copy(*out, *in)
 	}
+	if in.NodeSelector != nil {
+		in, out := &in.NodeSelector, &out.NodeSelector
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.Affinity != nil {
+		in, out := &in.Affinity, &out.Affinity
+		*out = new(v1.Affinity)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Tolerations != nil {
+		in, out := &in.Tolerations, &out.Tolerations
+		*out = make([]v1.Toleration, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.TopologySpreadConstraints != nil {
+		in, out := &in.TopologySpreadConstraints, &out.TopologySpreadConstraints
+		*out = make([]v1.TopologySpreadConstraint, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Resources != nil {
+		in, out := &in.Resources, &out.Resources
+		*out = new(v1.ResourceRequirements)
+		(*in).DeepCopyInto(*out)
+	}
 	if in.Volumes != nil {
 		in, out := &in.Volumes, &out.Volumes
 		*out = make([]SonarrSpecVolume, len(*in))
 		copy(*out, *in)
 	}
+	if in.RootFolders != nil {
+		in, out := &in.RootFolders, &out.RootFolders
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.MediaManagement != nil {
+		in, out := &in.MediaManagement, &out.MediaManagement
+		*out = new(SonarrSpecMediaManagement)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.DownloadClients != nil {
+		in, out := &in.DownloadClients, &out.DownloadClients
+		*out = make([]SonarrSpecDownloadClient, len(*in))
+		copy(*out, *in)
+	}
+	if in.Indexers != nil {
+		in, out := &in.Indexers, &out.Indexers
+		*out = make([]SonarrSpecIndexer, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.CustomFormats != nil {
+		in, out := &in.CustomFormats, &out.CustomFormats
+		*out = make([]SonarrSpecCustomFormat, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.QualityProfiles != nil {
+		in, out := &in.QualityProfiles, &out.QualityProfiles
+		*out = make([]SonarrSpecQualityProfile, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.ReleaseProfiles != nil {
+		in, out := &in.ReleaseProfiles, &out.ReleaseProfiles
+		*out = make([]SonarrSpecReleaseProfile, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Notifications != nil {
+		in, out := &in.Notifications, &out.Notifications
+		*out = make([]SonarrSpecNotification, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Backup != nil {
+		in, out := &in.Backup, &out.Backup
+		*out = new(SonarrSpecBackup)
+		**out = **in
+	}
+	if in.Restore != nil {
+		in, out := &in.Restore, &out.Restore
+		*out = new(SonarrSpecRestore)
+		**out = **in
+	}
+	if in.Config != nil {
+		in, out := &in.Config, &out.Config
+		*out = new(SonarrSpecConfig)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Service != nil {
+		in, out := &in.Service, &out.Service
+		*out = new(SonarrSpecService)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Ingress != nil {
+		in, out := &in.Ingress, &out.Ingress
+		*out = new(SonarrSpecIngress)
+		(*in).DeepCopyInto(*out)
+	}
 	return
 }
 
@@ -97,48 +355,662 @@ func (in *SonarrSpec) DeepCopy() *SonarrSpec {
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *SonarrSpecVolume) DeepCopyInto(out *SonarrSpecVolume) {
+func (in *SonarrSpecBackup) DeepCopyInto(out *SonarrSpecBackup) {
 	*out = *in
 	return
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SonarrSpecVolume.
-func (in *SonarrSpecVolume) DeepCopy() *SonarrSpecVolume {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SonarrSpecBackup.
+func (in *SonarrSpecBackup) DeepCopy() *SonarrSpecBackup {
 	if in == nil {
 		return nil
 	}
-	out := new(SonarrSpecVolume)
+	out := new(SonarrSpecBackup)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *SonarrStatus) DeepCopyInto(out *SonarrStatus) {
+func (in *SonarrSpecConfig) DeepCopyInto(out *SonarrSpecConfig) {
 	*out = *in
-	if in.Deployments != nil {
-		in, out := &in.Deployments, &out.Deployments
-		*out = make(map[v1.DeploymentConditionType][]string, len(*in))
+	if in.AnalyticsEnabled != nil {
+		in, out := &in.AnalyticsEnabled, &out.AnalyticsEnabled
+		*out = new(bool)
+		**out = **in
+	}
+	if in.EnableSSL != nil {
+		in, out := &in.EnableSSL, &out.EnableSSL
+		*out = new(bool)
+		**out = **in
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SonarrSpecConfig.
+func (in *SonarrSpecConfig) DeepCopy() *SonarrSpecConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(SonarrSpecConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SonarrSpecConfigMapKey) DeepCopyInto(out *SonarrSpecConfigMapKey) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SonarrSpecConfigMapKey.
+func (in *SonarrSpecConfigMapKey) DeepCopy() *SonarrSpecConfigMapKey {
+	if in == nil {
+		return nil
+	}
+	out := new(SonarrSpecConfigMapKey)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SonarrSpecCustomFormat) DeepCopyInto(out *SonarrSpecCustomFormat) {
+	*out = *in
+	if in.Specifications != nil {
+		in, out := &in.Specifications, &out.Specifications
+		*out = make([]SonarrSpecCustomFormatSpecification, len(*in))
+		copy(*out, *in)
+	}
+	if in.ConfigMap != nil {
+		in, out := &in.ConfigMap, &out.ConfigMap
+		*out = new(SonarrSpecConfigMapKey)
+		**out = **in
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SonarrSpecCustomFormat.
+func (in *SonarrSpecCustomFormat) DeepCopy() *SonarrSpecCustomFormat {
+	if in == nil {
+		return nil
+	}
+	out := new(SonarrSpecCustomFormat)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SonarrSpecCustomFormatSpecification) DeepCopyInto(out *SonarrSpecCustomFormatSpecification) {
+	*out = *in
+	out.Value = in.Value
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SonarrSpecCustomFormatSpecification.
+func (in *SonarrSpecCustomFormatSpecification) DeepCopy() *SonarrSpecCustomFormatSpecification {
+	if in == nil {
+		return nil
+	}
+	out := new(SonarrSpecCustomFormatSpecification)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SonarrSpecDownloadClient) DeepCopyInto(out *SonarrSpecDownloadClient) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SonarrSpecDownloadClient.
+func (in *SonarrSpecDownloadClient) DeepCopy() *SonarrSpecDownloadClient {
+	if in == nil {
+		return nil
+	}
+	out := new(SonarrSpecDownloadClient)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SonarrSpecIndexer) DeepCopyInto(out *SonarrSpecIndexer) {
+	*out = *in
+	if in.Categories != nil {
+		in, out := &in.Categories, &out.Categories
+		*out = make([]int32, len(*in))
+		copy(*out, *in)
+	}
+	if in.AnimeCategories != nil {
+		in, out := &in.AnimeCategories, &out.AnimeCategories
+		*out = make([]int32, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SonarrSpecIndexer.
+func (in *SonarrSpecIndexer) DeepCopy() *SonarrSpecIndexer {
+	if in == nil {
+		return nil
+	}
+	out := new(SonarrSpecIndexer)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SonarrSpecIngress) DeepCopyInto(out *SonarrSpecIngress) {
+	*out = *in
+	if in.Annotations != nil {
+		in, out := &in.Annotations, &out.Annotations
+		*out = make(map[string]string, len(*in))
 		for key, val := range *in {
-			var outVal []string
-			if val == nil {
-				(*out)[key] = nil
-			} else {
-				in, out := &val, &outVal
-				*out = make([]string, len(*in))
-				copy(*out, *in)
-			}
-			(*out)[key] = outVal
+			(*out)[key] = val
 		}
 	}
 	return
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SonarrStatus.
-func (in *SonarrStatus) DeepCopy() *SonarrStatus {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SonarrSpecIngress.
+func (in *SonarrSpecIngress) DeepCopy() *SonarrSpecIngress {
 	if in == nil {
 		return nil
 	}
-	out := new(SonarrStatus)
+	out := new(SonarrSpecIngress)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SonarrSpecMediaManagement) DeepCopyInto(out *SonarrSpecMediaManagement) {
+	*out = *in
+	if in.CreateEmptySeriesFolders != nil {
+		in, out := &in.CreateEmptySeriesFolders, &out.CreateEmptySeriesFolders
+		*out = new(bool)
+		**out = **in
+	}
+	if in.DeleteEmptyFolders != nil {
+		in, out := &in.DeleteEmptyFolders, &out.DeleteEmptyFolders
+		*out = new(bool)
+		**out = **in
+	}
+	if in.UseHardlinks != nil {
+		in, out := &in.UseHardlinks, &out.UseHardlinks
+		*out = new(bool)
+		**out = **in
+	}
+	if in.ImportExtraFiles != nil {
+		in, out := &in.ImportExtraFiles, &out.ImportExtraFiles
+		*out = new(bool)
+		**out = **in
+	}
+	if in.ExtraFileExtensions != nil {
+		in, out := &in.ExtraFileExtensions, &out.ExtraFileExtensions
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.RecycleBinCleanupDays != nil {
+		in, out := &in.RecycleBinCleanupDays, &out.RecycleBinCleanupDays
+		*out = new(int32)
+		**out = **in
+	}
+	if in.MinimumFreeSpaceMB != nil {
+		in, out := &in.MinimumFreeSpaceMB, &out.MinimumFreeSpaceMB
+		*out = new(int32)
+		**out = **in
+	}
+	if in.SetPermissions != nil {
+		in, out := &in.SetPermissions, &out.SetPermissions
+		*out = new(bool)
+		**out = **in
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SonarrSpecMediaManagement.
+func (in *SonarrSpecMediaManagement) DeepCopy() *SonarrSpecMediaManagement {
+	if in == nil {
+		return nil
+	}
+	out := new(SonarrSpecMediaManagement)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SonarrSpecNotification) DeepCopyInto(out *SonarrSpecNotification) {
+	*out = *in
+	if in.Events != nil {
+		in, out := &in.Events, &out.Events
+		*out = make([]SonarrNotificationEvent, len(*in))
+		copy(*out, *in)
+	}
+	if in.Webhook != nil {
+		in, out := &in.Webhook, &out.Webhook
+		*out = new(SonarrSpecNotificationWebhook)
+		**out = **in
+	}
+	if in.Discord != nil {
+		in, out := &in.Discord, &out.Discord
+		*out = new(SonarrSpecNotificationDiscord)
+		**out = **in
+	}
+	if in.Slack != nil {
+		in, out := &in.Slack, &out.Slack
+		*out = new(SonarrSpecNotificationSlack)
+		**out = **in
+	}
+	if in.Email != nil {
+		in, out := &in.Email, &out.Email
+		*out = new(SonarrSpecNotificationEmail)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Plex != nil {
+		in, out := &in.Plex, &out.Plex
+		*out = new(SonarrSpecNotificationPlex)
+		**out = **in
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SonarrSpecNotification.
+func (in *SonarrSpecNotification) DeepCopy() *SonarrSpecNotification {
+	if in == nil {
+		return nil
+	}
+	out := new(SonarrSpecNotification)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SonarrSpecNotificationDiscord) DeepCopyInto(out *SonarrSpecNotificationDiscord) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SonarrSpecNotificationDiscord.
+func (in *SonarrSpecNotificationDiscord) DeepCopy() *SonarrSpecNotificationDiscord {
+	if in == nil {
+		return nil
+	}
+	out := new(SonarrSpecNotificationDiscord)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SonarrSpecNotificationEmail) DeepCopyInto(out *SonarrSpecNotificationEmail) {
+	*out = *in
+	if in.To != nil {
+		in, out := &in.To, &out.To
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SonarrSpecNotificationEmail.
+func (in *SonarrSpecNotificationEmail) DeepCopy() *SonarrSpecNotificationEmail {
+	if in == nil {
+		return nil
+	}
+	out := new(SonarrSpecNotificationEmail)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SonarrSpecNotificationPlex) DeepCopyInto(out *SonarrSpecNotificationPlex) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SonarrSpecNotificationPlex.
+func (in *SonarrSpecNotificationPlex) DeepCopy() *SonarrSpecNotificationPlex {
+	if in == nil {
+		return nil
+	}
+	out := new(SonarrSpecNotificationPlex)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SonarrSpecNotificationSlack) DeepCopyInto(out *SonarrSpecNotificationSlack) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SonarrSpecNotificationSlack.
+func (in *SonarrSpecNotificationSlack) DeepCopy() *SonarrSpecNotificationSlack {
+	if in == nil {
+		return nil
+	}
+	out := new(SonarrSpecNotificationSlack)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SonarrSpecNotificationWebhook) DeepCopyInto(out *SonarrSpecNotificationWebhook) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SonarrSpecNotificationWebhook.
+func (in *SonarrSpecNotificationWebhook) DeepCopy() *SonarrSpecNotificationWebhook {
+	if in == nil {
+		return nil
+	}
+	out := new(SonarrSpecNotificationWebhook)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SonarrSpecQualityProfile) DeepCopyInto(out *SonarrSpecQualityProfile) {
+	*out = *in
+	if in.Qualities != nil {
+		in, out := &in.Qualities, &out.Qualities
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.FormatScores != nil {
+		in, out := &in.FormatScores, &out.FormatScores
+		*out = make(map[string]int32, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SonarrSpecQualityProfile.
+func (in *SonarrSpecQualityProfile) DeepCopy() *SonarrSpecQualityProfile {
+	if in == nil {
+		return nil
+	}
+	out := new(SonarrSpecQualityProfile)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SonarrSpecReleaseProfile) DeepCopyInto(out *SonarrSpecReleaseProfile) {
+	*out = *in
+	if in.Required != nil {
+		in, out := &in.Required, &out.Required
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Ignored != nil {
+		in, out := &in.Ignored, &out.Ignored
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SonarrSpecReleaseProfile.
+func (in *SonarrSpecReleaseProfile) DeepCopy() *SonarrSpecReleaseProfile {
+	if in == nil {
+		return nil
+	}
+	out := new(SonarrSpecReleaseProfile)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SonarrSpecRestore) DeepCopyInto(out *SonarrSpecRestore) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SonarrSpecRestore.
+func (in *SonarrSpecRestore) DeepCopy() *SonarrSpecRestore {
+	if in == nil {
+		return nil
+	}
+	out := new(SonarrSpecRestore)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SonarrSpecService) DeepCopyInto(out *SonarrSpecService) {
+	*out = *in
+	if in.Annotations != nil {
+		in, out := &in.Annotations, &out.Annotations
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SonarrSpecService.
+func (in *SonarrSpecService) DeepCopy() *SonarrSpecService {
+	if in == nil {
+		return nil
+	}
+	out := new(SonarrSpecService)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SonarrSpecUpdatePolicy) DeepCopyInto(out *SonarrSpecUpdatePolicy) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SonarrSpecUpdatePolicy.
+func (in *SonarrSpecUpdatePolicy) DeepCopy() *SonarrSpecUpdatePolicy {
+	if in == nil {
+		return nil
+	}
+	out := new(SonarrSpecUpdatePolicy)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SonarrSpecUpdateWindow) DeepCopyInto(out *SonarrSpecUpdateWindow) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SonarrSpecUpdateWindow.
+func (in *SonarrSpecUpdateWindow) DeepCopy() *SonarrSpecUpdateWindow {
+	if in == nil {
+		return nil
+	}
+	out := new(SonarrSpecUpdateWindow)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SonarrSpecVolume) DeepCopyInto(out *SonarrSpecVolume) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SonarrSpecVolume.
+func (in *SonarrSpecVolume) DeepCopy() *SonarrSpecVolume {
+	if in == nil {
+		return nil
+	}
+	out := new(SonarrSpecVolume)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SonarrStatus) DeepCopyInto(out *SonarrStatus) {
+	*out = *in
+	if in.ImageCheckTime != nil {
+		in, out := &in.ImageCheckTime, &out.ImageCheckTime
+		*out = (*in).DeepCopy()
+	}
+	if in.BlockedImages != nil {
+		in, out := &in.BlockedImages, &out.BlockedImages
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.PreUpdateBackupTime != nil {
+		in, out := &in.PreUpdateBackupTime, &out.PreUpdateBackupTime
+		*out = (*in).DeepCopy()
+	}
+	if in.LastBackupTime != nil {
+		in, out := &in.LastBackupTime, &out.LastBackupTime
+		*out = (*in).DeepCopy()
+	}
+	if in.Restore != nil {
+		in, out := &in.Restore, &out.Restore
+		*out = new(SonarrStatusRestore)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.UnmanagedRootFolders != nil {
+		in, out := &in.UnmanagedRootFolders, &out.UnmanagedRootFolders
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.DownloadClients != nil {
+		in, out := &in.DownloadClients, &out.DownloadClients
+		*out = make([]SonarrStatusConnection, len(*in))
+		copy(*out, *in)
+	}
+	if in.Notifications != nil {
+		in, out := &in.Notifications, &out.Notifications
+		*out = make([]SonarrStatusConnection, len(*in))
+		copy(*out, *in)
+	}
+	if in.Providers != nil {
+		in, out := &in.Providers, &out.Providers
+		*out = make([]SonarrStatusProvider, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Application != nil {
+		in, out := &in.Application, &out.Application
+		*out = new(SonarrStatusApplication)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]SonarrCondition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Deployments != nil {
+		in, out := &in.Deployments, &out.Deployments
+		*out = make(map[appsv1.DeploymentConditionType][]string, len(*in))
+		for key, val := range *in {
+			var outVal []string
+			if val == nil {
+				(*out)[key] = nil
+			} else {
+				in, out := &val, &outVal
+				*out = make([]string, len(*in))
+				copy(*out, *in)
+			}
+			(*out)[key] = outVal
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SonarrStatus.
+func (in *SonarrStatus) DeepCopy() *SonarrStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(SonarrStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SonarrStatusApplication) DeepCopyInto(out *SonarrStatusApplication) {
+	*out = *in
+	if in.StartTime != nil {
+		in, out := &in.StartTime, &out.StartTime
+		*out = (*in).DeepCopy()
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SonarrStatusApplication.
+func (in *SonarrStatusApplication) DeepCopy() *SonarrStatusApplication {
+	if in == nil {
+		return nil
+	}
+	out := new(SonarrStatusApplication)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SonarrStatusConnection) DeepCopyInto(out *SonarrStatusConnection) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SonarrStatusConnection.
+func (in *SonarrStatusConnection) DeepCopy() *SonarrStatusConnection {
+	if in == nil {
+		return nil
+	}
+	out := new(SonarrStatusConnection)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SonarrStatusProvider) DeepCopyInto(out *SonarrStatusProvider) {
+	*out = *in
+	if in.TestTime != nil {
+		in, out := &in.TestTime, &out.TestTime
+		*out = (*in).DeepCopy()
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SonarrStatusProvider.
+func (in *SonarrStatusProvider) DeepCopy() *SonarrStatusProvider {
+	if in == nil {
+		return nil
+	}
+	out := new(SonarrStatusProvider)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SonarrStatusRestore) DeepCopyInto(out *SonarrStatusRestore) {
+	*out = *in
+	if in.StartTime != nil {
+		in, out := &in.StartTime, &out.StartTime
+		*out = (*in).DeepCopy()
+	}
+	if in.CompletionTime != nil {
+		in, out := &in.CompletionTime, &out.CompletionTime
+		*out = (*in).DeepCopy()
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SonarrStatusRestore.
+func (in *SonarrStatusRestore) DeepCopy() *SonarrStatusRestore {
+	if in == nil {
+		return nil
+	}
+	out := new(SonarrStatusRestore)
 	in.DeepCopyInto(out)
 	return out
 }
@@ -0,0 +1,94 @@
+// Package configxml reads and updates config.xml, the flat settings file of Sonarr
+package configxml
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io"
+)
+
+// RootElement is the document element of config.xml
+const RootElement = "Config"
+
+// Setting is one element of config.xml
+type Setting struct {
+	Name  string
+	Value string
+}
+
+// Parse returns the settings of a config.xml document in document order.  Empty documents have no settings.
+func Parse(data []byte) ([]Setting, error) {
+	if len(bytes.TrimSpace(data)) == 0 {
+		return nil, nil
+	}
+
+	var settings []Setting
+	d := xml.NewDecoder(bytes.NewReader(data))
+	depth := 0
+	for {
+		token, err := d.Token()
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			return nil, err
+		}
+
+		switch t := token.(type) {
+		case xml.StartElement:
+			if depth == 0 {
+				depth++
+				continue
+			}
+			value := struct {
+				Value string `xml:",chardata"`
+			}{}
+			if err := d.DecodeElement(&value, &t); err != nil {
+				return nil, err
+			}
+			settings = append(settings, Setting{Name: t.Name.Local, Value: value.Value})
+		case xml.EndElement:
+			depth--
+		}
+	}
+	if depth != 0 {
+		return nil, fmt.Errorf("unterminated %s element", RootElement)
+	}
+	return settings, nil
+}
+
+// Render returns the config.xml document holding settings
+func Render(settings []Setting) []byte {
+	buf := &bytes.Buffer{}
+	buf.WriteString("<" + RootElement + ">\n")
+	for _, s := range settings {
+		buf.WriteString("  <" + s.Name + ">")
+		_ = xml.EscapeText(buf, []byte(s.Value))
+		buf.WriteString("</" + s.Name + ">\n")
+	}
+	buf.WriteString("</" + RootElement + ">\n")
+	return buf.Bytes()
+}
+
+// Merge returns the config.xml document config with the values of managed.  Settings not in managed keep their
+// value and position, new settings are appended.
+func Merge(config []byte, managed []Setting) ([]byte, error) {
+	settings, err := Parse(config)
+	if err != nil {
+		return nil, err
+	}
+
+	index := map[string]int{}
+	for i, s := range settings {
+		index[s.Name] = i
+	}
+	for _, s := range managed {
+		if i, ok := index[s.Name]; ok {
+			settings[i].Value = s.Value
+			continue
+		}
+		index[s.Name] = len(settings)
+		settings = append(settings, s)
+	}
+	return Render(settings), nil
+}
@@ -0,0 +1,75 @@
+package configxml
+
+import (
+	"reflect"
+	"testing"
+)
+
+const sonarrConfig = `<Config>
+  <LogLevel>info</LogLevel>
+  <Port>8989</Port>
+  <UrlBase></UrlBase>
+  <BindAddress>*</BindAddress>
+  <ApiKey>0123456789abcdef0123456789abcdef</ApiKey>
+  <AuthenticationMethod>None</AuthenticationMethod>
+  <Branch>main</Branch>
+</Config>
+`
+
+func TestParse(t *testing.T) {
+	settings, err := Parse([]byte(sonarrConfig))
+	if err != nil {
+		t.Fatalf("parse: (%v)", err)
+	}
+	if len(settings) != 7 || settings[0] != (Setting{Name: "LogLevel", Value: "info"}) || settings[2] != (Setting{Name: "UrlBase"}) {
+		t.Errorf("unexpected settings: %v", settings)
+	}
+
+	if settings, err := Parse([]byte("\n")); err != nil || settings != nil {
+		t.Errorf("empty document: %v (%v)", settings, err)
+	}
+	if _, err := Parse([]byte("<Config><Port>8989</Port>")); err == nil {
+		t.Error("expected error for truncated document")
+	}
+}
+
+func TestMerge(t *testing.T) {
+	merged, err := Merge([]byte(sonarrConfig), []Setting{
+		{Name: "Port", Value: "9090"},
+		{Name: "UrlBase", Value: "/sonarr"},
+		{Name: "AnalyticsEnabled", Value: "False"},
+	})
+	if err != nil {
+		t.Fatalf("merge: (%v)", err)
+	}
+
+	expected := `<Config>
+  <LogLevel>info</LogLevel>
+  <Port>9090</Port>
+  <UrlBase>/sonarr</UrlBase>
+  <BindAddress>*</BindAddress>
+  <ApiKey>0123456789abcdef0123456789abcdef</ApiKey>
+  <AuthenticationMethod>None</AuthenticationMethod>
+  <Branch>main</Branch>
+  <AnalyticsEnabled>False</AnalyticsEnabled>
+</Config>
+`
+	if string(merged) != expected {
+		t.Errorf("unexpected config:\n%s", merged)
+	}
+}
+
+func TestMergeNewConfig(t *testing.T) {
+	managed := []Setting{{Name: "SslCertPassword", Value: "p<ss&word"}}
+	merged, err := Merge(nil, managed)
+	if err != nil {
+		t.Fatalf("merge: (%v)", err)
+	}
+	settings, err := Parse(merged)
+	if err != nil {
+		t.Fatalf("parse: (%v)", err)
+	}
+	if !reflect.DeepEqual(settings, managed) {
+		t.Errorf("values not escaped: %s", merged)
+	}
+}
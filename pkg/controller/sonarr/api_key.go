@@ -0,0 +1,108 @@
+package sonarr
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+
+	"github.com/parflesh/sonarr-operator/defaults"
+	sonarrv1alpha1 "github.com/parflesh/sonarr-operator/pkg/apis/sonarr/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+)
+
+// apiKeyEnv passes the API key to the merge-config init container, which writes it to the ApiKey setting
+const apiKeyEnv = "SONARR_CONFIG_ApiKey"
+
+// generateAPIKey returns a random key in the format Sonarr uses, 32 lowercase hex digits
+func generateAPIKey() (string, error) {
+	key := make([]byte, 16)
+	if _, err := rand.Read(key); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(key), nil
+}
+
+func (r *ReconcileSonarr) newAPIKeySecret(cr *sonarrv1alpha1.Sonarr) (*corev1.Secret, error) {
+	key, err := generateAPIKey()
+	if err != nil {
+		return &corev1.Secret{}, err
+	}
+
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      cr.Spec.APIKeySecret,
+			Namespace: cr.Namespace,
+			Labels:    r.labelsForCR(cr),
+		},
+		Type: corev1.SecretTypeOpaque,
+		Data: map[string][]byte{
+			defaults.APIKeySecretKey: []byte(key),
+		},
+	}
+
+	err = controllerutil.SetControllerReference(cr, secret, r.scheme)
+	if err != nil {
+		return secret, err
+	}
+	return secret, nil
+}
+
+// reconcileAPIKeySecret creates the API key secret of cr with a random key when it does not exist yet and publishes
+// its name in status.  It returns the phase and reason to report when it created the secret.
+func (r *ReconcileSonarr) reconcileAPIKeySecret(cr *sonarrv1alpha1.Sonarr, status *sonarrv1alpha1.SonarrStatus) (string, string, error) {
+	status.APIKeySecret = cr.Spec.APIKeySecret
+	if cr.Spec.APIKeySecret == "" {
+		return "", "", nil
+	}
+
+	found := &corev1.Secret{}
+	err := r.client.Get(context.TODO(), client.ObjectKey{Namespace: cr.Namespace, Name: cr.Spec.APIKeySecret}, found)
+	if err != nil && errors.IsNotFound(err) {
+		secret, err := r.newAPIKeySecret(cr)
+		if err != nil {
+			return "", "", err
+		}
+		if err := r.client.Create(context.TODO(), secret); err != nil {
+			return "", "", err
+		}
+		return "Initializing", "Created API key secret", nil
+	} else if err != nil {
+		return "", "", err
+	}
+
+	if len(found.Data[defaults.APIKeySecretKey]) == 0 {
+		return "", "", fmt.Errorf("secret %s has no %s", found.Name, defaults.APIKeySecretKey)
+	}
+	return "", "", nil
+}
+
+// apiKeyEnvVar returns the environment variable passing the API key of cr to the merge-config init container
+func (r *ReconcileSonarr) apiKeyEnvVar(cr *sonarrv1alpha1.Sonarr) corev1.EnvVar {
+	return corev1.EnvVar{
+		Name: apiKeyEnv,
+		ValueFrom: &corev1.EnvVarSource{
+			SecretKeyRef: &corev1.SecretKeySelector{
+				LocalObjectReference: corev1.LocalObjectReference{Name: cr.Spec.APIKeySecret},
+				Key:                  defaults.APIKeySecretKey,
+			},
+		},
+	}
+}
+
+// apiKey returns the API key of cr, or an empty string when it is not known yet
+func (r *ReconcileSonarr) apiKey(cr *sonarrv1alpha1.Sonarr) string {
+	if cr.Spec.APIKeySecret == "" {
+		return ""
+	}
+	secret := &corev1.Secret{}
+	err := r.client.Get(context.TODO(), client.ObjectKey{Namespace: cr.Namespace, Name: cr.Spec.APIKeySecret}, secret)
+	if err != nil {
+		return ""
+	}
+	return string(secret.Data[defaults.APIKeySecretKey])
+}
@@ -0,0 +1,83 @@
+package sonarr
+
+import (
+	"context"
+	"regexp"
+	"testing"
+
+	sonarrv1alpha1 "github.com/parflesh/sonarr-operator/pkg/apis/sonarr/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+func TestSonarrControllerAPIKey(t *testing.T) {
+	cr := newTestSonarr(sonarrv1alpha1.SonarrSpec{
+		APIKeySecret: "sonarr-api-key",
+		Volumes: []sonarrv1alpha1.SonarrSpecVolume{
+			{Name: "config", Claim: "sonarr-config", MountPath: "/config"},
+		},
+	})
+
+	r, req := newTestReconciler(cr)
+	secretKey := types.NamespacedName{Name: "sonarr-api-key", Namespace: testNamespace}
+
+	reconcileTimes(t, r, req, 4)
+	secret := &corev1.Secret{}
+	if err := r.client.Get(context.TODO(), secretKey, secret); err != nil {
+		t.Fatalf("api key secret not created: (%v)", err)
+	}
+	key := string(secret.Data["apiKey"])
+	if matched, _ := regexp.MatchString("^[0-9a-f]{32}$", key); !matched {
+		t.Errorf("unexpected api key: %q", key)
+	}
+	if !metav1.IsControlledBy(secret, cr) {
+		t.Error("api key secret not owned by sonarr")
+	}
+	cr = getSonarr(t, r, req)
+	if cr.Status.APIKeySecret != "sonarr-api-key" {
+		t.Errorf("api key secret not in status: %q", cr.Status.APIKeySecret)
+	}
+
+	dep := getDeployment(t, r, req)
+	pod := dep.Spec.Template.Spec
+	if len(pod.InitContainers) != 1 || len(pod.InitContainers[0].Env) != 1 {
+		t.Fatalf("api key not passed to init container: %v", pod.InitContainers)
+	}
+	env := pod.InitContainers[0].Env[0]
+	if env.Name != "SONARR_CONFIG_ApiKey" || env.ValueFrom.SecretKeyRef.Name != "sonarr-api-key" || env.ValueFrom.SecretKeyRef.Key != "apiKey" {
+		t.Errorf("unexpected api key env: %v", env)
+	}
+	hash := dep.Spec.Template.Annotations["sonarr.parflesh.github.io/config-hash"]
+
+	// A key set by the user is kept and restarts the pods
+	secret.Data["apiKey"] = []byte("0123456789abcdef0123456789abcdef")
+	if err := r.client.Update(context.TODO(), secret); err != nil {
+		t.Fatalf("update secret: (%v)", err)
+	}
+	reconcileTimes(t, r, req, 2)
+	secret = &corev1.Secret{}
+	if err := r.client.Get(context.TODO(), secretKey, secret); err != nil {
+		t.Fatalf("get secret: (%v)", err)
+	}
+	if string(secret.Data["apiKey"]) != "0123456789abcdef0123456789abcdef" {
+		t.Errorf("api key replaced: %q", secret.Data["apiKey"])
+	}
+	dep = getDeployment(t, r, req)
+	if dep.Spec.Template.Annotations["sonarr.parflesh.github.io/config-hash"] == hash {
+		t.Error("config hash not updated for new api key")
+	}
+}
+
+func TestSonarrControllerAPIKeyWithoutConfigVolume(t *testing.T) {
+	cr := newTestSonarr(sonarrv1alpha1.SonarrSpec{
+		APIKeySecret: "sonarr-api-key",
+	})
+
+	r, req := newTestReconciler(cr)
+	reconcileTimes(t, r, req, 5)
+	cr = getSonarr(t, r, req)
+	if cr.Status.Phase != "Degraded" || cr.Status.Reason != "API key secret sonarr-api-key not injected: no volume mounted at /config" {
+		t.Errorf("api key not injected without a config volume: %s %s", cr.Status.Phase, cr.Status.Reason)
+	}
+}
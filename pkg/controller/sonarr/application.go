@@ -0,0 +1,67 @@
+package sonarr
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	sonarrv1alpha1 "github.com/parflesh/sonarr-operator/pkg/apis/sonarr/v1alpha1"
+	"github.com/parflesh/sonarr-operator/pkg/sonarrapi"
+	corev1 "k8s.io/api/core/v1"
+)
+
+// applicationTimeout limits how long a reconcile spends changing the settings of Sonarr
+var applicationTimeout = time.Minute
+
+// applicationReachable reports whether the last poll of status reached the Sonarr API
+func applicationReachable(status *sonarrv1alpha1.SonarrStatus) bool {
+	for _, c := range status.Conditions {
+		if c.Type == sonarrv1alpha1.ConditionApplicationReachable {
+			return c.Status == corev1.ConditionTrue
+		}
+	}
+	return false
+}
+
+// reconcileApplication applies the settings of cr kept in the Sonarr database through the Sonarr API, or only
+// reports how they differ, depending on the drift mode of cr.  It returns the settings that could not be applied, or
+// an empty string when all were.
+func (r *ReconcileSonarr) reconcileApplication(cr *sonarrv1alpha1.Sonarr, status *sonarrv1alpha1.SonarrStatus) (string, error) {
+	if !applicationReachable(status) {
+		return "", nil
+	}
+	drift := &settingsDrift{enforce: r.driftMode(cr) == sonarrv1alpha1.DriftEnforce}
+	if r.driftMode(cr) == sonarrv1alpha1.DriftIgnore {
+		r.reportDrift(cr, status, drift)
+		return "", nil
+	}
+	c, err := r.sonarrClient(cr)
+	if err != nil {
+		return "", err
+	}
+	ctx, cancel := context.WithTimeout(context.TODO(), applicationTimeout)
+	defer cancel()
+
+	var problems []string
+	for _, reconcileFn := range []func(context.Context, *sonarrapi.Client, *sonarrv1alpha1.Sonarr, *sonarrv1alpha1.SonarrStatus, *settingsDrift) (string, error){
+		r.reconcileHostConfig,
+		r.reconcileMediaManagement,
+		r.reconcileRootFolders,
+		r.reconcileDownloadClients,
+		r.reconcileIndexers,
+		r.reconcileCustomFormats,
+		r.reconcileQualityProfiles,
+		r.reconcileReleaseProfiles,
+		r.reconcileNotifications,
+	} {
+		problem, err := reconcileFn(ctx, c, cr, status, drift)
+		if err != nil {
+			return "", err
+		}
+		if problem != "" {
+			problems = append(problems, problem)
+		}
+	}
+	r.reportDrift(cr, status, drift)
+	return strings.Join(problems, "; "), nil
+}
@@ -0,0 +1,60 @@
+package sonarr
+
+import (
+	"context"
+	"testing"
+
+	sonarrv1alpha1 "github.com/parflesh/sonarr-operator/pkg/apis/sonarr/v1alpha1"
+	"github.com/parflesh/sonarr-operator/pkg/sonarrapi/sonarrapitest"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+// newSonarrAPITest returns a reconciler for cr, using a fake Sonarr reachable with the API key secret of cr.  Sonarr
+// only keeps the API key of the secret with a config volume, which is added when cr has none.
+func newSonarrAPITest(cr *sonarrv1alpha1.Sonarr) (*ReconcileSonarr, *sonarrapitest.Server, reconcile.Request) {
+	if len(cr.Spec.Volumes) == 0 {
+		cr.Spec.Volumes = []sonarrv1alpha1.SonarrSpecVolume{{Name: "config", Claim: "sonarr-config", MountPath: "/config"}}
+	}
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      cr.Spec.APIKeySecret,
+			Namespace: cr.Namespace,
+		},
+		Data: map[string][]byte{"apiKey": []byte(sonarrapitest.APIKey)},
+	}
+	server := sonarrapitest.NewServer()
+
+	r, req := newTestReconciler(cr, secret)
+	r.sonarrBaseURL = func(cr *sonarrv1alpha1.Sonarr) string {
+		return server.URL
+	}
+	return r, server, req
+}
+
+// newApplicationTest returns a reconciler for cr, using a fake Sonarr reachable with the API key secret of cr, after
+// creating the Deployment and marking it available
+func newApplicationTest(t *testing.T, cr *sonarrv1alpha1.Sonarr) (*ReconcileSonarr, *sonarrapitest.Server, reconcile.Request) {
+	r, server, req := newSonarrAPITest(cr)
+	reconcileTimes(t, r, req, 4)
+	dep := getDeployment(t, r, req)
+	dep.Status.AvailableReplicas = 1
+	dep.Status.Conditions = []appsv1.DeploymentCondition{{Type: appsv1.DeploymentAvailable, Status: corev1.ConditionTrue}}
+	if err := r.client.Status().Update(context.TODO(), dep); err != nil {
+		t.Fatalf("update deployment status: (%v)", err)
+	}
+	return r, server, req
+}
+
+// countRequests returns how many of the requests served by server since the first n are request
+func countRequests(server *sonarrapitest.Server, n int, request string) int {
+	count := 0
+	for _, r := range server.Requests()[n:] {
+		if r == request {
+			count++
+		}
+	}
+	return count
+}
@@ -0,0 +1,159 @@
+package sonarr
+
+import (
+	"encoding/json"
+	"reflect"
+
+	jsonpatch "github.com/evanphx/json-patch"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/jsonmergepatch"
+	"k8s.io/apimachinery/pkg/util/strategicpatch"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// lastAppliedKey holds the configuration last applied by the operator.  Like kubectl apply, it is the base of the
+// three-way merge telling fields removed from the desired object apart from fields set by others.
+const lastAppliedKey = "sonarr.parflesh.github.io/last-applied-configuration"
+
+// appliedConfiguration returns desired as JSON without status and server set metadata
+func appliedConfiguration(desired runtime.Object) (map[string]interface{}, error) {
+	var obj map[string]interface{}
+	if u, ok := desired.(runtime.Unstructured); ok {
+		obj = runtime.DeepCopyJSON(u.UnstructuredContent())
+	} else {
+		var err error
+		obj, err = runtime.DefaultUnstructuredConverter.ToUnstructured(desired)
+		if err != nil {
+			return nil, err
+		}
+	}
+	unstructured.RemoveNestedField(obj, "status")
+	unstructured.RemoveNestedField(obj, "metadata", "creationTimestamp")
+	unstructured.RemoveNestedField(obj, "metadata", "resourceVersion")
+	unstructured.RemoveNestedField(obj, "metadata", "annotations", lastAppliedKey)
+	unstructured.RemoveNestedField(obj, "spec", "template", "metadata", "creationTimestamp")
+	return obj, nil
+}
+
+// routeSecretFields are the fields of a Route copied from its TLS secret.  They are left out of the last applied
+// configuration, which any reader of the metadata sees, and compared against the secret by the desired object.
+var routeSecretFields = [][]string{{"spec", "tls", "key"}, {"spec", "tls", "certificate"}}
+
+// lastApplied returns config, as returned by appliedConfiguration, as JSON to store in the lastAppliedKey
+// annotation
+func lastApplied(config map[string]interface{}) (string, error) {
+	if config["kind"] == "Route" {
+		config = runtime.DeepCopyJSON(config)
+		for _, field := range routeSecretFields {
+			unstructured.RemoveNestedField(config, field...)
+		}
+	}
+	data, err := json.Marshal(config)
+	return string(data), err
+}
+
+// setLastApplied records desired as the configuration last applied by the operator, for objects about to be
+// created
+func setLastApplied(desired runtime.Object) error {
+	config, err := appliedConfiguration(desired)
+	if err != nil {
+		return err
+	}
+	data, err := lastApplied(config)
+	if err != nil {
+		return err
+	}
+
+	accessor, err := meta.Accessor(desired)
+	if err != nil {
+		return err
+	}
+	annotations := accessor.GetAnnotations()
+	if annotations == nil {
+		annotations = map[string]string{}
+	}
+	annotations[lastAppliedKey] = data
+	accessor.SetAnnotations(annotations)
+	return nil
+}
+
+// mergePatch returns the three-way strategic merge patch taking found to desired, or nil when found is up to date.
+// Fields of desired are always enforced, fields dropped from desired since the last apply are removed and fields
+// the operator never set are left to their owners.
+func mergePatch(found runtime.Object, desired runtime.Object) (client.Patch, error) {
+	modified, err := appliedConfiguration(desired)
+	if err != nil {
+		return nil, err
+	}
+	config, err := lastApplied(modified)
+	if err != nil {
+		return nil, err
+	}
+	if err := unstructured.SetNestedField(modified, config, "metadata", "annotations", lastAppliedKey); err != nil {
+		return nil, err
+	}
+	modifiedJSON, err := json.Marshal(modified)
+	if err != nil {
+		return nil, err
+	}
+
+	accessor, err := meta.Accessor(found)
+	if err != nil {
+		return nil, err
+	}
+	original := []byte(accessor.GetAnnotations()[lastAppliedKey])
+	current, err := json.Marshal(found)
+	if err != nil {
+		return nil, err
+	}
+
+	// Objects without Go types, like Routes, have no patch strategies and get JSON merge patches
+	if _, ok := desired.(runtime.Unstructured); ok {
+		patch, err := jsonmergepatch.CreateThreeWayJSONMergePatch(original, modifiedJSON, current)
+		if err != nil {
+			return nil, err
+		}
+		patched, err := jsonpatch.MergePatch(current, patch)
+		if err != nil {
+			return nil, err
+		}
+		if equal, err := jsonEqual(current, patched); err != nil || equal {
+			return nil, err
+		}
+		return client.ConstantPatch(types.MergePatchType, patch), nil
+	}
+
+	patchMeta, err := strategicpatch.NewPatchMetaFromStruct(desired)
+	if err != nil {
+		return nil, err
+	}
+	patch, err := strategicpatch.CreateThreeWayMergePatch(original, modifiedJSON, current, patchMeta, true)
+	if err != nil {
+		return nil, err
+	}
+
+	// Lists holding entries added by others produce patches only restating the order of the list, which do not
+	// change anything
+	patched, err := strategicpatch.StrategicMergePatch(current, patch, desired)
+	if err != nil {
+		return nil, err
+	}
+	if equal, err := jsonEqual(current, patched); err != nil || equal {
+		return nil, err
+	}
+	return client.ConstantPatch(types.StrategicMergePatchType, patch), nil
+}
+
+func jsonEqual(a []byte, b []byte) (bool, error) {
+	var aValue, bValue interface{}
+	if err := json.Unmarshal(a, &aValue); err != nil {
+		return false, err
+	}
+	if err := json.Unmarshal(b, &bValue); err != nil {
+		return false, err
+	}
+	return reflect.DeepEqual(aValue, bValue), nil
+}
@@ -0,0 +1,208 @@
+package sonarr
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"github.com/parflesh/sonarr-operator/defaults"
+	sonarrv1alpha1 "github.com/parflesh/sonarr-operator/pkg/apis/sonarr/v1alpha1"
+	batchv1 "k8s.io/api/batch/v1"
+	batchv1beta1 "k8s.io/api/batch/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+)
+
+// backupScript archives the settings and the backups Sonarr takes of its own database, then prunes old archives.
+// The live database is not archived: Sonarr writes to it while the backup runs and a copy may be inconsistent.  Until
+// Sonarr takes its first backup there is nothing to archive, which is not a failure.
+const backupScript = `set -e
+archive="/backup/sonarr-$(date +%Y%m%d%H%M%S).tar.gz"
+cd /config
+if ! ls Backups/*/*.zip >/dev/null 2>&1; then
+  echo "no Sonarr backups in /config/Backups yet, nothing to archive"
+  exit 0
+fi
+tar -czf "$archive" $(ls -d config.xml 2>/dev/null) Backups
+ls -1t /backup/sonarr-*.tar.gz | tail -n +$((RETENTION + 1)) | xargs -r rm -f
+`
+
+func (r *ReconcileSonarr) backupName(cr *sonarrv1alpha1.Sonarr) string {
+	return cr.Name + "-backup"
+}
+
+// backupLabels are set on backup jobs and pods.  They must not match labelsForCR, which selects the Sonarr pods.
+func (r *ReconcileSonarr) backupLabels(cr *sonarrv1alpha1.Sonarr) map[string]string {
+	return map[string]string{
+		"sonarr-backup": cr.Name,
+	}
+}
+
+// configVolume returns the volume of cr mounted at the Sonarr configuration path
+func (r *ReconcileSonarr) configVolume(cr *sonarrv1alpha1.Sonarr) (sonarrv1alpha1.SonarrSpecVolume, error) {
+	for _, vol := range cr.Spec.Volumes {
+		if vol.MountPath == defaults.ConfigMountPath {
+			return vol, nil
+		}
+	}
+	return sonarrv1alpha1.SonarrSpecVolume{}, fmt.Errorf("no volume mounted at %s", defaults.ConfigMountPath)
+}
+
+func (r *ReconcileSonarr) newCronJob(cr *sonarrv1alpha1.Sonarr) (*batchv1beta1.CronJob, error) {
+	labels := r.backupLabels(cr)
+
+	config, err := r.configVolume(cr)
+	if err != nil {
+		return &batchv1beta1.CronJob{}, err
+	}
+
+	image := cr.Spec.Backup.Image
+	if image == "" {
+		image = defaults.BackupImage
+	}
+	retention := cr.Spec.Backup.Retention
+	if retention <= 0 {
+		retention = defaults.BackupRetention
+	}
+
+	var imagePullSecrets []corev1.LocalObjectReference
+	for _, s := range cr.Spec.ImagePullSecrets {
+		imagePullSecrets = append(imagePullSecrets, corev1.LocalObjectReference{Name: s})
+	}
+
+	cron := &batchv1beta1.CronJob{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      r.backupName(cr),
+			Namespace: cr.Namespace,
+			Labels:    labels,
+		},
+		Spec: batchv1beta1.CronJobSpec{
+			Schedule:                   cr.Spec.Backup.Schedule,
+			ConcurrencyPolicy:          batchv1beta1.ForbidConcurrent,
+			SuccessfulJobsHistoryLimit: &[]int32{3}[0],
+			FailedJobsHistoryLimit:     &[]int32{3}[0],
+			JobTemplate: batchv1beta1.JobTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{
+					Labels: labels,
+				},
+				Spec: batchv1.JobSpec{
+					BackoffLimit: &[]int32{2}[0],
+					Template: corev1.PodTemplateSpec{
+						ObjectMeta: metav1.ObjectMeta{
+							Labels: labels,
+						},
+						Spec: corev1.PodSpec{
+							Volumes: []corev1.Volume{
+								{
+									Name: "config",
+									VolumeSource: corev1.VolumeSource{
+										PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{
+											ClaimName: config.Claim,
+											ReadOnly:  true,
+										},
+									},
+								},
+								{
+									Name: "backup",
+									VolumeSource: corev1.VolumeSource{
+										PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{
+											ClaimName: cr.Spec.Backup.Claim,
+										},
+									},
+								},
+							},
+							Containers: []corev1.Container{
+								{
+									Name:    "backup",
+									Image:   image,
+									Command: []string{"/bin/sh", "-c", backupScript},
+									Env: []corev1.EnvVar{
+										{
+											Name:  "RETENTION",
+											Value: strconv.Itoa(int(retention)),
+										},
+									},
+									VolumeMounts: []corev1.VolumeMount{
+										{
+											Name:      "config",
+											MountPath: "/config",
+											SubPath:   config.SubPath,
+											ReadOnly:  true,
+										},
+										{
+											Name:      "backup",
+											MountPath: "/backup",
+										},
+									},
+									ImagePullPolicy: corev1.PullIfNotPresent,
+								},
+							},
+							NodeSelector:      cr.Spec.NodeSelector,
+							Affinity:          r.backupAffinity(cr),
+							Tolerations:       cr.Spec.Tolerations,
+							RestartPolicy:     corev1.RestartPolicyOnFailure,
+							SecurityContext:   r.podSecurityContext(cr),
+							ImagePullSecrets:  imagePullSecrets,
+							PriorityClassName: cr.Spec.PriorityClassName,
+						},
+					},
+				},
+			},
+		},
+	}
+
+	err = controllerutil.SetControllerReference(cr, cron, r.scheme)
+	if err != nil {
+		return cron, err
+	}
+	return cron, nil
+}
+
+// backupAffinity returns the affinity of backup pods: the affinity of cr, preferring the node running Sonarr so
+// ReadWriteOnce configuration volumes can be mounted
+func (r *ReconcileSonarr) backupAffinity(cr *sonarrv1alpha1.Sonarr) *corev1.Affinity {
+	affinity := cr.Spec.Affinity.DeepCopy()
+	if affinity == nil {
+		affinity = &corev1.Affinity{}
+	}
+	if affinity.PodAffinity == nil {
+		affinity.PodAffinity = &corev1.PodAffinity{}
+	}
+	affinity.PodAffinity.PreferredDuringSchedulingIgnoredDuringExecution = append(affinity.PodAffinity.PreferredDuringSchedulingIgnoredDuringExecution, corev1.WeightedPodAffinityTerm{
+		Weight: 100,
+		PodAffinityTerm: corev1.PodAffinityTerm{
+			LabelSelector: &metav1.LabelSelector{
+				MatchLabels: r.labelsForCR(cr),
+			},
+			TopologyKey: corev1.LabelHostname,
+		},
+	})
+	return affinity
+}
+
+// reconcileCronJob returns the patch taking the CronJob f to p, or nil when it is up to date
+func (r *ReconcileSonarr) reconcileCronJob(f *batchv1beta1.CronJob, p *batchv1beta1.CronJob) (client.Patch, error) {
+	return mergePatch(f, p)
+}
+
+// lastBackupTime returns the completion time of the newest successful backup job of cr
+func (r *ReconcileSonarr) lastBackupTime(cr *sonarrv1alpha1.Sonarr) (*metav1.Time, error) {
+	jobs := &batchv1.JobList{}
+	err := r.client.List(context.TODO(), jobs, client.InNamespace(cr.Namespace), client.MatchingLabels(r.backupLabels(cr)))
+	if err != nil {
+		return nil, err
+	}
+
+	var last *metav1.Time
+	for _, job := range jobs.Items {
+		if job.Status.Succeeded == 0 || job.Status.CompletionTime == nil {
+			continue
+		}
+		if last == nil || last.Before(job.Status.CompletionTime) {
+			last = job.Status.CompletionTime
+		}
+	}
+	return last, nil
+}
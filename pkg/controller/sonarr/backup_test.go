@@ -0,0 +1,274 @@
+package sonarr
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	sonarrv1alpha1 "github.com/parflesh/sonarr-operator/pkg/apis/sonarr/v1alpha1"
+	"github.com/parflesh/sonarr-operator/pkg/image_inspect"
+	batchv1 "k8s.io/api/batch/v1"
+	batchv1beta1 "k8s.io/api/batch/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+func TestSonarrControllerBackupBeforeUpdate(t *testing.T) {
+	apiKey := "0123456789abcdef0123456789abcdef"
+	cr := newTestSonarr(sonarrv1alpha1.SonarrSpec{
+		BackupBeforeUpdate: true,
+		APIKeySecret:       "sonarr-api-key",
+	})
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "sonarr-api-key",
+			Namespace: testNamespace,
+		},
+		Data: map[string][]byte{"apiKey": []byte(apiKey)},
+	}
+
+	backupStatus := "failed"
+	backups := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if req.Header.Get("X-Api-Key") != apiKey {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		switch req.URL.Path {
+		case "/api/v3/command":
+			backups++
+			_, _ = w.Write([]byte(`{"id":1,"name":"Backup","status":"queued"}`))
+		case "/api/v3/command/1":
+			_, _ = w.Write([]byte(`{"id":1,"name":"Backup","status":"` + backupStatus + `"}`))
+		case "/api/v3/system/backup":
+			_, _ = w.Write([]byte(`[{"id":1,"name":"sonarr_backup.zip","type":"manual","time":"2020-03-01T20:00:00Z"}]`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	r, req := newTestReconciler(cr, secret)
+	inspector := r.imageInspector.(*image_inspect.MockImageInspector)
+	r.sonarrBaseURL = func(cr *sonarrv1alpha1.Sonarr) string {
+		return server.URL
+	}
+
+	reconcileTimes(t, r, req, 2)
+	dep := getDeployment(t, r, req)
+	dep.Status.AvailableReplicas = 1
+	if err := r.client.Status().Update(context.TODO(), dep); err != nil {
+		t.Fatalf("update deployment status: (%v)", err)
+	}
+
+	// Failing backup blocks the update
+	inspector.GetDigestOutput = testNewDigest
+	expireImageCheck(t, r, req)
+	res, err := r.Reconcile(req)
+	if err != nil {
+		t.Fatalf("reconcile: (%v)", err)
+	}
+	if res.RequeueAfter == 0 {
+		t.Error("reconcile not requeued while backup is running")
+	}
+	cr = getSonarr(t, r, req)
+	if cr.Status.Phase != "BackingUp" || cr.Status.PreUpdateBackupCommand != 1 {
+		t.Errorf("backup not started: %s %d", cr.Status.Phase, cr.Status.PreUpdateBackupCommand)
+	}
+	if _, err := r.Reconcile(req); err == nil {
+		t.Error("reconcile succeeded with failing backup")
+	}
+	dep = getDeployment(t, r, req)
+	if dep.Spec.Template.Spec.Containers[0].Image != cr.Spec.Image+"@"+testDigest {
+		t.Errorf("deployment updated without backup: %s", dep.Spec.Template.Spec.Containers[0].Image)
+	}
+	cr = getSonarr(t, r, req)
+	if cr.Status.Phase != "UpdateFailed" {
+		t.Errorf("unexpected phase: %s", cr.Status.Phase)
+	}
+
+	backupStatus = "completed"
+	reconcileTimes(t, r, req, 2)
+	dep = getDeployment(t, r, req)
+	if dep.Spec.Template.Spec.Containers[0].Image != cr.Spec.Image+"@"+testNewDigest {
+		t.Errorf("deployment not updated after backup: %s", dep.Spec.Template.Spec.Containers[0].Image)
+	}
+	cr = getSonarr(t, r, req)
+	if cr.Status.PreUpdateBackup != "sonarr_backup.zip" || cr.Status.PreUpdateBackupTime == nil {
+		t.Errorf("backup not recorded: %s %v", cr.Status.PreUpdateBackup, cr.Status.PreUpdateBackupTime)
+	}
+	if backups != 2 {
+		t.Errorf("unexpected number of backups: %d", backups)
+	}
+}
+
+func TestSonarrControllerBackupCronJob(t *testing.T) {
+	cr := newTestSonarr(sonarrv1alpha1.SonarrSpec{
+		Volumes: []sonarrv1alpha1.SonarrSpecVolume{
+			{Name: "config", Claim: "sonarr-config", MountPath: "/config"},
+		},
+		Backup: &sonarrv1alpha1.SonarrSpecBackup{
+			Schedule: "0 4 * * *",
+			Claim:    "sonarr-backup",
+		},
+	})
+	completed := metav1.NewTime(time.Date(2020, 3, 1, 4, 0, 10, 0, time.UTC))
+	job := &batchv1.Job{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      testName + "-backup-1583035200",
+			Namespace: testNamespace,
+			Labels:    map[string]string{"sonarr-backup": testName},
+		},
+		Status: batchv1.JobStatus{
+			Succeeded:      1,
+			CompletionTime: &completed,
+		},
+	}
+
+	r, req := newTestReconciler(cr, job)
+	cronKey := types.NamespacedName{Name: testName + "-backup", Namespace: testNamespace}
+
+	reconcileTimes(t, r, req, 3)
+	cron := &batchv1beta1.CronJob{}
+	if err := r.client.Get(context.TODO(), cronKey, cron); err != nil {
+		t.Fatalf("backup cronjob not created: (%v)", err)
+	}
+	pod := cron.Spec.JobTemplate.Spec.Template.Spec
+	if cron.Spec.Schedule != "0 4 * * *" || pod.Volumes[0].PersistentVolumeClaim.ClaimName != "sonarr-config" || pod.Volumes[1].PersistentVolumeClaim.ClaimName != "sonarr-backup" {
+		t.Errorf("unexpected backup cronjob: %v", cron.Spec)
+	}
+	if pod.Containers[0].Env[0].Value != "7" {
+		t.Errorf("default retention not applied: %v", pod.Containers[0].Env)
+	}
+
+	res, err := r.Reconcile(req)
+	if err != nil {
+		t.Fatalf("reconcile: (%v)", err)
+	}
+	if res.Requeue {
+		t.Error("reconcile requeued even though all should be good")
+	}
+	cr = getSonarr(t, r, req)
+	if cr.Status.LastBackupTime == nil || !cr.Status.LastBackupTime.Equal(&completed) {
+		t.Errorf("last backup time not reported: %v", cr.Status.LastBackupTime)
+	}
+
+	// Schedule drift is corrected, suspending the cronjob by hand is kept
+	cron.Spec.Suspend = &[]bool{true}[0]
+	if err := r.client.Update(context.TODO(), cron); err != nil {
+		t.Fatalf("suspend cronjob: (%v)", err)
+	}
+	cr.Spec.Backup.Schedule = "0 5 * * *"
+	updateSonarr(t, r, cr)
+	reconcileTimes(t, r, req, 1)
+	cron = &batchv1beta1.CronJob{}
+	if err := r.client.Get(context.TODO(), cronKey, cron); err != nil {
+		t.Fatalf("get cronjob: (%v)", err)
+	}
+	if cron.Spec.Schedule != "0 5 * * *" {
+		t.Errorf("schedule not updated: %s", cron.Spec.Schedule)
+	}
+	if cron.Spec.Suspend == nil || !*cron.Spec.Suspend {
+		t.Error("suspended cronjob resumed")
+	}
+
+	// Disabling backups removes the cronjob
+	cr.Spec.Backup = nil
+	updateSonarr(t, r, cr)
+	reconcileTimes(t, r, req, 1)
+	if err := r.client.Get(context.TODO(), cronKey, cron); err == nil {
+		t.Error("backup cronjob not deleted")
+	}
+}
+
+func TestSonarrControllerBackupWithoutConfigVolume(t *testing.T) {
+	cr := newTestSonarr(sonarrv1alpha1.SonarrSpec{
+		Backup: &sonarrv1alpha1.SonarrSpecBackup{
+			Schedule: "0 4 * * *",
+			Claim:    "sonarr-backup",
+		},
+	})
+
+	r, req := newTestReconciler(cr)
+	reconcileTimes(t, r, req, 3)
+	cron := &batchv1beta1.CronJob{}
+	if err := r.client.Get(context.TODO(), types.NamespacedName{Name: testName + "-backup", Namespace: testNamespace}, cron); err == nil {
+		t.Error("backup cronjob created without config volume")
+	}
+	cr = getSonarr(t, r, req)
+	if cr.Status.Phase != "Degraded" || cr.Status.Reason != "Backups not scheduled: no volume mounted at /config" {
+		t.Errorf("missing config volume not reported: %s %s", cr.Status.Phase, cr.Status.Reason)
+	}
+}
+
+func TestBackupScript(t *testing.T) {
+	bin := commandsBin(t, []string{"sh", "date", "ls", "tar", "gzip", "tail", "xargs", "rm"})
+	defer os.RemoveAll(bin)
+	dir, err := ioutil.TempDir("", "backup")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	for _, d := range []string{"config", "backup"} {
+		if err := os.Mkdir(filepath.Join(dir, d), 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := ioutil.WriteFile(filepath.Join(dir, "config", "config.xml"), []byte("<Config></Config>"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	runScript := func() {
+		t.Helper()
+		script := strings.NewReplacer("/backup/", dir+"/backup/", "/config", dir+"/config").Replace(backupScript)
+		cmd := exec.Command(filepath.Join(bin, "sh"), "-c", script)
+		cmd.Env = []string{"PATH=" + bin, "RETENTION=1"}
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("backup script failed: (%v) %s", err, out)
+		}
+	}
+	archives := func() []string {
+		matches, err := filepath.Glob(filepath.Join(dir, "backup", "sonarr-*.tar.gz"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		return matches
+	}
+
+	// A new install has no Sonarr backups yet, which succeeds without an archive
+	runScript()
+	if found := archives(); len(found) != 0 {
+		t.Errorf("unexpected archives without Sonarr backups: %v", found)
+	}
+
+	// Sonarr backups are archived and archives beyond the retention are pruned
+	if err := os.MkdirAll(filepath.Join(dir, "config", "Backups", "scheduled"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := ioutil.WriteFile(filepath.Join(dir, "config", "Backups", "scheduled", "sonarr_backup.zip"), []byte("zip"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	old := filepath.Join(dir, "backup", "sonarr-20200101000000.tar.gz")
+	if err := ioutil.WriteFile(old, []byte("old"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chtimes(old, time.Now().Add(-time.Hour), time.Now().Add(-time.Hour)); err != nil {
+		t.Fatal(err)
+	}
+	runScript()
+	found := archives()
+	if len(found) != 1 || found[0] == old {
+		t.Fatalf("unexpected archives: %v", found)
+	}
+	out, err := exec.Command(filepath.Join(bin, "tar"), "-tzf", found[0]).Output()
+	if err != nil || !strings.Contains(string(out), "config.xml") || !strings.Contains(string(out), "Backups/scheduled/sonarr_backup.zip") {
+		t.Errorf("unexpected archive content: %s (%v)", out, err)
+	}
+}
@@ -0,0 +1,230 @@
+package sonarr
+
+import (
+	"context"
+	"crypto/sha256"
+	"fmt"
+	"os"
+	"reflect"
+	"strconv"
+
+	"github.com/parflesh/sonarr-operator/defaults"
+	sonarrv1alpha1 "github.com/parflesh/sonarr-operator/pkg/apis/sonarr/v1alpha1"
+	"github.com/parflesh/sonarr-operator/pkg/configxml"
+	"github.com/parflesh/sonarr-operator/version"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+)
+
+const (
+	// configFile is the key of the managed settings in the config ConfigMap
+	configFile = "config.xml"
+
+	// managedConfigPath is where the init container mounts the config ConfigMap
+	managedConfigPath = "/sonarr-operator"
+
+	// configHashKey is set on the pod template, so pods restart when the managed settings change
+	configHashKey = "sonarr.parflesh.github.io/config-hash"
+)
+
+func (r *ReconcileSonarr) configName(cr *sonarrv1alpha1.Sonarr) string {
+	return cr.Name + "-config"
+}
+
+// manageConfig reports whether the operator writes settings of cr to config.xml
+func (r *ReconcileSonarr) manageConfig(cr *sonarrv1alpha1.Sonarr) bool {
+	return cr.Spec.Config != nil || cr.Spec.APIKeySecret != ""
+}
+
+// containerPort returns the port Sonarr listens on
+func (r *ReconcileSonarr) containerPort(cr *sonarrv1alpha1.Sonarr) int32 {
+	if cr.Spec.Config != nil && cr.Spec.Config.Port != 0 {
+		return cr.Spec.Config.Port
+	}
+	return defaults.SonarrPort
+}
+
+// urlBase returns the URL base Sonarr is served on, or an empty string when served on /
+func (r *ReconcileSonarr) urlBase(cr *sonarrv1alpha1.Sonarr) string {
+	if cr.Spec.Config == nil {
+		return ""
+	}
+	return cr.Spec.Config.URLBase
+}
+
+// operatorImage returns the image of the operator, which holds the merge-config command of the config init container
+func operatorImage() string {
+	if image := os.Getenv("OPERATOR_IMAGE"); image != "" {
+		return image
+	}
+	return defaults.OperatorImage + ":" + version.Version
+}
+
+// managedSettings returns the config.xml settings set by the spec of cr
+func (r *ReconcileSonarr) managedSettings(cr *sonarrv1alpha1.Sonarr) []configxml.Setting {
+	config := cr.Spec.Config
+	if config == nil {
+		return nil
+	}
+
+	var settings []configxml.Setting
+	add := func(name string, value string) {
+		if value != "" {
+			settings = append(settings, configxml.Setting{Name: name, Value: value})
+		}
+	}
+	addInt := func(name string, value int32) {
+		if value != 0 {
+			add(name, strconv.Itoa(int(value)))
+		}
+	}
+	addBool := func(name string, value *bool) {
+		if value != nil {
+			// config.xml uses .NET boolean names
+			add(name, map[bool]string{true: "True", false: "False"}[*value])
+		}
+	}
+
+	addInt("Port", config.Port)
+	add("UrlBase", config.URLBase)
+	add("AuthenticationMethod", config.AuthenticationMethod)
+	add("LogLevel", config.LogLevel)
+	add("Branch", config.Branch)
+	addBool("AnalyticsEnabled", config.AnalyticsEnabled)
+	addBool("EnableSsl", config.EnableSSL)
+	addInt("SslPort", config.SSLPort)
+	add("SslCertPath", config.SSLCertPath)
+	return settings
+}
+
+func (r *ReconcileSonarr) newConfigMap(cr *sonarrv1alpha1.Sonarr) (*corev1.ConfigMap, error) {
+	cm := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      r.configName(cr),
+			Namespace: cr.Namespace,
+			Labels:    r.labelsForCR(cr),
+		},
+		Data: map[string]string{
+			configFile: string(configxml.Render(r.managedSettings(cr))),
+		},
+	}
+
+	err := controllerutil.SetControllerReference(cr, cm, r.scheme)
+	if err != nil {
+		return cm, err
+	}
+	return cm, nil
+}
+
+// apiKeyProblem returns why the API key secret of cr is not injected into Sonarr, or "" when it is
+func (r *ReconcileSonarr) apiKeyProblem(cr *sonarrv1alpha1.Sonarr) string {
+	if cr.Spec.APIKeySecret == "" {
+		return ""
+	}
+	if _, err := r.configVolume(cr); err != nil {
+		return fmt.Sprintf("API key secret %s not injected: %v", cr.Spec.APIKeySecret, err)
+	}
+	return ""
+}
+
+// configPodSpec adds the init container merging the managed settings and API key into config.xml to the Sonarr pod
+// of cr
+func (r *ReconcileSonarr) configPodSpec(cr *sonarrv1alpha1.Sonarr, template *corev1.PodTemplateSpec) error {
+	if !r.manageConfig(cr) {
+		return nil
+	}
+
+	config, err := r.configVolume(cr)
+	if err != nil && cr.Spec.Config == nil {
+		// Without a config volume the API key would not outlive the pod, so Sonarr keeps generating its own.  This
+		// is reported by apiKeyProblem.
+		return nil
+	} else if err != nil {
+		return err
+	}
+
+	template.Spec.Volumes = append(template.Spec.Volumes, corev1.Volume{
+		Name: "sonarr-operator-config",
+		VolumeSource: corev1.VolumeSource{
+			ConfigMap: &corev1.ConfigMapVolumeSource{
+				LocalObjectReference: corev1.LocalObjectReference{Name: r.configName(cr)},
+			},
+		},
+	})
+	var env []corev1.EnvVar
+	if cr.Spec.APIKeySecret != "" {
+		env = append(env, r.apiKeyEnvVar(cr))
+	}
+	template.Spec.InitContainers = append(template.Spec.InitContainers, corev1.Container{
+		Name:    "config",
+		Image:   operatorImage(),
+		Command: []string{"/usr/local/bin/sonarr-operator", "merge-config", managedConfigPath + "/" + configFile, defaults.ConfigMountPath + "/" + configFile},
+		Env:     env,
+		VolumeMounts: []corev1.VolumeMount{
+			{
+				Name:      config.Name,
+				MountPath: defaults.ConfigMountPath,
+				SubPath:   config.SubPath,
+			},
+			{
+				Name:      "sonarr-operator-config",
+				MountPath: managedConfigPath,
+				ReadOnly:  true,
+			},
+		},
+		ImagePullPolicy: corev1.PullIfNotPresent,
+	})
+
+	if template.Annotations == nil {
+		template.Annotations = map[string]string{}
+	}
+	hash := sha256.New()
+	hash.Write(configxml.Render(r.managedSettings(cr)))
+	hash.Write([]byte(r.apiKey(cr)))
+	template.Annotations[configHashKey] = fmt.Sprintf("%x", hash.Sum(nil))
+	return nil
+}
+
+// reconcileConfigMap creates, updates or removes the ConfigMap holding the managed settings of cr.  It returns the
+// phase and reason to report when it changed anything.
+func (r *ReconcileSonarr) reconcileConfigMap(cr *sonarrv1alpha1.Sonarr) (string, string, error) {
+	found := &corev1.ConfigMap{}
+	err := r.client.Get(context.TODO(), client.ObjectKey{Namespace: cr.Namespace, Name: r.configName(cr)}, found)
+	if err != nil && !errors.IsNotFound(err) {
+		return "", "", err
+	}
+	exists := err == nil
+
+	if !r.manageConfig(cr) {
+		if exists && metav1.IsControlledBy(found, cr) {
+			if err := r.client.Delete(context.TODO(), found); err != nil && !errors.IsNotFound(err) {
+				return "", "", err
+			}
+			return "Updating", "Deleted config map", nil
+		}
+		return "", "", nil
+	}
+
+	desired, err := r.newConfigMap(cr)
+	if err != nil {
+		return "", "", err
+	}
+	if !exists {
+		if err := r.client.Create(context.TODO(), desired); err != nil {
+			return "", "", err
+		}
+		return "Initializing", "Created config map", nil
+	}
+
+	if !reflect.DeepEqual(found.Data, desired.Data) {
+		found.Data = desired.Data
+		if err := r.client.Update(context.TODO(), found); err != nil {
+			return "", "", err
+		}
+		return "Updating", "Updating config map", nil
+	}
+	return "", "", nil
+}
@@ -0,0 +1,89 @@
+package sonarr
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	sonarrv1alpha1 "github.com/parflesh/sonarr-operator/pkg/apis/sonarr/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+func TestSonarrControllerConfig(t *testing.T) {
+	analytics := false
+	cr := newTestSonarr(sonarrv1alpha1.SonarrSpec{
+		Volumes: []sonarrv1alpha1.SonarrSpecVolume{
+			{Name: "config", Claim: "sonarr-config", MountPath: "/config"},
+		},
+		Config: &sonarrv1alpha1.SonarrSpecConfig{
+			Port:             9090,
+			URLBase:          "/sonarr",
+			LogLevel:         "debug",
+			AnalyticsEnabled: &analytics,
+		},
+	})
+
+	r, req := newTestReconciler(cr)
+	cmKey := types.NamespacedName{Name: testName + "-config", Namespace: testNamespace}
+
+	reconcileTimes(t, r, req, 4)
+	cm := &corev1.ConfigMap{}
+	if err := r.client.Get(context.TODO(), cmKey, cm); err != nil {
+		t.Fatalf("config map not created: (%v)", err)
+	}
+	expected := "<Config>\n  <Port>9090</Port>\n  <UrlBase>/sonarr</UrlBase>\n  <LogLevel>debug</LogLevel>\n  <AnalyticsEnabled>False</AnalyticsEnabled>\n</Config>\n"
+	if cm.Data["config.xml"] != expected {
+		t.Errorf("unexpected managed config:\n%s", cm.Data["config.xml"])
+	}
+
+	dep := getDeployment(t, r, req)
+	pod := dep.Spec.Template.Spec
+	if len(pod.InitContainers) != 1 || pod.InitContainers[0].Command[1] != "merge-config" || pod.InitContainers[0].VolumeMounts[0].Name != "config" {
+		t.Errorf("unexpected init containers: %v", pod.InitContainers)
+	}
+	if pod.Containers[0].Ports[0].ContainerPort != 9090 || pod.Containers[0].ReadinessProbe.HTTPGet.Path != "/sonarr" {
+		t.Errorf("container not using configured port and url base: %v", pod.Containers[0])
+	}
+	hash := dep.Spec.Template.Annotations["sonarr.parflesh.github.io/config-hash"]
+	if hash == "" {
+		t.Error("config hash not set on pod template")
+	}
+	svc := &corev1.Service{}
+	if err := r.client.Get(context.TODO(), req.NamespacedName, svc); err != nil {
+		t.Fatalf("get service: (%v)", err)
+	}
+	if svc.Spec.Ports[0].Port != 8989 || svc.Spec.Ports[0].TargetPort.IntVal != 9090 {
+		t.Errorf("service not targeting configured port: %v", svc.Spec.Ports)
+	}
+
+	// Changing a setting updates the config map and restarts the pods
+	cr = getSonarr(t, r, req)
+	cr.Spec.Config.LogLevel = "trace"
+	updateSonarr(t, r, cr)
+	reconcileTimes(t, r, req, 2)
+	cm = &corev1.ConfigMap{}
+	if err := r.client.Get(context.TODO(), cmKey, cm); err != nil {
+		t.Fatalf("get config map: (%v)", err)
+	}
+	if !strings.Contains(cm.Data["config.xml"], "<LogLevel>trace</LogLevel>") {
+		t.Errorf("config map not updated:\n%s", cm.Data["config.xml"])
+	}
+	dep = getDeployment(t, r, req)
+	if dep.Spec.Template.Annotations["sonarr.parflesh.github.io/config-hash"] == hash {
+		t.Error("config hash not updated")
+	}
+
+	// Removing the config section removes the init container and config map
+	cr = getSonarr(t, r, req)
+	cr.Spec.Config = nil
+	updateSonarr(t, r, cr)
+	reconcileTimes(t, r, req, 2)
+	if err := r.client.Get(context.TODO(), cmKey, &corev1.ConfigMap{}); err == nil {
+		t.Error("config map not deleted")
+	}
+	dep = getDeployment(t, r, req)
+	if len(dep.Spec.Template.Spec.InitContainers) != 0 || len(dep.Spec.Template.Spec.Volumes) != 1 {
+		t.Errorf("config init container not removed: %v", dep.Spec.Template.Spec)
+	}
+}
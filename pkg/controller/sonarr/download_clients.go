@@ -0,0 +1,167 @@
+package sonarr
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	sonarrv1alpha1 "github.com/parflesh/sonarr-operator/pkg/apis/sonarr/v1alpha1"
+	"github.com/parflesh/sonarr-operator/pkg/sonarrapi"
+)
+
+// downloadClientImplementations maps the download client implementations to their Sonarr settings contract and
+// protocol
+var downloadClientImplementations = map[string]struct {
+	contract string
+	protocol string
+}{
+	"Sabnzbd":      {"SabnzbdSettings", sonarrapi.ProtocolUsenet},
+	"Nzbget":       {"NzbgetSettings", sonarrapi.ProtocolUsenet},
+	"QBittorrent":  {"QBittorrentSettings", sonarrapi.ProtocolTorrent},
+	"Transmission": {"TransmissionSettings", sonarrapi.ProtocolTorrent},
+}
+
+// downloadClientFields returns the Sonarr fields set by spec, with the credentials from its secret, and the hash of
+// the secret data
+func (r *ReconcileSonarr) downloadClientFields(cr *sonarrv1alpha1.Sonarr, spec sonarrv1alpha1.SonarrSpecDownloadClient) (map[string]interface{}, string, error) {
+	fields := map[string]interface{}{
+		"host":       spec.Host,
+		"port":       spec.Port,
+		"useSsl":     spec.UseSSL,
+		"urlBase":    spec.URLBase,
+		"tvCategory": spec.Category,
+	}
+
+	credentials, secretHash, err := r.secretData(cr, spec.CredentialsSecret)
+	if err != nil {
+		return nil, "", err
+	}
+	for _, key := range []string{"apiKey", "username", "password"} {
+		if value, ok := credentials[key]; ok {
+			fields[key] = strings.TrimSpace(string(value))
+		}
+	}
+	return fields, secretHash, nil
+}
+
+// reconcileDownloadClients adds the download clients of cr missing in Sonarr, updates those that differ and has
+// Sonarr test the connection to each, when needsTest says so.  Download clients not in the spec are left alone.  It
+// returns the download clients with an unknown implementation or failing their test.
+func (r *ReconcileSonarr) reconcileDownloadClients(ctx context.Context, c *sonarrapi.Client, cr *sonarrv1alpha1.Sonarr, status *sonarrv1alpha1.SonarrStatus, drift *settingsDrift) (string, error) {
+	if len(cr.Spec.DownloadClients) == 0 {
+		status.DownloadClients = nil
+		setProviders(status, providerDownloadClient, nil)
+		return "", nil
+	}
+
+	clients, err := c.GetDownloadClients(ctx)
+	if err != nil {
+		return "", err
+	}
+	existing := map[string]sonarrapi.DownloadClient{}
+	for _, dc := range clients {
+		existing[dc.Name] = dc
+	}
+
+	var connections []sonarrv1alpha1.SonarrStatusConnection
+	var providers []sonarrv1alpha1.SonarrStatusProvider
+	var unknown, failing []string
+	for _, spec := range cr.Spec.DownloadClients {
+		implementation, ok := downloadClientImplementations[spec.Implementation]
+		if !ok {
+			unknown = append(unknown, spec.Name)
+			continue
+		}
+		fields, secretHash, err := r.downloadClientFields(cr, spec)
+		if err != nil {
+			return "", fmt.Errorf("download client %s: %v", spec.Name, err)
+		}
+		priority := int(spec.Priority)
+		if priority == 0 {
+			priority = 1
+		}
+
+		dc, found := existing[spec.Name]
+		changed := !found || dc.Implementation != spec.Implementation || dc.Enable == spec.Disabled || dc.Priority != priority
+		dc.Name = spec.Name
+		dc.Implementation = spec.Implementation
+		dc.ConfigContract = implementation.contract
+		dc.Protocol = implementation.protocol
+		dc.Priority = priority
+		dc.Enable = !spec.Disabled
+		if dc.Tags == nil {
+			dc.Tags = []int{}
+		}
+		record := recordedProvider(status, providerDownloadClient, spec.Name)
+		fieldsChanged, err := setFields(&dc.Provider, fields, record.SecretHash != secretHash)
+		if err != nil {
+			return "", err
+		}
+		hash, err := specHash(spec, secretHash)
+		if err != nil {
+			return "", err
+		}
+
+		written := false
+		if !found && drift.found("download client %s missing", spec.Name) {
+			log.Info("Adding download client", "Namespace", cr.Namespace, "Name", cr.Name, "DownloadClient", spec.Name)
+			created, err := c.CreateDownloadClient(ctx, &dc)
+			if err != nil {
+				return "", fmt.Errorf("add download client %s: %v", spec.Name, err)
+			}
+			dc = *created
+			record.SecretHash = secretHash
+			written = true
+		} else if found && (changed || fieldsChanged) && drift.found("download client %s differs", spec.Name) {
+			log.Info("Updating download client", "Namespace", cr.Namespace, "Name", cr.Name, "DownloadClient", spec.Name)
+			if _, err := c.UpdateDownloadClient(ctx, &dc); err != nil {
+				return "", fmt.Errorf("update download client %s: %v", spec.Name, err)
+			}
+			record.SecretHash = secretHash
+			written = true
+		} else if found && !changed && !fieldsChanged {
+			record.SecretHash = secretHash
+		}
+		if !found && !written {
+			// The spec is not enforced, so the missing download client is only reported as drift.  Testing it would send
+			// its credentials to Sonarr for a download client Sonarr does not have.
+			providers = append(providers, record)
+			continue
+		}
+
+		connection := sonarrv1alpha1.SonarrStatusConnection{Name: spec.Name, Connected: true}
+		var passed *bool
+		last := findConnection(status.DownloadClients, spec.Name)
+		if last != nil {
+			passed = &last.Connected
+		}
+		if !needsTest(record, hash, written, passed) {
+			connection = *last
+		} else {
+			if err := c.TestDownloadClient(ctx, &dc); err != nil {
+				if !sonarrapi.IsBadRequest(err) {
+					return "", err
+				}
+				connection.Connected = false
+				connection.Message = testMessage(err)
+			}
+			setTested(&record, hash)
+		}
+		if !connection.Connected {
+			failing = append(failing, spec.Name)
+		}
+		connections = append(connections, connection)
+		providers = append(providers, record)
+	}
+	status.DownloadClients = connections
+	setProviders(status, providerDownloadClient, providers)
+
+	var problems []string
+	if len(unknown) > 0 {
+		problems = append(problems, fmt.Sprintf("Download clients with unknown implementation: %s", strings.Join(unknown, ", ")))
+	}
+	if len(failing) > 0 {
+		problems = append(problems, fmt.Sprintf("Download clients failing connection test: %s", strings.Join(failing, ", ")))
+	}
+	return strings.Join(problems, "; "), nil
+}
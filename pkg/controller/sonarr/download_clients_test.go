@@ -0,0 +1,140 @@
+package sonarr
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	sonarrv1alpha1 "github.com/parflesh/sonarr-operator/pkg/apis/sonarr/v1alpha1"
+	"github.com/parflesh/sonarr-operator/pkg/sonarrapi"
+	"github.com/parflesh/sonarr-operator/pkg/sonarrapi/sonarrapitest"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+func TestSonarrControllerDownloadClients(t *testing.T) {
+	cr := newTestSonarr(sonarrv1alpha1.SonarrSpec{
+		APIKeySecret: "sonarr-api-key",
+		DownloadClients: []sonarrv1alpha1.SonarrSpecDownloadClient{
+			{Name: "sabnzbd", Implementation: "Sabnzbd", Host: "sabnzbd", Port: 8080, Category: "tv", CredentialsSecret: "sabnzbd"},
+			{Name: "qbittorrent", Implementation: "QBittorrent", Host: "qbittorrent", Port: 8080, CredentialsSecret: "qbittorrent", Priority: 2},
+		},
+	})
+	r, server, req := newApplicationTest(t, cr)
+	defer server.Close()
+	server.Add(sonarrapitest.DownloadClients, sonarrapi.DownloadClient{
+		Provider: sonarrapi.Provider{
+			Name:           "qbittorrent",
+			Implementation: "QBittorrent",
+			ConfigContract: "QBittorrentSettings",
+			Protocol:       sonarrapi.ProtocolTorrent,
+			Priority:       2,
+			Fields: []sonarrapi.Field{
+				{Name: "host", Value: "torrent.example.com"},
+				{Name: "port", Value: 8080},
+				{Name: "password", Value: "********"},
+				{Name: "recentTvPriority", Value: 1},
+			},
+		},
+		Enable: true,
+	})
+	server.TestFailures = map[string]string{"qbittorrent": "Unable to connect to qBittorrent"}
+	server.MaskedFields = []string{"apiKey", "password"}
+	for _, secret := range []*corev1.Secret{
+		{ObjectMeta: metav1.ObjectMeta{Name: "sabnzbd", Namespace: "sonarr"}, Data: map[string][]byte{"apiKey": []byte("sabnzbd-key\n")}},
+		{ObjectMeta: metav1.ObjectMeta{Name: "qbittorrent", Namespace: "sonarr"}, Data: map[string][]byte{"username": []byte("admin"), "password": []byte("secret")}},
+	} {
+		if err := r.client.Create(context.TODO(), secret); err != nil {
+			t.Fatalf("create secret: (%v)", err)
+		}
+	}
+
+	reconcileTimes(t, r, req, 1)
+	var clients []sonarrapi.DownloadClient
+	server.List(sonarrapitest.DownloadClients, &clients)
+	if len(clients) != 2 {
+		t.Fatalf("unexpected download clients: %v", clients)
+	}
+	qbit, sab := clients[0], clients[1]
+	if qbit.Field("host") != "qbittorrent" || qbit.Field("username") != "admin" || qbit.Field("password") != "secret" || qbit.Field("recentTvPriority") != float64(1) {
+		t.Errorf("download client not updated: %v", qbit.Fields)
+	}
+	if sab.Name != "sabnzbd" || sab.ConfigContract != "SabnzbdSettings" || sab.Protocol != sonarrapi.ProtocolUsenet || !sab.Enable || sab.Priority != 1 {
+		t.Errorf("unexpected download client: %v", sab)
+	}
+	if sab.Field("apiKey") != "sabnzbd-key" || sab.Field("tvCategory") != "tv" || sab.Field("port") != float64(8080) {
+		t.Errorf("unexpected download client fields: %v", sab.Fields)
+	}
+
+	cr = getSonarr(t, r, req)
+	expected := []sonarrv1alpha1.SonarrStatusConnection{
+		{Name: "sabnzbd", Connected: true},
+		{Name: "qbittorrent", Connected: false, Message: "Unable to connect to qBittorrent"},
+	}
+	if !reflect.DeepEqual(cr.Status.DownloadClients, expected) {
+		t.Errorf("unexpected download client status: %v", cr.Status.DownloadClients)
+	}
+	if cr.Status.Phase != "Degraded" || !strings.Contains(cr.Status.Reason, "qbittorrent") {
+		t.Errorf("failing download client not reported: %s %s", cr.Status.Phase, cr.Status.Reason)
+	}
+
+	// Download clients matching the spec are left alone, and not tested again
+	requests := len(server.Requests())
+	reconcileTimes(t, r, req, 1)
+	for _, request := range server.Requests()[requests:] {
+		if !strings.HasPrefix(request, "GET") {
+			t.Errorf("unexpected request for unchanged download clients: %s", request)
+		}
+	}
+
+	// Rotated secrets are sent again, although Sonarr masks them
+	secret := &corev1.Secret{}
+	if err := r.client.Get(context.TODO(), types.NamespacedName{Name: "qbittorrent", Namespace: "sonarr"}, secret); err != nil {
+		t.Fatalf("get secret: (%v)", err)
+	}
+	secret.Data["password"] = []byte("rotated")
+	if err := r.client.Update(context.TODO(), secret); err != nil {
+		t.Fatalf("update secret: (%v)", err)
+	}
+	reconcileTimes(t, r, req, 2)
+	clients = nil
+	server.List(sonarrapitest.DownloadClients, &clients)
+	if clients[0].Field("password") != "rotated" || clients[1].Field("apiKey") != "sabnzbd-key" {
+		t.Errorf("rotated secret not sent: %v", clients[0].Fields)
+	}
+	updates := 0
+	for _, request := range server.Requests()[requests:] {
+		if strings.HasPrefix(request, "PUT") {
+			updates++
+		}
+	}
+	if updates != 1 || countRequests(server, requests, "POST /downloadclient/test") != 1 {
+		t.Errorf("unexpected updates and tests for a rotated secret: %d %v", updates, server.Requests()[requests:])
+	}
+
+	// Download clients with an unknown implementation are reported and skipped
+	cr.Spec.DownloadClients = append(cr.Spec.DownloadClients, sonarrv1alpha1.SonarrSpecDownloadClient{Name: "deluge", Implementation: "Deluge"})
+	updateSonarr(t, r, cr)
+	reconcileTimes(t, r, req, 1)
+	cr = getSonarr(t, r, req)
+	if !strings.Contains(cr.Status.Reason, "Download clients with unknown implementation: deluge") || !reflect.DeepEqual(cr.Status.DownloadClients, expected) {
+		t.Errorf("unknown download client not reported: %s %v", cr.Status.Reason, cr.Status.DownloadClients)
+	}
+
+	// Failing download clients are tested again after providerRetestInterval
+	server.TestFailures = nil
+	reconcileTimes(t, r, req, 1)
+	if cr = getSonarr(t, r, req); cr.Status.DownloadClients[1].Connected {
+		t.Errorf("download client tested again too early: %v", cr.Status.DownloadClients)
+	}
+	defer func(interval time.Duration) { providerRetestInterval = interval }(providerRetestInterval)
+	providerRetestInterval = 0
+	requests = len(server.Requests())
+	reconcileTimes(t, r, req, 1)
+	if cr = getSonarr(t, r, req); !cr.Status.DownloadClients[1].Connected || countRequests(server, requests, "POST /downloadclient/test") != 1 {
+		t.Errorf("failing download client not tested again: %v %v", cr.Status.DownloadClients, server.Requests()[requests:])
+	}
+}
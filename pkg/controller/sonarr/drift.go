@@ -0,0 +1,182 @@
+package sonarr
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	sonarrv1alpha1 "github.com/parflesh/sonarr-operator/pkg/apis/sonarr/v1alpha1"
+	"github.com/parflesh/sonarr-operator/pkg/sonarrapi"
+	corev1 "k8s.io/api/core/v1"
+)
+
+// settingsDrift collects the differences between the settings in Sonarr and the spec found by the application
+// reconcilers, which only change Sonarr when enforcing the spec
+type settingsDrift struct {
+	enforce     bool
+	differences []string
+}
+
+// found records the difference described by format and args, and reports whether to change Sonarr to remove it
+func (d *settingsDrift) found(format string, args ...interface{}) bool {
+	if !d.enforce {
+		d.differences = append(d.differences, fmt.Sprintf(format, args...))
+	}
+	return d.enforce
+}
+
+// driftMode returns the drift mode of cr
+func (r *ReconcileSonarr) driftMode(cr *sonarrv1alpha1.Sonarr) string {
+	if cr.Spec.DriftMode == "" {
+		return sonarrv1alpha1.DriftEnforce
+	}
+	return cr.Spec.DriftMode
+}
+
+// reportDrift sets the Drifted condition of status from drift when detecting drift, and emits an Event for each
+// difference not reported by the previous condition
+func (r *ReconcileSonarr) reportDrift(cr *sonarrv1alpha1.Sonarr, status *sonarrv1alpha1.SonarrStatus, drift *settingsDrift) {
+	var conditions []sonarrv1alpha1.SonarrCondition
+	reported := map[string]bool{}
+	for _, c := range status.Conditions {
+		if c.Type != sonarrv1alpha1.ConditionDrifted {
+			conditions = append(conditions, c)
+		} else if c.Status == corev1.ConditionTrue {
+			for _, d := range strings.Split(c.Message, "; ") {
+				reported[d] = true
+			}
+		}
+	}
+	old := status.Conditions
+	status.Conditions = conditions
+	if r.driftMode(cr) != sonarrv1alpha1.DriftDetect {
+		return
+	}
+
+	drifted := sonarrv1alpha1.SonarrCondition{
+		Type:   sonarrv1alpha1.ConditionDrifted,
+		Status: corev1.ConditionFalse,
+		Reason: "InSync",
+	}
+	if len(drift.differences) > 0 {
+		drifted.Status = corev1.ConditionTrue
+		drifted.Reason = "SettingsDiffer"
+		drifted.Message = strings.Join(drift.differences, "; ")
+		for _, d := range drift.differences {
+			if !reported[d] {
+				r.recorder.Event(cr, corev1.EventTypeWarning, "Drifted", d)
+			}
+		}
+	}
+	status.Conditions = append(status.Conditions, condition(old, drifted))
+}
+
+// reconcileHostConfig compares the host settings in Sonarr with the config of cr and updates those that differ.
+// Sonarr applies changes to the port, URL base and SSL settings when it restarts.
+func (r *ReconcileSonarr) reconcileHostConfig(ctx context.Context, c *sonarrapi.Client, cr *sonarrv1alpha1.Sonarr, status *sonarrv1alpha1.SonarrStatus, drift *settingsDrift) (string, error) {
+	config := cr.Spec.Config
+	if config == nil {
+		return "", nil
+	}
+	host, err := c.GetHostConfig(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	var changed []string
+	setString := func(name string, setting *string, value string) {
+		// The API names enums in lower case, config.xml capitalized
+		if value != "" && !strings.EqualFold(*setting, value) {
+			*setting = strings.ToLower(value[:1]) + value[1:]
+			changed = append(changed, name)
+		}
+	}
+	setInt := func(name string, setting *int, value int32) {
+		if value != 0 && *setting != int(value) {
+			*setting = int(value)
+			changed = append(changed, name)
+		}
+	}
+	setBool := func(name string, setting *bool, value *bool) {
+		if value != nil && *setting != *value {
+			*setting = *value
+			changed = append(changed, name)
+		}
+	}
+	setInt("port", &host.Port, config.Port)
+	if config.URLBase != "" && host.URLBase != config.URLBase {
+		host.URLBase = config.URLBase
+		changed = append(changed, "urlBase")
+	}
+	setString("authenticationMethod", &host.AuthenticationMethod, config.AuthenticationMethod)
+	setString("logLevel", &host.LogLevel, config.LogLevel)
+	setString("branch", &host.Branch, config.Branch)
+	setBool("analyticsEnabled", &host.AnalyticsEnabled, config.AnalyticsEnabled)
+	setBool("enableSsl", &host.EnableSSL, config.EnableSSL)
+	setInt("sslPort", &host.SSLPort, config.SSLPort)
+	if config.SSLCertPath != "" && host.SSLCertPath != config.SSLCertPath {
+		host.SSLCertPath = config.SSLCertPath
+		changed = append(changed, "sslCertPath")
+	}
+
+	if len(changed) == 0 || !drift.found("host config differs: %s", strings.Join(changed, ", ")) {
+		return "", nil
+	}
+	log.Info("Updating host config", "Namespace", cr.Namespace, "Name", cr.Name, "Settings", changed)
+	if _, err := c.UpdateHostConfig(ctx, host); err != nil {
+		return "", fmt.Errorf("update host config: %v", err)
+	}
+	return "", nil
+}
+
+// reconcileMediaManagement compares the media management settings in Sonarr with those of cr and updates those that
+// differ
+func (r *ReconcileSonarr) reconcileMediaManagement(ctx context.Context, c *sonarrapi.Client, cr *sonarrv1alpha1.Sonarr, status *sonarrv1alpha1.SonarrStatus, drift *settingsDrift) (string, error) {
+	spec := cr.Spec.MediaManagement
+	if spec == nil {
+		return "", nil
+	}
+	media, err := c.GetMediaManagementConfig(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	var changed []string
+	setString := func(name string, setting *string, value string) {
+		if value != "" && *setting != value {
+			*setting = value
+			changed = append(changed, name)
+		}
+	}
+	setInt := func(name string, setting *int, value *int32) {
+		if value != nil && *setting != int(*value) {
+			*setting = int(*value)
+			changed = append(changed, name)
+		}
+	}
+	setBool := func(name string, setting *bool, value *bool) {
+		if value != nil && *setting != *value {
+			*setting = *value
+			changed = append(changed, name)
+		}
+	}
+	setBool("createEmptySeriesFolders", &media.CreateEmptySeriesFolders, spec.CreateEmptySeriesFolders)
+	setBool("deleteEmptyFolders", &media.DeleteEmptyFolders, spec.DeleteEmptyFolders)
+	setBool("copyUsingHardlinks", &media.CopyUsingHardlinks, spec.UseHardlinks)
+	setBool("importExtraFiles", &media.ImportExtraFiles, spec.ImportExtraFiles)
+	setString("extraFileExtensions", &media.ExtraFileExtensions, strings.Join(spec.ExtraFileExtensions, ","))
+	setString("recycleBin", &media.RecycleBin, spec.RecycleBin)
+	setInt("recycleBinCleanupDays", &media.RecycleBinCleanupDays, spec.RecycleBinCleanupDays)
+	setInt("minimumFreeSpaceWhenImporting", &media.MinimumFreeSpaceWhenImporting, spec.MinimumFreeSpaceMB)
+	setBool("setPermissionsLinux", &media.SetPermissionsLinux, spec.SetPermissions)
+	setString("chmodFolder", &media.ChmodFolder, spec.ChmodFolder)
+
+	if len(changed) == 0 || !drift.found("media management differs: %s", strings.Join(changed, ", ")) {
+		return "", nil
+	}
+	log.Info("Updating media management config", "Namespace", cr.Namespace, "Name", cr.Name, "Settings", changed)
+	if _, err := c.UpdateMediaManagementConfig(ctx, media); err != nil {
+		return "", fmt.Errorf("update media management config: %v", err)
+	}
+	return "", nil
+}
@@ -0,0 +1,123 @@
+package sonarr
+
+import (
+	"strings"
+	"testing"
+
+	sonarrv1alpha1 "github.com/parflesh/sonarr-operator/pkg/apis/sonarr/v1alpha1"
+	"github.com/parflesh/sonarr-operator/pkg/sonarrapi"
+	"github.com/parflesh/sonarr-operator/pkg/sonarrapi/sonarrapitest"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/tools/record"
+)
+
+func TestSonarrControllerDriftMode(t *testing.T) {
+	hardlinks := false
+	cr := newTestSonarr(sonarrv1alpha1.SonarrSpec{
+		APIKeySecret: "sonarr-api-key",
+		DriftMode:    sonarrv1alpha1.DriftDetect,
+		Volumes: []sonarrv1alpha1.SonarrSpecVolume{
+			{Name: "config", Claim: "sonarr-config", MountPath: "/config"},
+		},
+		Config:          &sonarrv1alpha1.SonarrSpecConfig{LogLevel: "debug", AuthenticationMethod: "None"},
+		MediaManagement: &sonarrv1alpha1.SonarrSpecMediaManagement{UseHardlinks: &hardlinks, ChmodFolder: "775"},
+		ReleaseProfiles: []sonarrv1alpha1.SonarrSpecReleaseProfile{{Name: "x265", Required: []string{"x265"}}},
+	})
+	r, server, req := newApplicationTest(t, cr)
+	defer server.Close()
+	recorder := r.recorder.(*record.FakeRecorder)
+
+	// Detecting drift leaves Sonarr alone
+	requests := len(server.Requests())
+	reconcileTimes(t, r, req, 1)
+	for _, request := range server.Requests()[requests:] {
+		if !strings.HasPrefix(request, "GET") {
+			t.Errorf("unexpected request detecting drift: %s", request)
+		}
+	}
+	cr = getSonarr(t, r, req)
+	drifted := findCondition(cr, sonarrv1alpha1.ConditionDrifted)
+	expected := "host config differs: logLevel; media management differs: copyUsingHardlinks, chmodFolder; release profile x265 missing"
+	if drifted == nil || drifted.Status != corev1.ConditionTrue || drifted.Message != expected {
+		t.Errorf("unexpected drifted condition: %v", drifted)
+	}
+	if len(recorder.Events) != 3 {
+		t.Errorf("expected an event for each difference, got %d", len(recorder.Events))
+	}
+	if event := <-recorder.Events; event != "Warning Drifted host config differs: logLevel" {
+		t.Errorf("unexpected event: %s", event)
+	}
+
+	// Differences already reported do not emit events again
+	reconcileTimes(t, r, req, 1)
+	if len(recorder.Events) != 2 {
+		t.Errorf("differences reported again: %d events", len(recorder.Events))
+	}
+
+	// Enforcing the spec applies it
+	cr = getSonarr(t, r, req)
+	cr.Spec.DriftMode = sonarrv1alpha1.DriftEnforce
+	updateSonarr(t, r, cr)
+	reconcileTimes(t, r, req, 1)
+	host := sonarrapi.HostConfig{}
+	server.Settings(sonarrapitest.HostConfig, &host)
+	media := sonarrapi.MediaManagementConfig{}
+	server.Settings(sonarrapitest.MediaManagementConfig, &media)
+	var profiles []sonarrapi.ReleaseProfile
+	server.List(sonarrapitest.ReleaseProfiles, &profiles)
+	if host.LogLevel != "debug" || host.AuthenticationMethod != "none" || media.CopyUsingHardlinks || media.ChmodFolder != "775" || len(profiles) != 1 {
+		t.Errorf("spec not enforced: %+v %+v %v", host, media, profiles)
+	}
+	cr = getSonarr(t, r, req)
+	if findCondition(cr, sonarrv1alpha1.ConditionDrifted) != nil {
+		t.Errorf("drifted condition kept when enforcing: %v", cr.Status.Conditions)
+	}
+
+	// Ignoring drift keeps changes made in the Sonarr UI
+	cr.Spec.DriftMode = sonarrv1alpha1.DriftIgnore
+	updateSonarr(t, r, cr)
+	server.SetSettings(sonarrapitest.MediaManagementConfig, map[string]interface{}{"chmodFolder": "755"})
+	requests = len(server.Requests())
+	reconcileTimes(t, r, req, 1)
+	for _, request := range server.Requests()[requests:] {
+		if !strings.HasPrefix(request, "GET /system") && request != "GET /health" {
+			t.Errorf("unexpected request ignoring drift: %s", request)
+		}
+	}
+}
+
+func TestSonarrControllerDriftModeProviders(t *testing.T) {
+	cr := newTestSonarr(sonarrv1alpha1.SonarrSpec{
+		APIKeySecret: "sonarr-api-key",
+		DriftMode:    sonarrv1alpha1.DriftDetect,
+		DownloadClients: []sonarrv1alpha1.SonarrSpecDownloadClient{
+			{Name: "sabnzbd", Implementation: "Sabnzbd", Host: "sabnzbd", Port: 8080},
+		},
+		Indexers: []sonarrv1alpha1.SonarrSpecIndexer{
+			{Name: "jackett", Implementation: "Torznab", URL: "http://jackett:9117/api/v2.0/indexers/all/results/torznab"},
+		},
+		Notifications: []sonarrv1alpha1.SonarrSpecNotification{
+			{Name: "hooks", Webhook: &sonarrv1alpha1.SonarrSpecNotificationWebhook{URL: "http://hooks.example.com/sonarr"}},
+		},
+	})
+	r, server, req := newApplicationTest(t, cr)
+	defer server.Close()
+
+	// Providers missing in Sonarr are reported as drift, and not tested
+	requests := len(server.Requests())
+	reconcileTimes(t, r, req, 1)
+	for _, request := range server.Requests()[requests:] {
+		if !strings.HasPrefix(request, "GET") {
+			t.Errorf("unexpected request detecting drift: %s", request)
+		}
+	}
+	cr = getSonarr(t, r, req)
+	drifted := findCondition(cr, sonarrv1alpha1.ConditionDrifted)
+	expected := "download client sabnzbd missing; indexer jackett missing; notification hooks missing"
+	if drifted == nil || drifted.Status != corev1.ConditionTrue || drifted.Message != expected {
+		t.Errorf("unexpected drifted condition: %v", drifted)
+	}
+	if len(cr.Status.DownloadClients) != 0 || len(cr.Status.Notifications) != 0 || findCondition(cr, sonarrv1alpha1.ConditionIndexerPrefix+"jackett") != nil {
+		t.Errorf("missing providers reported as tested: %v %v %v", cr.Status.DownloadClients, cr.Status.Notifications, cr.Status.Conditions)
+	}
+}
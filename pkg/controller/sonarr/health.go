@@ -0,0 +1,188 @@
+package sonarr
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	sonarrv1alpha1 "github.com/parflesh/sonarr-operator/pkg/apis/sonarr/v1alpha1"
+	"github.com/parflesh/sonarr-operator/pkg/sonarrapi"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// statusTimeout limits how long a reconcile waits for the status and health of Sonarr
+var statusTimeout = 10 * time.Second
+
+// condition returns c with the transition time of the condition of the same type in conditions, when its status
+// did not change
+func condition(conditions []sonarrv1alpha1.SonarrCondition, c sonarrv1alpha1.SonarrCondition) sonarrv1alpha1.SonarrCondition {
+	c.LastTransitionTime = metav1.Now()
+	for _, old := range conditions {
+		if old.Type == c.Type && old.Status == c.Status {
+			c.LastTransitionTime = old.LastTransitionTime
+		}
+	}
+	return c
+}
+
+// lastCondition returns the condition of conditionType in conditions, or nil when there is none
+func lastCondition(conditions []sonarrv1alpha1.SonarrCondition, conditionType string) *sonarrv1alpha1.SonarrCondition {
+	for i := range conditions {
+		if conditions[i].Type == conditionType {
+			return &conditions[i]
+		}
+	}
+	return nil
+}
+
+// applicationConditions returns the conditions of conditions set when applying settings, the indexer tests and
+// drift, which are kept by polls
+func applicationConditions(conditions []sonarrv1alpha1.SonarrCondition) []sonarrv1alpha1.SonarrCondition {
+	var kept []sonarrv1alpha1.SonarrCondition
+	for _, c := range conditions {
+		if strings.HasPrefix(c.Type, sonarrv1alpha1.ConditionIndexerPrefix) || c.Type == sonarrv1alpha1.ConditionDrifted {
+			kept = append(kept, c)
+		}
+	}
+	return kept
+}
+
+// checkApplication polls the status and health checks of Sonarr through its Service and reports them in status.  It
+// returns why Sonarr is degraded, or an empty string when it is healthy or not polled.
+func (r *ReconcileSonarr) checkApplication(cr *sonarrv1alpha1.Sonarr, status *sonarrv1alpha1.SonarrStatus, available bool) string {
+	old := status.Conditions
+	if cr.Spec.APIKeySecret == "" {
+		// Without the API key Sonarr cannot be asked
+		status.Application = nil
+		status.Conditions = nil
+		return ""
+	}
+
+	unreachable := func(reason string, message string) {
+		status.Conditions = []sonarrv1alpha1.SonarrCondition{
+			condition(old, sonarrv1alpha1.SonarrCondition{
+				Type:    sonarrv1alpha1.ConditionApplicationReachable,
+				Status:  corev1.ConditionFalse,
+				Reason:  reason,
+				Message: message,
+			}),
+			condition(old, sonarrv1alpha1.SonarrCondition{
+				Type:   sonarrv1alpha1.ConditionApplicationHealthy,
+				Status: corev1.ConditionUnknown,
+				Reason: reason,
+			}),
+		}
+		status.Conditions = append(status.Conditions, applicationConditions(old)...)
+	}
+	if !available {
+		unreachable("NotRunning", "No Sonarr pod is available")
+		return ""
+	}
+
+	c, err := r.sonarrClient(cr)
+	if err != nil {
+		unreachable("APIKeyUnavailable", err.Error())
+		return fmt.Sprintf("Sonarr API key unavailable: %v", err)
+	}
+	ctx, cancel := context.WithTimeout(context.TODO(), statusTimeout)
+	defer cancel()
+	app, err := c.GetSystemStatus(ctx)
+	var checks []sonarrapi.HealthCheck
+	if err == nil {
+		checks, err = c.GetHealth(ctx)
+	}
+	if err != nil {
+		reason := "Unreachable"
+		if sonarrapi.IsUnauthorized(err) {
+			reason = "Unauthorized"
+		}
+		unreachable(reason, err.Error())
+		return fmt.Sprintf("Sonarr API unreachable: %v", err)
+	}
+
+	status.Application = &sonarrv1alpha1.SonarrStatusApplication{
+		Version: app.Version,
+		Branch:  app.Branch,
+	}
+	if !app.StartTime.IsZero() {
+		status.Application.StartTime = &metav1.Time{Time: app.StartTime}
+	}
+
+	var failing []string
+	var sources []string
+	bySource := map[string][]sonarrapi.HealthCheck{}
+	for _, check := range checks {
+		if check.Type == "" {
+			check.Type = sonarrapi.HealthNotice
+		}
+		if check.Type != sonarrapi.HealthNotice && check.Type != sonarrapi.HealthOK {
+			failing = append(failing, check.Message)
+		}
+		if _, ok := bySource[check.Source]; !ok {
+			sources = append(sources, check.Source)
+		}
+		bySource[check.Source] = append(bySource[check.Source], check)
+	}
+	var checkConditions []sonarrv1alpha1.SonarrCondition
+	for _, source := range sources {
+		checkConditions = append(checkConditions, condition(old, healthCondition(source, bySource[source])))
+	}
+
+	healthy := sonarrv1alpha1.SonarrCondition{
+		Type:   sonarrv1alpha1.ConditionApplicationHealthy,
+		Status: corev1.ConditionTrue,
+		Reason: "ChecksPassing",
+	}
+	if len(failing) > 0 {
+		healthy.Status = corev1.ConditionFalse
+		healthy.Reason = "ChecksFailing"
+		healthy.Message = strings.Join(failing, "; ")
+	}
+	status.Conditions = append([]sonarrv1alpha1.SonarrCondition{
+		condition(old, sonarrv1alpha1.SonarrCondition{
+			Type:   sonarrv1alpha1.ConditionApplicationReachable,
+			Status: corev1.ConditionTrue,
+			Reason: "Polled",
+		}),
+		condition(old, healthy),
+	}, checkConditions...)
+	status.Conditions = append(status.Conditions, applicationConditions(old)...)
+
+	if len(failing) > 0 {
+		return fmt.Sprintf("Sonarr health checks failing: %s", healthy.Message)
+	}
+	return ""
+}
+
+// healthSeverity orders the health check types of Sonarr from the least to the most severe
+var healthSeverity = []string{sonarrapi.HealthOK, sonarrapi.HealthNotice, sonarrapi.HealthWarning, sonarrapi.HealthError}
+
+// healthCondition returns the condition reporting the health checks of source.  A source may report several
+// problems, their messages are combined and the most severe type is the reason.
+func healthCondition(source string, checks []sonarrapi.HealthCheck) sonarrv1alpha1.SonarrCondition {
+	severity := func(checkType string) int {
+		for i, t := range healthSeverity {
+			if t == checkType {
+				return i
+			}
+		}
+		return 0
+	}
+
+	checkType := checks[0].Type
+	var messages []string
+	for _, check := range checks {
+		if severity(check.Type) > severity(checkType) {
+			checkType = check.Type
+		}
+		messages = append(messages, check.Message)
+	}
+	return sonarrv1alpha1.SonarrCondition{
+		Type:    source,
+		Status:  corev1.ConditionFalse,
+		Reason:  strings.ToUpper(checkType[:1]) + checkType[1:],
+		Message: strings.Join(messages, "; "),
+	}
+}
@@ -0,0 +1,87 @@
+package sonarr
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	sonarrv1alpha1 "github.com/parflesh/sonarr-operator/pkg/apis/sonarr/v1alpha1"
+	"github.com/parflesh/sonarr-operator/pkg/sonarrapi"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+)
+
+// findCondition returns the condition of cr with conditionType, or nil
+func findCondition(cr *sonarrv1alpha1.Sonarr, conditionType string) *sonarrv1alpha1.SonarrCondition {
+	for i := range cr.Status.Conditions {
+		if cr.Status.Conditions[i].Type == conditionType {
+			return &cr.Status.Conditions[i]
+		}
+	}
+	return nil
+}
+
+func TestSonarrControllerApplicationHealth(t *testing.T) {
+	cr := newTestSonarr(sonarrv1alpha1.SonarrSpec{
+		APIKeySecret: "sonarr-api-key",
+	})
+	r, server, req := newSonarrAPITest(cr)
+	defer server.Close()
+
+	reconcileTimes(t, r, req, 4)
+	cr = getSonarr(t, r, req)
+	if c := findCondition(cr, sonarrv1alpha1.ConditionApplicationReachable); c == nil || c.Status != corev1.ConditionFalse || c.Reason != "NotRunning" {
+		t.Errorf("unexpected reachable condition before deployment available: %v", c)
+	}
+
+	dep := getDeployment(t, r, req)
+	dep.Status.AvailableReplicas = 1
+	dep.Status.Conditions = []appsv1.DeploymentCondition{{Type: appsv1.DeploymentAvailable, Status: corev1.ConditionTrue}}
+	if err := r.client.Status().Update(context.TODO(), dep); err != nil {
+		t.Fatalf("update deployment status: (%v)", err)
+	}
+	reconcileTimes(t, r, req, 1)
+	cr = getSonarr(t, r, req)
+	if cr.Status.Phase != "Available" {
+		t.Errorf("unexpected phase: %s %s", cr.Status.Phase, cr.Status.Reason)
+	}
+	if cr.Status.Application == nil || cr.Status.Application.Version != server.Status.Version || cr.Status.Application.Branch != "main" {
+		t.Errorf("application status not reported: %v", cr.Status.Application)
+	}
+	if c := findCondition(cr, sonarrv1alpha1.ConditionApplicationHealthy); c == nil || c.Status != corev1.ConditionTrue {
+		t.Errorf("unexpected healthy condition: %v", c)
+	}
+
+	// Failing health checks degrade the Sonarr and are listed as conditions
+	server.Health = []sonarrapi.HealthCheck{
+		{Source: "IndexerRssCheck", Type: sonarrapi.HealthWarning, Message: "No indexers available with RSS sync enabled"},
+		{Source: "UpdateCheck", Type: sonarrapi.HealthNotice, Message: "New update available"},
+		{Source: "UpdateCheck", Type: sonarrapi.HealthWarning, Message: "Cannot install update"},
+	}
+	reconcileTimes(t, r, req, 1)
+	cr = getSonarr(t, r, req)
+	if cr.Status.Phase != "Degraded" || !strings.Contains(cr.Status.Reason, "RSS sync") || strings.Contains(cr.Status.Reason, "New update") {
+		t.Errorf("unexpected phase: %s %s", cr.Status.Phase, cr.Status.Reason)
+	}
+	if c := findCondition(cr, "IndexerRssCheck"); c == nil || c.Status != corev1.ConditionFalse || c.Reason != "Warning" {
+		t.Errorf("unexpected health check condition: %v", c)
+	}
+	if c := findCondition(cr, "UpdateCheck"); c == nil || c.Reason != "Warning" || c.Message != "New update available; Cannot install update" {
+		t.Errorf("same source health checks not combined: %v", c)
+	}
+	if len(cr.Status.Conditions) != 4 {
+		t.Errorf("unexpected conditions: %v", cr.Status.Conditions)
+	}
+
+	// Resolved checks are removed and an unreachable API degrades the Sonarr
+	server.Health = nil
+	server.APIKey = "rotated"
+	reconcileTimes(t, r, req, 1)
+	cr = getSonarr(t, r, req)
+	if c := findCondition(cr, sonarrv1alpha1.ConditionApplicationReachable); c == nil || c.Status != corev1.ConditionFalse || c.Reason != "Unauthorized" {
+		t.Errorf("unexpected reachable condition: %v", c)
+	}
+	if findCondition(cr, "IndexerRssCheck") != nil || cr.Status.Phase != "Degraded" {
+		t.Errorf("unexpected status: %v", cr.Status)
+	}
+}
@@ -0,0 +1,200 @@
+package sonarr
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	sonarrv1alpha1 "github.com/parflesh/sonarr-operator/pkg/apis/sonarr/v1alpha1"
+	"github.com/parflesh/sonarr-operator/pkg/sonarrapi"
+	corev1 "k8s.io/api/core/v1"
+)
+
+// indexerImplementations maps the indexer implementations to their Sonarr settings contract and protocol
+var indexerImplementations = map[string]struct {
+	contract string
+	protocol string
+}{
+	"Newznab": {"NewznabSettings", sonarrapi.ProtocolUsenet},
+	"Torznab": {"TorznabSettings", sonarrapi.ProtocolTorrent},
+}
+
+// defaultIndexerCategories are the Newznab categories of SD and HD TV
+var defaultIndexerCategories = []int32{5030, 5040}
+
+// defaultIndexerPriority is the priority Sonarr gives new indexers
+const defaultIndexerPriority = 25
+
+// indexerFields returns the Sonarr fields set by spec, with the API key from its secret, and the hash of the secret
+// data
+func (r *ReconcileSonarr) indexerFields(cr *sonarrv1alpha1.Sonarr, spec sonarrv1alpha1.SonarrSpecIndexer) (map[string]interface{}, string, error) {
+	apiPath := spec.APIPath
+	if apiPath == "" {
+		apiPath = "/api"
+	}
+	// Sonarr returns empty lists rather than null
+	categories := append([]int32{}, spec.Categories...)
+	if len(categories) == 0 {
+		categories = defaultIndexerCategories
+	}
+	animeCategories := append([]int32{}, spec.AnimeCategories...)
+
+	fields := map[string]interface{}{
+		"baseUrl":         spec.URL,
+		"apiPath":         apiPath,
+		"categories":      categories,
+		"animeCategories": animeCategories,
+	}
+
+	data, secretHash, err := r.secretData(cr, spec.APIKeySecret)
+	if err != nil {
+		return nil, "", err
+	}
+	if apiKey, ok := data["apiKey"]; ok {
+		fields["apiKey"] = strings.TrimSpace(string(apiKey))
+	} else if spec.APIKeySecret != "" {
+		return nil, "", fmt.Errorf("secret %s has no apiKey", spec.APIKeySecret)
+	}
+	return fields, secretHash, nil
+}
+
+// reconcileIndexers adds the indexers of cr missing in Sonarr, updates those that differ and has Sonarr test each,
+// when needsTest says so, recording the tests as conditions.  Indexers not in the spec are left alone.  It returns
+// the indexers with an unknown implementation or failing their test.
+func (r *ReconcileSonarr) reconcileIndexers(ctx context.Context, c *sonarrapi.Client, cr *sonarrv1alpha1.Sonarr, status *sonarrv1alpha1.SonarrStatus, drift *settingsDrift) (string, error) {
+	old := applicationConditions(status.Conditions)
+	var conditions []sonarrv1alpha1.SonarrCondition
+	for _, cond := range status.Conditions {
+		if !strings.HasPrefix(cond.Type, sonarrv1alpha1.ConditionIndexerPrefix) {
+			conditions = append(conditions, cond)
+		}
+	}
+	status.Conditions = conditions
+	if len(cr.Spec.Indexers) == 0 {
+		setProviders(status, providerIndexer, nil)
+		return "", nil
+	}
+
+	indexers, err := c.GetIndexers(ctx)
+	if err != nil {
+		return "", err
+	}
+	existing := map[string]sonarrapi.Indexer{}
+	for _, indexer := range indexers {
+		existing[indexer.Name] = indexer
+	}
+
+	var providers []sonarrv1alpha1.SonarrStatusProvider
+	var unknown, failing []string
+	for _, spec := range cr.Spec.Indexers {
+		implementation, ok := indexerImplementations[spec.Implementation]
+		if !ok {
+			unknown = append(unknown, spec.Name)
+			continue
+		}
+		fields, secretHash, err := r.indexerFields(cr, spec)
+		if err != nil {
+			return "", fmt.Errorf("indexer %s: %v", spec.Name, err)
+		}
+		priority := int(spec.Priority)
+		if priority == 0 {
+			priority = defaultIndexerPriority
+		}
+
+		indexer, found := existing[spec.Name]
+		changed := !found ||
+			indexer.Implementation != spec.Implementation ||
+			indexer.Priority != priority ||
+			indexer.EnableRss == spec.DisableRss ||
+			indexer.EnableAutomaticSearch == spec.DisableAutomaticSearch ||
+			indexer.EnableInteractiveSearch == spec.DisableInteractiveSearch
+		indexer.Name = spec.Name
+		indexer.Implementation = spec.Implementation
+		indexer.ConfigContract = implementation.contract
+		indexer.Protocol = implementation.protocol
+		indexer.Priority = priority
+		indexer.EnableRss = !spec.DisableRss
+		indexer.EnableAutomaticSearch = !spec.DisableAutomaticSearch
+		indexer.EnableInteractiveSearch = !spec.DisableInteractiveSearch
+		if indexer.Tags == nil {
+			indexer.Tags = []int{}
+		}
+		record := recordedProvider(status, providerIndexer, spec.Name)
+		fieldsChanged, err := setFields(&indexer.Provider, fields, record.SecretHash != secretHash)
+		if err != nil {
+			return "", err
+		}
+		hash, err := specHash(spec, secretHash)
+		if err != nil {
+			return "", err
+		}
+
+		written := false
+		if !found && drift.found("indexer %s missing", spec.Name) {
+			log.Info("Adding indexer", "Namespace", cr.Namespace, "Name", cr.Name, "Indexer", spec.Name)
+			created, err := c.CreateIndexer(ctx, &indexer)
+			if err != nil {
+				return "", fmt.Errorf("add indexer %s: %v", spec.Name, err)
+			}
+			indexer = *created
+			record.SecretHash = secretHash
+			written = true
+		} else if found && (changed || fieldsChanged) && drift.found("indexer %s differs", spec.Name) {
+			log.Info("Updating indexer", "Namespace", cr.Namespace, "Name", cr.Name, "Indexer", spec.Name)
+			if _, err := c.UpdateIndexer(ctx, &indexer); err != nil {
+				return "", fmt.Errorf("update indexer %s: %v", spec.Name, err)
+			}
+			record.SecretHash = secretHash
+			written = true
+		} else if found && !changed && !fieldsChanged {
+			record.SecretHash = secretHash
+		}
+		if !found && !written {
+			// The spec is not enforced, so the missing indexer is only reported as drift.  Testing it would send
+			// its credentials to Sonarr for a indexer Sonarr does not have.
+			providers = append(providers, record)
+			continue
+		}
+
+		test := sonarrv1alpha1.SonarrCondition{
+			Type:   sonarrv1alpha1.ConditionIndexerPrefix + spec.Name,
+			Status: corev1.ConditionTrue,
+			Reason: "TestPassed",
+		}
+		var passed *bool
+		last := lastCondition(old, test.Type)
+		if last != nil {
+			lastPassed := last.Status == corev1.ConditionTrue
+			passed = &lastPassed
+		}
+		if !needsTest(record, hash, written, passed) {
+			test = *last
+		} else {
+			if err := c.TestIndexer(ctx, &indexer); err != nil {
+				if !sonarrapi.IsBadRequest(err) {
+					return "", err
+				}
+				test.Status = corev1.ConditionFalse
+				test.Reason = "TestFailed"
+				test.Message = testMessage(err)
+			}
+			setTested(&record, hash)
+		}
+		if test.Status != corev1.ConditionTrue {
+			failing = append(failing, spec.Name)
+		}
+		status.Conditions = append(status.Conditions, condition(old, test))
+		providers = append(providers, record)
+	}
+
+	setProviders(status, providerIndexer, providers)
+
+	var problems []string
+	if len(unknown) > 0 {
+		problems = append(problems, fmt.Sprintf("Indexers with unknown implementation: %s", strings.Join(unknown, ", ")))
+	}
+	if len(failing) > 0 {
+		problems = append(problems, fmt.Sprintf("Indexers failing test: %s", strings.Join(failing, ", ")))
+	}
+	return strings.Join(problems, "; "), nil
+}
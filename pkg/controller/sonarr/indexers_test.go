@@ -0,0 +1,112 @@
+package sonarr
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	sonarrv1alpha1 "github.com/parflesh/sonarr-operator/pkg/apis/sonarr/v1alpha1"
+	"github.com/parflesh/sonarr-operator/pkg/sonarrapi"
+	"github.com/parflesh/sonarr-operator/pkg/sonarrapi/sonarrapitest"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestSonarrControllerIndexers(t *testing.T) {
+	cr := newTestSonarr(sonarrv1alpha1.SonarrSpec{
+		APIKeySecret: "sonarr-api-key",
+		Indexers: []sonarrv1alpha1.SonarrSpecIndexer{
+			{Name: "nzbgeek", Implementation: "Newznab", URL: "https://api.nzbgeek.info", APIKeySecret: "nzbgeek"},
+			{Name: "jackett", Implementation: "Torznab", URL: "http://jackett:9117/api/v2.0/indexers/all/results/torznab", Categories: []int32{5000}, Priority: 10, DisableRss: true},
+		},
+	})
+	r, server, req := newApplicationTest(t, cr)
+	defer server.Close()
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "nzbgeek", Namespace: "sonarr"},
+		Data:       map[string][]byte{"apiKey": []byte("nzbgeek-key")},
+	}
+	if err := r.client.Create(context.TODO(), secret); err != nil {
+		t.Fatalf("create secret: (%v)", err)
+	}
+	server.TestFailures = map[string]string{"jackett": "Unable to connect to indexer"}
+
+	reconcileTimes(t, r, req, 1)
+	var indexers []sonarrapi.Indexer
+	server.List(sonarrapitest.Indexers, &indexers)
+	if len(indexers) != 2 {
+		t.Fatalf("unexpected indexers: %v", indexers)
+	}
+	nzbgeek, jackett := indexers[0], indexers[1]
+	if nzbgeek.ConfigContract != "NewznabSettings" || nzbgeek.Priority != 25 || !nzbgeek.EnableRss || nzbgeek.Field("apiKey") != "nzbgeek-key" || nzbgeek.Field("apiPath") != "/api" {
+		t.Errorf("unexpected indexer: %v", nzbgeek)
+	}
+	if !reflect.DeepEqual(nzbgeek.Field("categories"), []interface{}{float64(5030), float64(5040)}) {
+		t.Errorf("default categories not set: %v", nzbgeek.Field("categories"))
+	}
+	if jackett.Protocol != sonarrapi.ProtocolTorrent || jackett.Priority != 10 || jackett.EnableRss || !jackett.EnableAutomaticSearch {
+		t.Errorf("unexpected indexer: %v", jackett)
+	}
+
+	cr = getSonarr(t, r, req)
+	if c := findCondition(cr, "Indexer/nzbgeek"); c == nil || c.Status != corev1.ConditionTrue {
+		t.Errorf("unexpected indexer condition: %v", c)
+	}
+	failed := findCondition(cr, "Indexer/jackett")
+	if failed == nil || failed.Status != corev1.ConditionFalse || failed.Message != "Unable to connect to indexer" {
+		t.Fatalf("unexpected indexer condition: %v", failed)
+	}
+	if cr.Status.Phase != "Degraded" || cr.Status.Reason != "Indexers failing test: jackett" {
+		t.Errorf("failing indexer not reported: %s %s", cr.Status.Phase, cr.Status.Reason)
+	}
+
+	// Changing the spec updates the indexer, and a passing test clears the failure
+	transition := failed.LastTransitionTime
+	cr.Spec.Indexers[1].DisableRss = false
+	updateSonarr(t, r, cr)
+	server.TestFailures = nil
+	reconcileTimes(t, r, req, 1)
+	indexers = nil
+	server.List(sonarrapitest.Indexers, &indexers)
+	if !indexers[1].EnableRss {
+		t.Error("indexer not updated")
+	}
+	cr = getSonarr(t, r, req)
+	if c := findCondition(cr, "Indexer/jackett"); c == nil || c.Status != corev1.ConditionTrue || c.LastTransitionTime.Before(&transition) {
+		t.Errorf("unexpected indexer condition: %v", c)
+	}
+	if cr.Status.Phase != "Available" {
+		t.Errorf("unexpected phase: %s %s", cr.Status.Phase, cr.Status.Reason)
+	}
+
+	// Indexers matching the spec are not tested again, keeping their conditions
+	requests := len(server.Requests())
+	reconcileTimes(t, r, req, 1)
+	if tests := countRequests(server, requests, "POST /indexer/test"); tests != 0 {
+		t.Errorf("unchanged indexers tested again: %d", tests)
+	}
+	if c := findCondition(getSonarr(t, r, req), "Indexer/jackett"); c == nil || c.Status != corev1.ConditionTrue {
+		t.Errorf("indexer condition not kept: %v", c)
+	}
+
+	// Indexers with an unknown implementation are reported and skipped
+	cr.Spec.Indexers = append(cr.Spec.Indexers, sonarrv1alpha1.SonarrSpecIndexer{Name: "rarbg", Implementation: "Rarbg"})
+	updateSonarr(t, r, cr)
+	reconcileTimes(t, r, req, 1)
+	cr = getSonarr(t, r, req)
+	if cr.Status.Phase != "Degraded" || cr.Status.Reason != "Indexers with unknown implementation: rarbg" {
+		t.Errorf("unknown indexer not reported: %s %s", cr.Status.Phase, cr.Status.Reason)
+	}
+	if c := findCondition(cr, "Indexer/jackett"); c == nil || c.Status != corev1.ConditionTrue {
+		t.Errorf("known indexers not tested: %v", c)
+	}
+
+	// Removing the indexers from the spec removes their conditions
+	cr.Spec.Indexers = nil
+	updateSonarr(t, r, cr)
+	reconcileTimes(t, r, req, 1)
+	cr = getSonarr(t, r, req)
+	if findCondition(cr, "Indexer/nzbgeek") != nil || len(cr.Status.Conditions) != 2 {
+		t.Errorf("indexer conditions not removed: %v", cr.Status.Conditions)
+	}
+}
@@ -0,0 +1,239 @@
+package sonarr
+
+import (
+	"context"
+	"fmt"
+
+	sonarrv1alpha1 "github.com/parflesh/sonarr-operator/pkg/apis/sonarr/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	networkingv1beta1 "k8s.io/api/networking/v1beta1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/client-go/discovery"
+	"k8s.io/client-go/rest"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+)
+
+// ingressClassAnnotation selects the ingress controller serving an Ingress
+const ingressClassAnnotation = "kubernetes.io/ingress.class"
+
+// routeGVK identifies OpenShift Routes.  Routes are handled as unstructured objects, so the operator does not
+// depend on the OpenShift API types.
+var routeGVK = schema.GroupVersionKind{Group: "route.openshift.io", Version: "v1", Kind: "Route"}
+
+// routeAPIAvailable reports whether the cluster serves OpenShift Routes
+func routeAPIAvailable(cfg *rest.Config) (bool, error) {
+	dc, err := discovery.NewDiscoveryClientForConfig(cfg)
+	if err != nil {
+		return false, err
+	}
+	resources, err := dc.ServerResourcesForGroupVersion(routeGVK.GroupVersion().String())
+	if errors.IsNotFound(err) {
+		return false, nil
+	} else if err != nil {
+		return false, err
+	}
+	for _, res := range resources.APIResources {
+		if res.Kind == routeGVK.Kind {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func newRouteObject() *unstructured.Unstructured {
+	route := &unstructured.Unstructured{}
+	route.SetGroupVersionKind(routeGVK)
+	return route
+}
+
+// ingressAnnotations returns the annotations of the Ingress or Route of cr
+func (r *ReconcileSonarr) ingressAnnotations(cr *sonarrv1alpha1.Sonarr, route bool) map[string]string {
+	annotations := map[string]string{}
+	for k, v := range cr.Spec.Ingress.Annotations {
+		annotations[k] = v
+	}
+	if cr.Spec.Ingress.IngressClass != "" && !route {
+		annotations[ingressClassAnnotation] = cr.Spec.Ingress.IngressClass
+	}
+	if len(annotations) == 0 {
+		return nil
+	}
+	return annotations
+}
+
+// ingressPath returns the path Sonarr is exposed on, which defaults to the URL base of Sonarr
+func (r *ReconcileSonarr) ingressPath(cr *sonarrv1alpha1.Sonarr) string {
+	if cr.Spec.Ingress.Path != "" {
+		return cr.Spec.Ingress.Path
+	}
+	return r.urlBase(cr)
+}
+
+func (r *ReconcileSonarr) newIngress(cr *sonarrv1alpha1.Sonarr) (*networkingv1beta1.Ingress, error) {
+	path := r.ingressPath(cr)
+	if path == "" {
+		path = "/"
+	}
+
+	ingress := &networkingv1beta1.Ingress{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        cr.Name,
+			Namespace:   cr.Namespace,
+			Labels:      r.labelsForCR(cr),
+			Annotations: r.ingressAnnotations(cr, false),
+		},
+		Spec: networkingv1beta1.IngressSpec{
+			Rules: []networkingv1beta1.IngressRule{
+				{
+					Host: cr.Spec.Ingress.Host,
+					IngressRuleValue: networkingv1beta1.IngressRuleValue{
+						HTTP: &networkingv1beta1.HTTPIngressRuleValue{
+							Paths: []networkingv1beta1.HTTPIngressPath{
+								{
+									Path: path,
+									Backend: networkingv1beta1.IngressBackend{
+										ServiceName: cr.Name,
+										ServicePort: intstr.FromString("http"),
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+	if cr.Spec.Ingress.TLSSecret != "" {
+		ingress.Spec.TLS = []networkingv1beta1.IngressTLS{
+			{
+				Hosts:      []string{cr.Spec.Ingress.Host},
+				SecretName: cr.Spec.Ingress.TLSSecret,
+			},
+		}
+	}
+
+	err := controllerutil.SetControllerReference(cr, ingress, r.scheme)
+	if err != nil {
+		return ingress, err
+	}
+	return ingress, nil
+}
+
+// newRoute returns the OpenShift Route of cr.  Routes carry the certificate itself, so it is copied from the TLS
+// secret.
+func (r *ReconcileSonarr) newRoute(cr *sonarrv1alpha1.Sonarr) (*unstructured.Unstructured, error) {
+	route := newRouteObject()
+	route.SetName(cr.Name)
+	route.SetNamespace(cr.Namespace)
+	route.SetLabels(r.labelsForCR(cr))
+	route.SetAnnotations(r.ingressAnnotations(cr, true))
+
+	spec := map[string]interface{}{
+		"host": cr.Spec.Ingress.Host,
+		"to": map[string]interface{}{
+			"kind":   "Service",
+			"name":   cr.Name,
+			"weight": int64(100),
+		},
+		"port": map[string]interface{}{
+			"targetPort": "http",
+		},
+		"wildcardPolicy": "None",
+	}
+	if path := r.ingressPath(cr); path != "" {
+		spec["path"] = path
+	}
+	if cr.Spec.Ingress.TLSSecret != "" {
+		secret := &corev1.Secret{}
+		err := r.client.Get(context.TODO(), client.ObjectKey{Namespace: cr.Namespace, Name: cr.Spec.Ingress.TLSSecret}, secret)
+		if err != nil {
+			return route, err
+		}
+		spec["tls"] = map[string]interface{}{
+			"termination":                   "edge",
+			"insecureEdgeTerminationPolicy": "Redirect",
+			"certificate":                   string(secret.Data[corev1.TLSCertKey]),
+			"key":                           string(secret.Data[corev1.TLSPrivateKeyKey]),
+		}
+	}
+	route.Object["spec"] = spec
+
+	err := controllerutil.SetControllerReference(cr, route, r.scheme)
+	if err != nil {
+		return route, err
+	}
+	return route, nil
+}
+
+// reconcileExposure creates, updates or removes the Ingress, or Route when the cluster serves them, of cr.  It
+// returns the phase and reason to report when it changed anything.
+func (r *ReconcileSonarr) reconcileExposure(cr *sonarrv1alpha1.Sonarr) (string, string, error) {
+	kind := "ingress"
+	var found, desired runtime.Object
+	if r.routeAPI {
+		kind = "route"
+		found = newRouteObject()
+		if cr.Spec.Ingress != nil {
+			p, err := r.newRoute(cr)
+			if err != nil {
+				return "", "", err
+			}
+			desired = p
+		}
+	} else {
+		found = &networkingv1beta1.Ingress{}
+		if cr.Spec.Ingress != nil {
+			p, err := r.newIngress(cr)
+			if err != nil {
+				return "", "", err
+			}
+			desired = p
+		}
+	}
+
+	err := r.client.Get(context.TODO(), client.ObjectKey{Namespace: cr.Namespace, Name: cr.Name}, found)
+	if err != nil && !errors.IsNotFound(err) {
+		return "", "", err
+	}
+	exists := err == nil
+
+	if desired == nil {
+		if exists && metav1.IsControlledBy(found.(metav1.Object), cr) {
+			if err := r.client.Delete(context.TODO(), found); err != nil && !errors.IsNotFound(err) {
+				return "", "", err
+			}
+			return "Updating", fmt.Sprintf("Deleted %s", kind), nil
+		}
+		return "", "", nil
+	}
+
+	if !exists {
+		if err := setLastApplied(desired); err != nil {
+			return "", "", err
+		}
+		if err := r.client.Create(context.TODO(), desired); err != nil {
+			return "", "", err
+		}
+		return "Initializing", fmt.Sprintf("Created %s", kind), nil
+	}
+
+	// Only the annotations in the spec are managed, annotations set by others are kept
+	patch, err := mergePatch(found, desired)
+	if err != nil {
+		return "", "", err
+	}
+	if patch == nil {
+		return "", "", nil
+	}
+	log.Info("Exposure drifted from spec", "Namespace", cr.Namespace, "Name", cr.Name, "Kind", kind)
+	if err := r.client.Patch(context.TODO(), found, patch); err != nil {
+		return "", "", err
+	}
+	return "Updating", fmt.Sprintf("Updating %s", kind), nil
+}
@@ -0,0 +1,173 @@
+package sonarr
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	sonarrv1alpha1 "github.com/parflesh/sonarr-operator/pkg/apis/sonarr/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	networkingv1beta1 "k8s.io/api/networking/v1beta1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func TestSonarrControllerIngress(t *testing.T) {
+	cr := newTestSonarr(sonarrv1alpha1.SonarrSpec{
+		Ingress: &sonarrv1alpha1.SonarrSpecIngress{
+			Host:         "sonarr.example.com",
+			TLSSecret:    "sonarr-tls",
+			IngressClass: "nginx",
+			Annotations:  map[string]string{"cert-manager.io/cluster-issuer": "letsencrypt"},
+		},
+	})
+
+	r, req := newTestReconciler(cr)
+
+	reconcileTimes(t, r, req, 4)
+	ingress := &networkingv1beta1.Ingress{}
+	if err := r.client.Get(context.TODO(), req.NamespacedName, ingress); err != nil {
+		t.Fatalf("ingress not created: (%v)", err)
+	}
+	if ingress.Annotations["kubernetes.io/ingress.class"] != "nginx" || ingress.Annotations["cert-manager.io/cluster-issuer"] != "letsencrypt" {
+		t.Errorf("unexpected ingress annotations: %v", ingress.Annotations)
+	}
+	rule := ingress.Spec.Rules[0]
+	if rule.Host != "sonarr.example.com" || rule.HTTP.Paths[0].Path != "/" || rule.HTTP.Paths[0].Backend.ServiceName != testName {
+		t.Errorf("unexpected ingress rule: %v", rule)
+	}
+	if len(ingress.Spec.TLS) != 1 || ingress.Spec.TLS[0].SecretName != "sonarr-tls" {
+		t.Errorf("unexpected ingress tls: %v", ingress.Spec.TLS)
+	}
+
+	// Hand edits are reverted
+	ingress.Spec.Rules[0].Host = "other.example.com"
+	if err := r.client.Update(context.TODO(), ingress); err != nil {
+		t.Fatalf("update ingress: (%v)", err)
+	}
+	reconcileTimes(t, r, req, 1)
+	ingress = &networkingv1beta1.Ingress{}
+	if err := r.client.Get(context.TODO(), req.NamespacedName, ingress); err != nil {
+		t.Fatalf("get ingress: (%v)", err)
+	}
+	if ingress.Spec.Rules[0].Host != "sonarr.example.com" {
+		t.Errorf("ingress host not reverted: %s", ingress.Spec.Rules[0].Host)
+	}
+
+	// Annotations set by others are kept, annotations removed from the spec are removed
+	ingress.Annotations["nginx.ingress.kubernetes.io/proxy-body-size"] = "8m"
+	if err := r.client.Update(context.TODO(), ingress); err != nil {
+		t.Fatalf("update ingress: (%v)", err)
+	}
+	cr = getSonarr(t, r, req)
+	cr.Spec.Ingress.Annotations = nil
+	updateSonarr(t, r, cr)
+	reconcileTimes(t, r, req, 1)
+	ingress = &networkingv1beta1.Ingress{}
+	if err := r.client.Get(context.TODO(), req.NamespacedName, ingress); err != nil {
+		t.Fatalf("get ingress: (%v)", err)
+	}
+	if _, ok := ingress.Annotations["cert-manager.io/cluster-issuer"]; ok || ingress.Annotations["nginx.ingress.kubernetes.io/proxy-body-size"] != "8m" || ingress.Annotations["kubernetes.io/ingress.class"] != "nginx" {
+		t.Errorf("unexpected ingress annotations: %v", ingress.Annotations)
+	}
+
+	// Removing the ingress section removes the ingress
+	cr = getSonarr(t, r, req)
+	cr.Spec.Ingress = nil
+	updateSonarr(t, r, cr)
+	reconcileTimes(t, r, req, 1)
+	if err := r.client.Get(context.TODO(), req.NamespacedName, &networkingv1beta1.Ingress{}); err == nil {
+		t.Error("ingress not deleted")
+	}
+}
+
+func TestSonarrControllerRoute(t *testing.T) {
+	cr := newTestSonarr(sonarrv1alpha1.SonarrSpec{
+		Ingress: &sonarrv1alpha1.SonarrSpecIngress{
+			Host:      "sonarr.apps.example.com",
+			TLSSecret: "sonarr-tls",
+		},
+	})
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "sonarr-tls",
+			Namespace: testNamespace,
+		},
+		Type: corev1.SecretTypeTLS,
+		Data: map[string][]byte{
+			corev1.TLSCertKey:       []byte("certificate"),
+			corev1.TLSPrivateKeyKey: []byte("key"),
+		},
+	}
+
+	r, req := newTestReconciler(cr, secret)
+	r.routeAPI = true
+
+	reconcileTimes(t, r, req, 4)
+	if err := r.client.Get(context.TODO(), req.NamespacedName, &networkingv1beta1.Ingress{}); err == nil {
+		t.Error("ingress created on a cluster serving routes")
+	}
+	route := newRouteObject()
+	if err := r.client.Get(context.TODO(), req.NamespacedName, route); err != nil {
+		t.Fatalf("route not created: (%v)", err)
+	}
+	host, _, _ := unstructured.NestedString(route.Object, "spec", "host")
+	service, _, _ := unstructured.NestedString(route.Object, "spec", "to", "name")
+	certificate, _, _ := unstructured.NestedString(route.Object, "spec", "tls", "certificate")
+	if host != "sonarr.apps.example.com" || service != testName || certificate != "certificate" {
+		t.Errorf("unexpected route: %v", route.Object["spec"])
+	}
+
+	res, err := r.Reconcile(req)
+	if err != nil {
+		t.Fatalf("reconcile: (%v)", err)
+	}
+	if res.Requeue {
+		t.Error("reconcile requeued even though all should be good")
+	}
+
+	// Hand edits are reverted, annotations set by others are kept
+	if err := unstructured.SetNestedField(route.Object, "other.apps.example.com", "spec", "host"); err != nil {
+		t.Fatal(err)
+	}
+	annotations := route.GetAnnotations()
+	annotations["haproxy.router.openshift.io/timeout"] = "2m"
+	route.SetAnnotations(annotations)
+	if err := r.client.Update(context.TODO(), route); err != nil {
+		t.Fatalf("update route: (%v)", err)
+	}
+	reconcileTimes(t, r, req, 1)
+	route = newRouteObject()
+	if err := r.client.Get(context.TODO(), req.NamespacedName, route); err != nil {
+		t.Fatalf("get route: (%v)", err)
+	}
+	host, _, _ = unstructured.NestedString(route.Object, "spec", "host")
+	if host != "sonarr.apps.example.com" || route.GetAnnotations()["haproxy.router.openshift.io/timeout"] != "2m" {
+		t.Errorf("unexpected route: %v %v", route.GetAnnotations(), route.Object["spec"])
+	}
+
+	// The certificate and key stay out of the last applied configuration, and rotating them updates the route
+	secret.Data[corev1.TLSCertKey] = []byte("rotated-certificate")
+	secret.Data[corev1.TLSPrivateKeyKey] = []byte("rotated-key")
+	if err := r.client.Update(context.TODO(), secret); err != nil {
+		t.Fatalf("update secret: (%v)", err)
+	}
+	reconcileTimes(t, r, req, 1)
+	route = newRouteObject()
+	if err := r.client.Get(context.TODO(), req.NamespacedName, route); err != nil {
+		t.Fatalf("get route: (%v)", err)
+	}
+	certificate, _, _ = unstructured.NestedString(route.Object, "spec", "tls", "certificate")
+	key, _, _ := unstructured.NestedString(route.Object, "spec", "tls", "key")
+	if certificate != "rotated-certificate" || key != "rotated-key" {
+		t.Errorf("rotated certificate not applied: %v", route.Object["spec"])
+	}
+	applied := map[string]interface{}{}
+	if err := json.Unmarshal([]byte(route.GetAnnotations()[lastAppliedKey]), &applied); err != nil {
+		t.Fatalf("decode last applied configuration: (%v)", err)
+	}
+	tls, _, _ := unstructured.NestedMap(applied, "spec", "tls")
+	if _, ok := tls["key"]; ok || tls["certificate"] != nil || tls["termination"] != "edge" {
+		t.Errorf("unexpected last applied tls: %v", tls)
+	}
+}
@@ -0,0 +1,264 @@
+package sonarr
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	sonarrv1alpha1 "github.com/parflesh/sonarr-operator/pkg/apis/sonarr/v1alpha1"
+	"github.com/parflesh/sonarr-operator/pkg/sonarrapi"
+)
+
+// defaultNotificationEvents are reported by notifications not listing their events
+var defaultNotificationEvents = []sonarrv1alpha1.SonarrNotificationEvent{
+	sonarrv1alpha1.NotificationOnGrab,
+	sonarrv1alpha1.NotificationOnDownload,
+	sonarrv1alpha1.NotificationOnUpgrade,
+}
+
+// notificationSettings holds the Sonarr implementation, settings contract and fields of a notification, and maps
+// the secret entries holding its credentials to their fields
+type notificationSettings struct {
+	implementation string
+	contract       string
+	fields         map[string]interface{}
+	secretFields   map[string]string
+}
+
+// notificationType returns the settings of the type of notification set in spec, or nil when not exactly one is set
+func notificationType(spec sonarrv1alpha1.SonarrSpecNotification) *notificationSettings {
+	var types []*notificationSettings
+	if w := spec.Webhook; w != nil {
+		// Sonarr numbers the webhook methods
+		method := 1
+		if w.Method == "PUT" {
+			method = 2
+		}
+		types = append(types, &notificationSettings{
+			implementation: "Webhook",
+			contract:       "WebhookSettings",
+			fields:         map[string]interface{}{"url": w.URL, "method": method},
+			secretFields:   map[string]string{"username": "username", "password": "password"},
+		})
+	}
+	if d := spec.Discord; d != nil {
+		types = append(types, &notificationSettings{
+			implementation: "Discord",
+			contract:       "DiscordSettings",
+			fields:         map[string]interface{}{"username": d.Username},
+			secretFields:   map[string]string{"webhookUrl": "webHookUrl"},
+		})
+	}
+	if s := spec.Slack; s != nil {
+		types = append(types, &notificationSettings{
+			implementation: "Slack",
+			contract:       "SlackSettings",
+			fields:         map[string]interface{}{"channel": s.Channel, "username": s.Username},
+			secretFields:   map[string]string{"webhookUrl": "webHookUrl"},
+		})
+	}
+	if e := spec.Email; e != nil {
+		port := e.Port
+		if port == 0 {
+			port = 587
+		}
+		types = append(types, &notificationSettings{
+			implementation: "Email",
+			contract:       "EmailSettings",
+			fields:         map[string]interface{}{"server": e.Server, "port": port, "ssl": e.UseSSL, "from": e.From, "to": e.To},
+			secretFields:   map[string]string{"username": "username", "password": "password"},
+		})
+	}
+	if p := spec.Plex; p != nil {
+		port := p.Port
+		if port == 0 {
+			port = 32400
+		}
+		types = append(types, &notificationSettings{
+			implementation: "PlexServer",
+			contract:       "PlexServerSettings",
+			fields:         map[string]interface{}{"host": p.Host, "port": port, "useSsl": p.UseSSL, "updateLibrary": true},
+			secretFields:   map[string]string{"authToken": "authToken"},
+		})
+	}
+	if len(types) != 1 {
+		return nil
+	}
+	return types[0]
+}
+
+// notificationFields returns the Sonarr fields of settings, with the credentials from the secret of spec, and the
+// hash of the secret data
+func (r *ReconcileSonarr) notificationFields(cr *sonarrv1alpha1.Sonarr, spec sonarrv1alpha1.SonarrSpecNotification, settings *notificationSettings) (map[string]interface{}, string, error) {
+	fields := map[string]interface{}{}
+	for name, value := range settings.fields {
+		// Sonarr returns optional settings left empty as null
+		if value != "" {
+			fields[name] = value
+		}
+	}
+
+	credentials, secretHash, err := r.secretData(cr, spec.Secret)
+	if err != nil {
+		return nil, "", err
+	}
+	for key, name := range settings.secretFields {
+		if value, ok := credentials[key]; ok {
+			fields[name] = strings.TrimSpace(string(value))
+		}
+	}
+	return fields, secretHash, nil
+}
+
+// setNotificationEvents sets the events reported by n from spec and reports whether any changed
+func setNotificationEvents(n *sonarrapi.Notification, spec sonarrv1alpha1.SonarrSpecNotification) bool {
+	events := spec.Events
+	if len(events) == 0 {
+		events = defaultNotificationEvents
+	}
+	enabled := map[sonarrv1alpha1.SonarrNotificationEvent]bool{}
+	for _, e := range events {
+		enabled[e] = true
+	}
+
+	changed := false
+	for event, field := range map[sonarrv1alpha1.SonarrNotificationEvent]*bool{
+		sonarrv1alpha1.NotificationOnGrab:        &n.OnGrab,
+		sonarrv1alpha1.NotificationOnDownload:    &n.OnDownload,
+		sonarrv1alpha1.NotificationOnUpgrade:     &n.OnUpgrade,
+		sonarrv1alpha1.NotificationOnRename:      &n.OnRename,
+		sonarrv1alpha1.NotificationOnHealthIssue: &n.OnHealthIssue,
+	} {
+		if *field != enabled[event] {
+			*field = enabled[event]
+			changed = true
+		}
+	}
+	if n.IncludeHealthWarnings != spec.IncludeHealthWarnings {
+		n.IncludeHealthWarnings = spec.IncludeHealthWarnings
+		changed = true
+	}
+	return changed
+}
+
+// reconcileNotifications adds the notifications of cr missing in Sonarr, updates those that differ and has Sonarr
+// test each, when needsTest says so.  Notifications not in the spec are left alone.  It returns the notifications
+// failing their test or setting no single type.
+func (r *ReconcileSonarr) reconcileNotifications(ctx context.Context, c *sonarrapi.Client, cr *sonarrv1alpha1.Sonarr, status *sonarrv1alpha1.SonarrStatus, drift *settingsDrift) (string, error) {
+	if len(cr.Spec.Notifications) == 0 {
+		status.Notifications = nil
+		setProviders(status, providerNotification, nil)
+		return "", nil
+	}
+
+	notifications, err := c.GetNotifications(ctx)
+	if err != nil {
+		return "", err
+	}
+	existing := map[string]sonarrapi.Notification{}
+	for _, n := range notifications {
+		existing[n.Name] = n
+	}
+
+	var connections []sonarrv1alpha1.SonarrStatusConnection
+	var providers []sonarrv1alpha1.SonarrStatusProvider
+	var invalid, failing []string
+	for _, spec := range cr.Spec.Notifications {
+		settings := notificationType(spec)
+		if settings == nil {
+			invalid = append(invalid, spec.Name)
+			continue
+		}
+		fields, secretHash, err := r.notificationFields(cr, spec, settings)
+		if err != nil {
+			return "", fmt.Errorf("notification %s: %v", spec.Name, err)
+		}
+
+		n, found := existing[spec.Name]
+		changed := !found || n.Implementation != settings.implementation
+		if n.Implementation != settings.implementation {
+			// The fields of another implementation do not apply
+			n.Fields = nil
+		}
+		n.Name = spec.Name
+		n.Implementation = settings.implementation
+		n.ConfigContract = settings.contract
+		if n.Tags == nil {
+			n.Tags = []int{}
+		}
+		if setNotificationEvents(&n, spec) {
+			changed = true
+		}
+		record := recordedProvider(status, providerNotification, spec.Name)
+		fieldsChanged, err := setFields(&n.Provider, fields, record.SecretHash != secretHash)
+		if err != nil {
+			return "", err
+		}
+		hash, err := specHash(spec, secretHash)
+		if err != nil {
+			return "", err
+		}
+
+		written := false
+		if !found && drift.found("notification %s missing", spec.Name) {
+			log.Info("Adding notification", "Namespace", cr.Namespace, "Name", cr.Name, "Notification", spec.Name)
+			created, err := c.CreateNotification(ctx, &n)
+			if err != nil {
+				return "", fmt.Errorf("add notification %s: %v", spec.Name, err)
+			}
+			n = *created
+			record.SecretHash = secretHash
+			written = true
+		} else if found && (changed || fieldsChanged) && drift.found("notification %s differs", spec.Name) {
+			log.Info("Updating notification", "Namespace", cr.Namespace, "Name", cr.Name, "Notification", spec.Name)
+			if _, err := c.UpdateNotification(ctx, &n); err != nil {
+				return "", fmt.Errorf("update notification %s: %v", spec.Name, err)
+			}
+			record.SecretHash = secretHash
+			written = true
+		} else if found && !changed && !fieldsChanged {
+			record.SecretHash = secretHash
+		}
+		if !found && !written {
+			// The spec is not enforced, so the missing notification is only reported as drift.  Testing it would send
+			// its credentials to Sonarr for a notification Sonarr does not have.
+			providers = append(providers, record)
+			continue
+		}
+
+		connection := sonarrv1alpha1.SonarrStatusConnection{Name: spec.Name, Connected: true}
+		var passed *bool
+		last := findConnection(status.Notifications, spec.Name)
+		if last != nil {
+			passed = &last.Connected
+		}
+		if !needsTest(record, hash, written, passed) {
+			connection = *last
+		} else {
+			if err := c.TestNotification(ctx, &n); err != nil {
+				if !sonarrapi.IsBadRequest(err) {
+					return "", err
+				}
+				connection.Connected = false
+				connection.Message = testMessage(err)
+			}
+			setTested(&record, hash)
+		}
+		if !connection.Connected {
+			failing = append(failing, spec.Name)
+		}
+		connections = append(connections, connection)
+		providers = append(providers, record)
+	}
+	status.Notifications = connections
+	setProviders(status, providerNotification, providers)
+
+	var problems []string
+	if len(invalid) > 0 {
+		problems = append(problems, fmt.Sprintf("Notifications not setting exactly one of webhook, discord, slack, email and plex: %s", strings.Join(invalid, ", ")))
+	}
+	if len(failing) > 0 {
+		problems = append(problems, fmt.Sprintf("Notifications failing test: %s", strings.Join(failing, ", ")))
+	}
+	return strings.Join(problems, "; "), nil
+}
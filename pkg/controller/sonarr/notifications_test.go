@@ -0,0 +1,95 @@
+package sonarr
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+
+	sonarrv1alpha1 "github.com/parflesh/sonarr-operator/pkg/apis/sonarr/v1alpha1"
+	"github.com/parflesh/sonarr-operator/pkg/sonarrapi"
+	"github.com/parflesh/sonarr-operator/pkg/sonarrapi/sonarrapitest"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestSonarrControllerNotifications(t *testing.T) {
+	cr := newTestSonarr(sonarrv1alpha1.SonarrSpec{
+		APIKeySecret: "sonarr-api-key",
+		Notifications: []sonarrv1alpha1.SonarrSpecNotification{
+			{Name: "discord", Secret: "discord", Discord: &sonarrv1alpha1.SonarrSpecNotificationDiscord{Username: "Sonarr"}},
+			{
+				Name:    "webhook",
+				Events:  []sonarrv1alpha1.SonarrNotificationEvent{sonarrv1alpha1.NotificationOnDownload, sonarrv1alpha1.NotificationOnHealthIssue},
+				Webhook: &sonarrv1alpha1.SonarrSpecNotificationWebhook{URL: "http://hooks.example.com/sonarr", Method: "PUT"},
+			},
+			{Name: "plex", Secret: "plex", Plex: &sonarrv1alpha1.SonarrSpecNotificationPlex{Host: "plex"}},
+			{Name: "invalid"},
+		},
+	})
+	r, server, req := newApplicationTest(t, cr)
+	defer server.Close()
+	server.Add(sonarrapitest.Notifications, sonarrapi.Notification{
+		Provider: sonarrapi.Provider{
+			Name:           "plex",
+			Implementation: "PlexServer",
+			ConfigContract: "PlexServerSettings",
+			Fields: []sonarrapi.Field{
+				{Name: "host", Value: "plex.example.com"},
+				{Name: "port", Value: 32400},
+				{Name: "authToken", Value: "********"},
+			},
+		},
+		OnDownload: true,
+	})
+	server.TestFailures = map[string]string{"plex": "Unable to connect to Plex Media Server"}
+	for _, secret := range []*corev1.Secret{
+		{ObjectMeta: metav1.ObjectMeta{Name: "discord", Namespace: "sonarr"}, Data: map[string][]byte{"webhookUrl": []byte("https://discord.com/api/webhooks/1/token\n")}},
+		{ObjectMeta: metav1.ObjectMeta{Name: "plex", Namespace: "sonarr"}, Data: map[string][]byte{"authToken": []byte("plex-token")}},
+	} {
+		if err := r.client.Create(context.TODO(), secret); err != nil {
+			t.Fatalf("create secret: (%v)", err)
+		}
+	}
+
+	reconcileTimes(t, r, req, 1)
+	var notifications []sonarrapi.Notification
+	server.List(sonarrapitest.Notifications, &notifications)
+	if len(notifications) != 3 {
+		t.Fatalf("unexpected notifications: %v", notifications)
+	}
+	plex, discord, webhook := notifications[0], notifications[1], notifications[2]
+	if plex.Field("host") != "plex" || plex.Field("authToken") != "plex-token" || !plex.OnGrab || !plex.OnUpgrade {
+		t.Errorf("notification not updated: %+v", plex)
+	}
+	if discord.Implementation != "Discord" || discord.ConfigContract != "DiscordSettings" || discord.Field("webHookUrl") != "https://discord.com/api/webhooks/1/token" ||
+		discord.Field("username") != "Sonarr" || !discord.OnGrab || !discord.OnDownload || !discord.OnUpgrade || discord.OnHealthIssue {
+		t.Errorf("unexpected notification: %+v", discord)
+	}
+	if webhook.Field("url") != "http://hooks.example.com/sonarr" || webhook.Field("method") != float64(2) || webhook.OnGrab || !webhook.OnDownload || !webhook.OnHealthIssue {
+		t.Errorf("unexpected notification: %+v", webhook)
+	}
+
+	cr = getSonarr(t, r, req)
+	expected := []sonarrv1alpha1.SonarrStatusConnection{
+		{Name: "discord", Connected: true},
+		{Name: "webhook", Connected: true},
+		{Name: "plex", Connected: false, Message: "Unable to connect to Plex Media Server"},
+	}
+	if !reflect.DeepEqual(cr.Status.Notifications, expected) {
+		t.Errorf("unexpected notification status: %v", cr.Status.Notifications)
+	}
+	if cr.Status.Phase != "Degraded" || !strings.Contains(cr.Status.Reason, "exactly one of webhook, discord, slack, email and plex: invalid") ||
+		!strings.Contains(cr.Status.Reason, "Notifications failing test: plex") {
+		t.Errorf("notification problems not reported: %s %s", cr.Status.Phase, cr.Status.Reason)
+	}
+
+	// Notifications matching the spec are left alone, and not tested again
+	requests := len(server.Requests())
+	reconcileTimes(t, r, req, 1)
+	for _, request := range server.Requests()[requests:] {
+		if !strings.HasPrefix(request, "GET") {
+			t.Errorf("unexpected request for unchanged notifications: %s", request)
+		}
+	}
+}
@@ -0,0 +1,358 @@
+package sonarr
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	sonarrv1alpha1 "github.com/parflesh/sonarr-operator/pkg/apis/sonarr/v1alpha1"
+	"github.com/parflesh/sonarr-operator/pkg/sonarrapi"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// sameJSON reports whether a and b encode to the same JSON document
+func sameJSON(a interface{}, b interface{}) (bool, error) {
+	aJSON, err := json.Marshal(a)
+	if err != nil {
+		return false, err
+	}
+	bJSON, err := json.Marshal(b)
+	if err != nil {
+		return false, err
+	}
+	return jsonEqual(aJSON, bJSON)
+}
+
+// sortFields orders the fields of the specifications of format by name, so formats compare equal regardless of the
+// order Sonarr returns them in
+func sortFields(format *sonarrapi.CustomFormat) {
+	for _, spec := range format.Specifications {
+		sort.Slice(spec.Fields, func(i, j int) bool { return spec.Fields[i].Name < spec.Fields[j].Name })
+	}
+}
+
+// desiredCustomFormat returns the custom format of spec, read from its ConfigMap when set
+func (r *ReconcileSonarr) desiredCustomFormat(cr *sonarrv1alpha1.Sonarr, spec sonarrv1alpha1.SonarrSpecCustomFormat) (*sonarrapi.CustomFormat, error) {
+	format := &sonarrapi.CustomFormat{}
+	if spec.ConfigMap != nil {
+		cm := &corev1.ConfigMap{}
+		err := r.client.Get(context.TODO(), client.ObjectKey{Namespace: cr.Namespace, Name: spec.ConfigMap.Name}, cm)
+		if err != nil {
+			return nil, err
+		}
+		data, ok := cm.Data[spec.ConfigMap.Key]
+		if !ok {
+			return nil, fmt.Errorf("config map %s has no %s", spec.ConfigMap.Name, spec.ConfigMap.Key)
+		}
+		if err := json.Unmarshal([]byte(data), format); err != nil {
+			return nil, fmt.Errorf("config map %s: %s: %v", spec.ConfigMap.Name, spec.ConfigMap.Key, err)
+		}
+	}
+	for _, s := range spec.Specifications {
+		var value interface{} = s.Value.StrVal
+		if s.Value.Type == intstr.Int {
+			value = s.Value.IntVal
+		}
+		format.Specifications = append(format.Specifications, sonarrapi.CustomFormatSpecification{
+			Name:           s.Name,
+			Implementation: s.Implementation,
+			Negate:         s.Negate,
+			Required:       s.Required,
+			Fields:         []sonarrapi.Field{{Name: "value", Value: value}},
+		})
+	}
+	if format.Specifications == nil {
+		format.Specifications = []sonarrapi.CustomFormatSpecification{}
+	}
+
+	format.ID = 0
+	format.Name = spec.Name
+	format.IncludeCustomFormatWhenRenaming = format.IncludeCustomFormatWhenRenaming || spec.IncludeWhenRenaming
+	sortFields(format)
+	return format, nil
+}
+
+// reconcileCustomFormats adds the custom formats of cr missing in Sonarr and updates those that differ.  Custom
+// formats not in the spec are left alone.  It returns the custom formats that could not be read, or that Sonarr
+// does not support custom formats.
+func (r *ReconcileSonarr) reconcileCustomFormats(ctx context.Context, c *sonarrapi.Client, cr *sonarrv1alpha1.Sonarr, status *sonarrv1alpha1.SonarrStatus, drift *settingsDrift) (string, error) {
+	if len(cr.Spec.CustomFormats) == 0 {
+		return "", nil
+	}
+
+	formats, err := c.GetCustomFormats(ctx)
+	if sonarrapi.IsNotFound(err) {
+		return "custom formats require Sonarr v4", nil
+	} else if err != nil {
+		return "", err
+	}
+	existing := map[string]sonarrapi.CustomFormat{}
+	for _, format := range formats {
+		sortFields(&format)
+		existing[format.Name] = format
+	}
+
+	var invalid []string
+	for _, spec := range cr.Spec.CustomFormats {
+		desired, err := r.desiredCustomFormat(cr, spec)
+		if err != nil {
+			invalid = append(invalid, fmt.Sprintf("custom format %s: %v", spec.Name, err))
+			continue
+		}
+
+		found, ok := existing[spec.Name]
+		if !ok {
+			if !drift.found("custom format %s missing", spec.Name) {
+				continue
+			}
+			log.Info("Adding custom format", "Namespace", cr.Namespace, "Name", cr.Name, "CustomFormat", spec.Name)
+			if _, err := c.CreateCustomFormat(ctx, desired); err != nil {
+				return "", fmt.Errorf("add custom format %s: %v", spec.Name, err)
+			}
+			continue
+		}
+		desired.ID = found.ID
+		if same, err := sameJSON(found, desired); err != nil {
+			return "", err
+		} else if !same && drift.found("custom format %s differs", spec.Name) {
+			log.Info("Updating custom format", "Namespace", cr.Namespace, "Name", cr.Name, "CustomFormat", spec.Name)
+			if _, err := c.UpdateCustomFormat(ctx, desired); err != nil {
+				return "", fmt.Errorf("update custom format %s: %v", spec.Name, err)
+			}
+		}
+	}
+	return strings.Join(invalid, "; "), nil
+}
+
+// qualityItemName returns the name of the quality or quality group of item
+func qualityItemName(item sonarrapi.QualityProfileItem) string {
+	if item.Quality != nil {
+		return item.Quality.Name
+	}
+	return item.Name
+}
+
+// qualityItemID returns the ID of the quality or quality group of item, as used for the cutoff
+func qualityItemID(item sonarrapi.QualityProfileItem) int {
+	if item.Quality != nil {
+		return item.Quality.ID
+	}
+	return item.ID
+}
+
+// setQualityProfile changes profile to match spec.  It looks up the custom formats scored by spec with formats.
+func setQualityProfile(profile *sonarrapi.QualityProfile, spec sonarrv1alpha1.SonarrSpecQualityProfile, formats func() ([]sonarrapi.CustomFormat, error)) error {
+	allowed := map[string]bool{}
+	for _, name := range spec.Qualities {
+		allowed[name] = true
+	}
+	cutoffName := spec.Cutoff
+	if cutoffName == "" {
+		cutoffName = spec.Qualities[0]
+	}
+
+	known := map[string]bool{}
+	cutoff := 0
+	for i := range profile.Items {
+		item := &profile.Items[i]
+		name := qualityItemName(*item)
+		known[name] = true
+		item.Allowed = allowed[name]
+		// Qualities of a group are allowed with the group
+		for j := range item.Items {
+			item.Items[j].Allowed = item.Allowed
+		}
+		if name == cutoffName {
+			cutoff = qualityItemID(*item)
+		}
+	}
+	var unknown []string
+	for _, name := range spec.Qualities {
+		if !known[name] {
+			unknown = append(unknown, name)
+		}
+	}
+	if len(unknown) > 0 {
+		return fmt.Errorf("unknown qualities %s", strings.Join(unknown, ", "))
+	}
+	if !allowed[cutoffName] {
+		return fmt.Errorf("cutoff %s is not one of the qualities", cutoffName)
+	}
+	profile.Name = spec.Name
+	profile.UpgradeAllowed = spec.UpgradeAllowed
+	profile.Cutoff = cutoff
+	profile.MinFormatScore = int(spec.MinFormatScore)
+	profile.CutoffFormatScore = int(spec.CutoffFormatScore)
+
+	if len(spec.FormatScores) == 0 {
+		for i := range profile.FormatItems {
+			profile.FormatItems[i].Score = 0
+		}
+		return nil
+	}
+	// Sonarr requires quality profiles to score every custom format
+	all, err := formats()
+	if err != nil {
+		return err
+	}
+	scores := map[int]int{}
+	byName := map[string]bool{}
+	for _, format := range all {
+		scores[format.ID] = int(spec.FormatScores[format.Name])
+		byName[format.Name] = true
+	}
+	for name := range spec.FormatScores {
+		if !byName[name] {
+			unknown = append(unknown, name)
+		}
+	}
+	if len(unknown) > 0 {
+		sort.Strings(unknown)
+		return fmt.Errorf("unknown custom formats %s", strings.Join(unknown, ", "))
+	}
+	var items []sonarrapi.ProfileFormatItem
+	for _, item := range profile.FormatItems {
+		if score, ok := scores[item.Format]; ok {
+			item.Score = score
+			items = append(items, item)
+			delete(scores, item.Format)
+		}
+	}
+	for _, format := range all {
+		if _, ok := scores[format.ID]; ok {
+			items = append(items, sonarrapi.ProfileFormatItem{Format: format.ID, Name: format.Name, Score: scores[format.ID]})
+		}
+	}
+	profile.FormatItems = items
+	return nil
+}
+
+// reconcileQualityProfiles adds the quality profiles of cr missing in Sonarr, based on the quality profile schema,
+// and updates those that differ.  Quality profiles not in the spec are left alone.  It returns the quality profiles
+// that do not match the qualities and custom formats known to Sonarr.
+func (r *ReconcileSonarr) reconcileQualityProfiles(ctx context.Context, c *sonarrapi.Client, cr *sonarrv1alpha1.Sonarr, status *sonarrv1alpha1.SonarrStatus, drift *settingsDrift) (string, error) {
+	if len(cr.Spec.QualityProfiles) == 0 {
+		return "", nil
+	}
+
+	profiles, err := c.GetQualityProfiles(ctx)
+	if err != nil {
+		return "", err
+	}
+	existing := map[string]sonarrapi.QualityProfile{}
+	for _, profile := range profiles {
+		existing[profile.Name] = profile
+	}
+	var formats []sonarrapi.CustomFormat
+	var formatsErr error
+	getFormats := func() ([]sonarrapi.CustomFormat, error) {
+		if formats == nil && formatsErr == nil {
+			formats, formatsErr = c.GetCustomFormats(ctx)
+		}
+		if sonarrapi.IsNotFound(formatsErr) {
+			return nil, fmt.Errorf("custom formats require Sonarr v4")
+		}
+		return formats, formatsErr
+	}
+
+	var invalid []string
+	for _, spec := range cr.Spec.QualityProfiles {
+		found, ok := existing[spec.Name]
+		base := &found
+		if !ok {
+			if base, err = c.GetQualityProfileSchema(ctx); err != nil {
+				return "", err
+			}
+		}
+		// Copy the profile through JSON, so changes to items do not show in found
+		desired := &sonarrapi.QualityProfile{}
+		data, err := json.Marshal(base)
+		if err != nil {
+			return "", err
+		}
+		if err := json.Unmarshal(data, desired); err != nil {
+			return "", err
+		}
+		if err := setQualityProfile(desired, spec, getFormats); err != nil {
+			if formatsErr != nil && !sonarrapi.IsNotFound(formatsErr) {
+				return "", formatsErr
+			}
+			invalid = append(invalid, fmt.Sprintf("quality profile %s: %v", spec.Name, err))
+			continue
+		}
+
+		if !ok {
+			if !drift.found("quality profile %s missing", spec.Name) {
+				continue
+			}
+			log.Info("Adding quality profile", "Namespace", cr.Namespace, "Name", cr.Name, "QualityProfile", spec.Name)
+			desired.ID = 0
+			if _, err := c.CreateQualityProfile(ctx, desired); err != nil {
+				return "", fmt.Errorf("add quality profile %s: %v", spec.Name, err)
+			}
+			continue
+		}
+		if same, err := sameJSON(found, desired); err != nil {
+			return "", err
+		} else if !same && drift.found("quality profile %s differs", spec.Name) {
+			log.Info("Updating quality profile", "Namespace", cr.Namespace, "Name", cr.Name, "QualityProfile", spec.Name)
+			if _, err := c.UpdateQualityProfile(ctx, desired); err != nil {
+				return "", fmt.Errorf("update quality profile %s: %v", spec.Name, err)
+			}
+		}
+	}
+	return strings.Join(invalid, "; "), nil
+}
+
+// reconcileReleaseProfiles adds the release profiles of cr missing in Sonarr and updates those that differ.  Release
+// profiles not in the spec are left alone.
+func (r *ReconcileSonarr) reconcileReleaseProfiles(ctx context.Context, c *sonarrapi.Client, cr *sonarrv1alpha1.Sonarr, status *sonarrv1alpha1.SonarrStatus, drift *settingsDrift) (string, error) {
+	if len(cr.Spec.ReleaseProfiles) == 0 {
+		return "", nil
+	}
+
+	profiles, err := c.GetReleaseProfiles(ctx)
+	if err != nil {
+		return "", err
+	}
+	existing := map[string]sonarrapi.ReleaseProfile{}
+	for _, profile := range profiles {
+		existing[profile.Name] = profile
+	}
+
+	for _, spec := range cr.Spec.ReleaseProfiles {
+		found, ok := existing[spec.Name]
+		desired := found
+		desired.Name = spec.Name
+		desired.Enabled = !spec.Disabled
+		desired.Required = append([]string{}, spec.Required...)
+		desired.Ignored = append([]string{}, spec.Ignored...)
+		if desired.Tags == nil {
+			desired.Tags = []int{}
+		}
+
+		if !ok {
+			if !drift.found("release profile %s missing", spec.Name) {
+				continue
+			}
+			log.Info("Adding release profile", "Namespace", cr.Namespace, "Name", cr.Name, "ReleaseProfile", spec.Name)
+			if _, err := c.CreateReleaseProfile(ctx, &desired); err != nil {
+				return "", fmt.Errorf("add release profile %s: %v", spec.Name, err)
+			}
+			continue
+		}
+		if same, err := sameJSON(found, desired); err != nil {
+			return "", err
+		} else if !same && drift.found("release profile %s differs", spec.Name) {
+			log.Info("Updating release profile", "Namespace", cr.Namespace, "Name", cr.Name, "ReleaseProfile", spec.Name)
+			if _, err := c.UpdateReleaseProfile(ctx, &desired); err != nil {
+				return "", fmt.Errorf("update release profile %s: %v", spec.Name, err)
+			}
+		}
+	}
+	return "", nil
+}
@@ -0,0 +1,122 @@
+package sonarr
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+
+	sonarrv1alpha1 "github.com/parflesh/sonarr-operator/pkg/apis/sonarr/v1alpha1"
+	"github.com/parflesh/sonarr-operator/pkg/sonarrapi"
+	"github.com/parflesh/sonarr-operator/pkg/sonarrapi/sonarrapitest"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+)
+
+func TestSonarrControllerProfiles(t *testing.T) {
+	cr := newTestSonarr(sonarrv1alpha1.SonarrSpec{
+		APIKeySecret: "sonarr-api-key",
+		CustomFormats: []sonarrv1alpha1.SonarrSpecCustomFormat{
+			{
+				Name: "x265",
+				Specifications: []sonarrv1alpha1.SonarrSpecCustomFormatSpecification{
+					{Name: "x265", Implementation: "ReleaseTitleSpecification", Value: intstr.FromString(`[xh]\.?265`)},
+					{Name: "Not 2160p", Implementation: "ResolutionSpecification", Negate: true, Value: intstr.FromInt(2160)},
+				},
+			},
+			{Name: "HDR", ConfigMap: &sonarrv1alpha1.SonarrSpecConfigMapKey{Name: "custom-formats", Key: "hdr.json"}},
+		},
+		QualityProfiles: []sonarrv1alpha1.SonarrSpecQualityProfile{
+			{Name: "HD", Qualities: []string{"HDTV-720p", "WEB 1080p"}, Cutoff: "WEB 1080p", UpgradeAllowed: true, FormatScores: map[string]int32{"x265": -10000, "HDR": 100}},
+			{Name: "Broken", Qualities: []string{"HDTV-720p", "Bluray-2160p"}},
+		},
+		ReleaseProfiles: []sonarrv1alpha1.SonarrSpecReleaseProfile{
+			{Name: "No hardcoded subs", Ignored: []string{"hc", "korsub"}},
+		},
+	})
+	r, server, req := newApplicationTest(t, cr)
+	defer server.Close()
+	cm := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: "custom-formats", Namespace: "sonarr"},
+		Data: map[string]string{"hdr.json": `{
+			"trash_id": "3a3ff47579026e76d6504ebea39390de",
+			"name": "HDR Formats",
+			"includeCustomFormatWhenRenaming": false,
+			"specifications": [
+				{"name": "HDR", "implementation": "ReleaseTitleSpecification", "negate": false, "required": true, "fields": {"value": "\\bHDR(\\b|\\d)"}}
+			]
+		}`},
+	}
+	if err := r.client.Create(context.TODO(), cm); err != nil {
+		t.Fatalf("create config map: (%v)", err)
+	}
+
+	reconcileTimes(t, r, req, 1)
+	var formats []sonarrapi.CustomFormat
+	server.List(sonarrapitest.CustomFormats, &formats)
+	if len(formats) != 2 || formats[0].Name != "x265" || formats[1].Name != "HDR" {
+		t.Fatalf("unexpected custom formats: %v", formats)
+	}
+	if len(formats[0].Specifications) != 2 || formats[0].Specifications[1].Fields[0].Value != float64(2160) || !formats[0].Specifications[1].Negate {
+		t.Errorf("unexpected custom format: %v", formats[0])
+	}
+	if formats[1].Specifications[0].Fields[0].Value != `\bHDR(\b|\d)` || !formats[1].Specifications[0].Required {
+		t.Errorf("custom format not read from config map: %v", formats[1])
+	}
+
+	var profiles []sonarrapi.QualityProfile
+	server.List(sonarrapitest.QualityProfiles, &profiles)
+	if len(profiles) != 1 {
+		t.Fatalf("unexpected quality profiles: %v", profiles)
+	}
+	profile := profiles[0]
+	var allowed []string
+	for _, item := range profile.Items {
+		if item.Allowed {
+			allowed = append(allowed, qualityItemName(item))
+		}
+	}
+	if profile.Name != "HD" || !profile.UpgradeAllowed || profile.Cutoff != 1000 || !reflect.DeepEqual(allowed, []string{"HDTV-720p", "WEB 1080p"}) || !profile.Items[3].Items[1].Allowed {
+		t.Errorf("unexpected quality profile: %v", profile)
+	}
+	expectedScores := []sonarrapi.ProfileFormatItem{
+		{Format: formats[0].ID, Name: "x265", Score: -10000},
+		{Format: formats[1].ID, Name: "HDR", Score: 100},
+	}
+	if !reflect.DeepEqual(profile.FormatItems, expectedScores) {
+		t.Errorf("unexpected custom format scores: %v", profile.FormatItems)
+	}
+
+	var releaseProfiles []sonarrapi.ReleaseProfile
+	server.List(sonarrapitest.ReleaseProfiles, &releaseProfiles)
+	if len(releaseProfiles) != 1 || !releaseProfiles[0].Enabled || !reflect.DeepEqual(releaseProfiles[0].Ignored, []string{"hc", "korsub"}) {
+		t.Errorf("unexpected release profiles: %v", releaseProfiles)
+	}
+
+	cr = getSonarr(t, r, req)
+	if cr.Status.Phase != "Degraded" || cr.Status.Reason != "quality profile Broken: unknown qualities Bluray-2160p" {
+		t.Errorf("invalid quality profile not reported: %s %s", cr.Status.Phase, cr.Status.Reason)
+	}
+
+	// Profiles matching the spec are left alone
+	requests := len(server.Requests())
+	reconcileTimes(t, r, req, 1)
+	for _, request := range server.Requests()[requests:] {
+		if !strings.HasPrefix(request, "GET") {
+			t.Errorf("unexpected request for unchanged profiles: %s", request)
+		}
+	}
+
+	// Changed scores and cutoffs are converged
+	cr.Spec.QualityProfiles = cr.Spec.QualityProfiles[:1]
+	cr.Spec.QualityProfiles[0].Cutoff = ""
+	cr.Spec.QualityProfiles[0].FormatScores = map[string]int32{"HDR": 50}
+	updateSonarr(t, r, cr)
+	reconcileTimes(t, r, req, 1)
+	profiles = nil
+	server.List(sonarrapitest.QualityProfiles, &profiles)
+	if profiles[0].Cutoff != 4 || profiles[0].FormatItems[0].Score != 0 || profiles[0].FormatItems[1].Score != 50 {
+		t.Errorf("quality profile not updated: %v", profiles[0])
+	}
+}
@@ -0,0 +1,166 @@
+package sonarr
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"time"
+
+	sonarrv1alpha1 "github.com/parflesh/sonarr-operator/pkg/apis/sonarr/v1alpha1"
+	"github.com/parflesh/sonarr-operator/pkg/sonarrapi"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// maskedValue is returned by Sonarr in place of passwords and API keys, which are then not compared
+const maskedValue = "********"
+
+// providerRetestInterval is how long Sonarr waits before testing a provider that failed its test again, when its
+// settings did not change
+var providerRetestInterval = 15 * time.Minute
+
+// Kinds of providers recorded in the status
+const (
+	providerDownloadClient = "DownloadClient"
+	providerIndexer        = "Indexer"
+	providerNotification   = "Notification"
+)
+
+// secretData returns the data of the secret name in the namespace of cr and a hash of the data keyed by the uid of
+// the secret, or nils when name is empty.  The hash tells changed data apart without revealing it in the status.
+func (r *ReconcileSonarr) secretData(cr *sonarrv1alpha1.Sonarr, name string) (map[string][]byte, string, error) {
+	if name == "" {
+		return nil, "", nil
+	}
+	secret := &corev1.Secret{}
+	err := r.client.Get(context.TODO(), client.ObjectKey{Namespace: cr.Namespace, Name: name}, secret)
+	if err != nil {
+		return nil, "", err
+	}
+
+	keys := make([]string, 0, len(secret.Data))
+	for key := range secret.Data {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	mac := hmac.New(sha256.New, []byte(secret.UID))
+	for _, key := range keys {
+		fmt.Fprintf(mac, "%s=%x;", key, secret.Data[key])
+	}
+	return secret.Data, hex.EncodeToString(mac.Sum(nil)), nil
+}
+
+// recordedProvider returns the provider kind name recorded in status, or a new record when there is none
+func recordedProvider(status *sonarrv1alpha1.SonarrStatus, kind string, name string) sonarrv1alpha1.SonarrStatusProvider {
+	for _, p := range status.Providers {
+		if p.Kind == kind && p.Name == name {
+			return p
+		}
+	}
+	return sonarrv1alpha1.SonarrStatusProvider{Kind: kind, Name: name}
+}
+
+// specHash returns a hash of spec and secretHash, which changes with the settings of a provider
+func specHash(spec interface{}, secretHash string) (string, error) {
+	data, err := json.Marshal(spec)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(append(data, secretHash...))
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// needsTest reports whether Sonarr tests the provider recorded as p, with the settings hashed as hash.  Providers
+// are tested after they are written to Sonarr, when their settings changed since the last test or no result is
+// known, and again providerRetestInterval after a failed test.  Otherwise the last result stands.
+func needsTest(p sonarrv1alpha1.SonarrStatusProvider, hash string, written bool, passed *bool) bool {
+	switch {
+	case written || passed == nil || p.TestTime == nil || p.TestedHash != hash:
+		return true
+	case !*passed:
+		return time.Since(p.TestTime.Time) >= providerRetestInterval
+	}
+	return false
+}
+
+// setTested records in p that Sonarr tested the provider with the settings hashed as hash
+func setTested(p *sonarrv1alpha1.SonarrStatusProvider, hash string) {
+	now := metav1.Now()
+	p.TestedHash = hash
+	p.TestTime = &now
+}
+
+// findConnection returns the connection name of connections, or nil when there is none
+func findConnection(connections []sonarrv1alpha1.SonarrStatusConnection, name string) *sonarrv1alpha1.SonarrStatusConnection {
+	for i := range connections {
+		if connections[i].Name == name {
+			return &connections[i]
+		}
+	}
+	return nil
+}
+
+// setProviders replaces the providers of kind in status with providers
+func setProviders(status *sonarrv1alpha1.SonarrStatus, kind string, providers []sonarrv1alpha1.SonarrStatusProvider) {
+	var kept []sonarrv1alpha1.SonarrStatusProvider
+	for _, p := range status.Providers {
+		if p.Kind != kind {
+			kept = append(kept, p)
+		}
+	}
+	status.Providers = append(kept, providers...)
+}
+
+// setFields sets the fields of p to the values in fields and reports whether any changed.  Values are compared as
+// JSON, since fields read from Sonarr hold decoded JSON values.  Masked values are only set with resendSecrets.
+func setFields(p *sonarrapi.Provider, fields map[string]interface{}, resendSecrets bool) (bool, error) {
+	names := make([]string, 0, len(fields))
+	for name := range fields {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	changed := false
+	for _, name := range names {
+		current := p.Field(name)
+		if current == maskedValue {
+			if resendSecrets {
+				p.SetField(name, fields[name])
+				changed = true
+			}
+			continue
+		}
+		currentJSON, err := json.Marshal(current)
+		if err != nil {
+			return false, err
+		}
+		valueJSON, err := json.Marshal(fields[name])
+		if err != nil {
+			return false, err
+		}
+		if equal, err := jsonEqual(currentJSON, valueJSON); err != nil {
+			return false, err
+		} else if !equal {
+			p.SetField(name, fields[name])
+			changed = true
+		}
+	}
+	return changed, nil
+}
+
+// testMessage returns why a connection test failed with err
+func testMessage(err error) string {
+	if apiErr, ok := err.(*sonarrapi.APIError); ok && len(apiErr.Failures) > 0 {
+		message := apiErr.Failures[0].ErrorMessage
+		for _, f := range apiErr.Failures[1:] {
+			message += "; " + f.ErrorMessage
+		}
+		return message
+	}
+	return err.Error()
+}
@@ -0,0 +1,281 @@
+package sonarr
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/parflesh/sonarr-operator/defaults"
+	sonarrv1alpha1 "github.com/parflesh/sonarr-operator/pkg/apis/sonarr/v1alpha1"
+	appsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+// restoreScript replaces the Sonarr database and settings with the content of a Sonarr backup zip, or of the newest
+// Sonarr backup zip in a scheduled backup tar.gz.  Zips are extracted with python as the default image has no unzip,
+// ubi8 ships it as platform-python.
+const restoreScript = `set -e
+python=/usr/libexec/platform-python
+test -x "$python" || python=python3
+archive="/restore/${RESTORE_PATH#/}"
+test -f "$archive" || { echo "backup archive $archive not found"; exit 1; }
+case "$archive" in
+  *.zip) ;;
+  *.tar.gz|*.tgz)
+    dir=$(mktemp -d)
+    tar -xzf "$archive" -C "$dir"
+    archive=$(ls -1t "$dir"/Backups/*/*.zip 2>/dev/null | head -n 1)
+    test -n "$archive" || { echo "no Sonarr backup in $RESTORE_PATH"; exit 1; } ;;
+  *) echo "unsupported backup archive $archive"; exit 1 ;;
+esac
+rm -f /config/sonarr.db-shm /config/sonarr.db-wal /config/sonarr.db-journal
+"$python" -m zipfile -e "$archive" /config
+`
+
+// restorePollInterval is how often a running restore is checked
+var restorePollInterval = 10 * time.Second
+
+// restoreReplicasKey holds the replicas of a Deployment scaled down for a restore, which it is scaled back to
+const restoreReplicasKey = "sonarr.parflesh.github.io/restore-replicas"
+
+// scaleForRestore scales dep to zero while restoring, Sonarr must not be running while its database is replaced,
+// and back to its former replicas once done.  It reports whether dep changed.
+func scaleForRestore(dep *appsv1.Deployment, restoring bool) bool {
+	saved, scaled := dep.Annotations[restoreReplicasKey]
+	if restoring {
+		if dep.Spec.Replicas != nil && *dep.Spec.Replicas == 0 {
+			return false
+		}
+		if !scaled {
+			replicas := int32(1)
+			if dep.Spec.Replicas != nil {
+				replicas = *dep.Spec.Replicas
+			}
+			if dep.Annotations == nil {
+				dep.Annotations = map[string]string{}
+			}
+			dep.Annotations[restoreReplicasKey] = strconv.Itoa(int(replicas))
+		}
+		dep.Spec.Replicas = &[]int32{0}[0]
+		return true
+	}
+
+	if !scaled {
+		return false
+	}
+	replicas, err := strconv.Atoi(saved)
+	if err != nil || replicas < 0 {
+		replicas = 1
+	}
+	delete(dep.Annotations, restoreReplicasKey)
+	dep.Spec.Replicas = &[]int32{int32(replicas)}[0]
+	return true
+}
+
+func (r *ReconcileSonarr) restoreName(cr *sonarrv1alpha1.Sonarr) string {
+	return cr.Name + "-restore"
+}
+
+// restoreLabels are set on restore jobs and pods.  They must not match labelsForCR, which selects the Sonarr pods.
+func (r *ReconcileSonarr) restoreLabels(cr *sonarrv1alpha1.Sonarr) map[string]string {
+	return map[string]string{
+		"sonarr-restore": cr.Name,
+	}
+}
+
+// restoreRequested reports whether the restore in the spec of cr has not finished yet
+func (r *ReconcileSonarr) restoreRequested(cr *sonarrv1alpha1.Sonarr) bool {
+	restore := cr.Spec.Restore
+	if restore == nil {
+		return false
+	}
+	done := cr.Status.Restore
+	if done == nil || done.Claim != restore.Claim || done.Path != restore.Path {
+		return true
+	}
+	return done.Phase != sonarrv1alpha1.RestoreSucceeded && done.Phase != sonarrv1alpha1.RestoreFailed
+}
+
+func (r *ReconcileSonarr) newRestoreJob(cr *sonarrv1alpha1.Sonarr) (*batchv1.Job, error) {
+	labels := r.restoreLabels(cr)
+
+	config, err := r.configVolume(cr)
+	if err != nil {
+		return &batchv1.Job{}, err
+	}
+
+	image := cr.Spec.Restore.Image
+	if image == "" {
+		image = defaults.BackupImage
+	}
+
+	var imagePullSecrets []corev1.LocalObjectReference
+	for _, s := range cr.Spec.ImagePullSecrets {
+		imagePullSecrets = append(imagePullSecrets, corev1.LocalObjectReference{Name: s})
+	}
+
+	job := &batchv1.Job{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      r.restoreName(cr),
+			Namespace: cr.Namespace,
+			Labels:    labels,
+		},
+		Spec: batchv1.JobSpec{
+			BackoffLimit: &[]int32{2}[0],
+			Template: corev1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{
+					Labels: labels,
+				},
+				Spec: corev1.PodSpec{
+					Volumes: []corev1.Volume{
+						{
+							Name: "config",
+							VolumeSource: corev1.VolumeSource{
+								PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{
+									ClaimName: config.Claim,
+								},
+							},
+						},
+						{
+							Name: "restore",
+							VolumeSource: corev1.VolumeSource{
+								PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{
+									ClaimName: cr.Spec.Restore.Claim,
+									ReadOnly:  true,
+								},
+							},
+						},
+					},
+					Containers: []corev1.Container{
+						{
+							Name:    "restore",
+							Image:   image,
+							Command: []string{"/bin/sh", "-c", restoreScript},
+							Env: []corev1.EnvVar{
+								{
+									Name:  "RESTORE_PATH",
+									Value: cr.Spec.Restore.Path,
+								},
+							},
+							VolumeMounts: []corev1.VolumeMount{
+								{
+									Name:      "config",
+									MountPath: "/config",
+									SubPath:   config.SubPath,
+								},
+								{
+									Name:      "restore",
+									MountPath: "/restore",
+									ReadOnly:  true,
+								},
+							},
+							ImagePullPolicy: corev1.PullIfNotPresent,
+						},
+					},
+					RestartPolicy:     corev1.RestartPolicyOnFailure,
+					SecurityContext:   r.podSecurityContext(cr),
+					ImagePullSecrets:  imagePullSecrets,
+					PriorityClassName: cr.Spec.PriorityClassName,
+					// Restores run on the nodes Sonarr may run on, which reach its configuration volume
+					NodeSelector: cr.Spec.NodeSelector,
+					Affinity:     cr.Spec.Affinity.DeepCopy(),
+					Tolerations:  cr.Spec.Tolerations,
+				},
+			},
+		},
+	}
+
+	err = controllerutil.SetControllerReference(cr, job, r.scheme)
+	if err != nil {
+		return job, err
+	}
+	return job, nil
+}
+
+// reconcileRestore moves the restore requested by cr forward once the Deployment has been scaled to zero: it
+// starts the restore job, waits for it and records its result.  The job is removed once finished, after which
+// the Deployment is scaled back up on the next reconcile.
+func (r *ReconcileSonarr) reconcileRestore(cr *sonarrv1alpha1.Sonarr, dep *appsv1.Deployment, status *sonarrv1alpha1.SonarrStatus) (reconcile.Result, error) {
+	restore := status.Restore
+	if restore == nil || restore.Claim != cr.Spec.Restore.Claim || restore.Path != cr.Spec.Restore.Path {
+		restore = &sonarrv1alpha1.SonarrStatusRestore{
+			Claim:     cr.Spec.Restore.Claim,
+			Path:      cr.Spec.Restore.Path,
+			Phase:     sonarrv1alpha1.RestoreScalingDown,
+			StartTime: &metav1.Time{Time: time.Now()},
+		}
+		status.Restore = restore
+	}
+
+	if dep.Status.Replicas > 0 {
+		restore.Phase = sonarrv1alpha1.RestoreScalingDown
+		status.Phase = "Restoring"
+		status.Reason = "Waiting for Sonarr to stop"
+		return reconcile.Result{RequeueAfter: restorePollInterval}, nil
+	}
+
+	found := &batchv1.Job{}
+	err := r.client.Get(context.TODO(), client.ObjectKey{Namespace: cr.Namespace, Name: r.restoreName(cr)}, found)
+	if err != nil && errors.IsNotFound(err) {
+		job, err := r.newRestoreJob(cr)
+		if err != nil {
+			restore.Phase = sonarrv1alpha1.RestoreFailed
+			restore.Message = err.Error()
+			restore.CompletionTime = &metav1.Time{Time: time.Now()}
+			return reconcile.Result{Requeue: true}, nil
+		}
+		if err := r.client.Create(context.TODO(), job); err != nil {
+			return reconcile.Result{}, err
+		}
+		restore.Phase = sonarrv1alpha1.RestoreRunning
+		status.Phase = "Restoring"
+		status.Reason = "Created restore job"
+		return reconcile.Result{RequeueAfter: restorePollInterval}, nil
+	} else if err != nil {
+		return reconcile.Result{}, err
+	}
+
+	switch {
+	case found.Status.Succeeded > 0:
+		restore.Phase = sonarrv1alpha1.RestoreSucceeded
+		restore.Message = ""
+	case jobFailed(found):
+		restore.Phase = sonarrv1alpha1.RestoreFailed
+		restore.Message = "Restore job failed"
+		for _, c := range found.Status.Conditions {
+			if c.Type == batchv1.JobFailed && c.Message != "" {
+				restore.Message = fmt.Sprintf("Restore job failed: %s", c.Message)
+			}
+		}
+	default:
+		restore.Phase = sonarrv1alpha1.RestoreRunning
+		status.Phase = "Restoring"
+		status.Reason = fmt.Sprintf("Restoring %s from %s", cr.Spec.Restore.Path, cr.Spec.Restore.Claim)
+		return reconcile.Result{RequeueAfter: restorePollInterval}, nil
+	}
+
+	restore.CompletionTime = &metav1.Time{Time: time.Now()}
+	background := metav1.DeletePropagationBackground
+	err = r.client.Delete(context.TODO(), found, &client.DeleteOptions{PropagationPolicy: &background})
+	if err != nil && !errors.IsNotFound(err) {
+		return reconcile.Result{}, err
+	}
+	return reconcile.Result{Requeue: true}, nil
+}
+
+// jobFailed reports whether job has given up
+func jobFailed(job *batchv1.Job) bool {
+	for _, c := range job.Status.Conditions {
+		if c.Type == batchv1.JobFailed && c.Status == corev1.ConditionTrue {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,228 @@
+package sonarr
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	sonarrv1alpha1 "github.com/parflesh/sonarr-operator/pkg/apis/sonarr/v1alpha1"
+	batchv1 "k8s.io/api/batch/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+func TestSonarrControllerRestore(t *testing.T) {
+	cr := newTestSonarr(sonarrv1alpha1.SonarrSpec{
+		Volumes: []sonarrv1alpha1.SonarrSpecVolume{
+			{Name: "config", Claim: "sonarr-config", MountPath: "/config"},
+		},
+	})
+
+	r, req := newTestReconciler(cr)
+	jobKey := types.NamespacedName{Name: testName + "-restore", Namespace: testNamespace}
+
+	reconcileTimes(t, r, req, 3)
+	dep := getDeployment(t, r, req)
+	if *dep.Spec.Replicas != 1 {
+		t.Fatalf("unexpected replicas: %d", *dep.Spec.Replicas)
+	}
+
+	// Scaling by others is kept
+	dep.Spec.Replicas = &[]int32{2}[0]
+	if err := r.client.Update(context.TODO(), dep); err != nil {
+		t.Fatalf("scale deployment: (%v)", err)
+	}
+	dep.Status.Replicas = 2
+	if err := r.client.Status().Update(context.TODO(), dep); err != nil {
+		t.Fatalf("update deployment status: (%v)", err)
+	}
+	reconcileTimes(t, r, req, 1)
+	dep = getDeployment(t, r, req)
+	if *dep.Spec.Replicas != 2 {
+		t.Fatalf("deployment scale reverted: %d", *dep.Spec.Replicas)
+	}
+
+	cr = getSonarr(t, r, req)
+	cr.Spec.Restore = &sonarrv1alpha1.SonarrSpecRestore{Claim: "sonarr-backup", Path: "sonarr_backup_v3.0.zip"}
+	updateSonarr(t, r, cr)
+
+	// Sonarr is stopped first
+	reconcileTimes(t, r, req, 1)
+	dep = getDeployment(t, r, req)
+	if *dep.Spec.Replicas != 0 {
+		t.Fatalf("deployment not scaled down: %d", *dep.Spec.Replicas)
+	}
+	reconcileTimes(t, r, req, 1)
+	job := &batchv1.Job{}
+	if err := r.client.Get(context.TODO(), jobKey, job); err == nil {
+		t.Fatal("restore job created while sonarr is running")
+	}
+	cr = getSonarr(t, r, req)
+	if cr.Status.Restore == nil || cr.Status.Restore.Phase != sonarrv1alpha1.RestoreScalingDown {
+		t.Errorf("restore not waiting for scale down: %v", cr.Status.Restore)
+	}
+
+	// The restore job runs once the pods are gone
+	dep.Status.Replicas = 0
+	if err := r.client.Status().Update(context.TODO(), dep); err != nil {
+		t.Fatalf("update deployment status: (%v)", err)
+	}
+	reconcileTimes(t, r, req, 1)
+	if err := r.client.Get(context.TODO(), jobKey, job); err != nil {
+		t.Fatalf("restore job not created: (%v)", err)
+	}
+	pod := job.Spec.Template.Spec
+	if pod.Volumes[0].PersistentVolumeClaim.ClaimName != "sonarr-config" || pod.Volumes[1].PersistentVolumeClaim.ClaimName != "sonarr-backup" {
+		t.Errorf("unexpected restore volumes: %v", pod.Volumes)
+	}
+	if pod.Containers[0].Env[0].Value != "sonarr_backup_v3.0.zip" {
+		t.Errorf("unexpected restore path: %v", pod.Containers[0].Env)
+	}
+
+	// Sonarr is scaled back up after the job succeeds
+	job.Status.Succeeded = 1
+	if err := r.client.Status().Update(context.TODO(), job); err != nil {
+		t.Fatalf("update job status: (%v)", err)
+	}
+	reconcileTimes(t, r, req, 2)
+	if err := r.client.Get(context.TODO(), jobKey, &batchv1.Job{}); err == nil {
+		t.Error("finished restore job not deleted")
+	}
+	dep = getDeployment(t, r, req)
+	if *dep.Spec.Replicas != 2 {
+		t.Errorf("deployment not scaled back up: %d", *dep.Spec.Replicas)
+	}
+	cr = getSonarr(t, r, req)
+	if cr.Status.Restore.Phase != sonarrv1alpha1.RestoreSucceeded || cr.Status.Restore.CompletionTime == nil {
+		t.Errorf("restore result not recorded: %v", cr.Status.Restore)
+	}
+}
+
+// defaultImageCommands are the commands run by restoreScript that ship with defaults.BackupImage, python3 standing in
+// for its platform-python
+var defaultImageCommands = []string{"sh", "ls", "head", "mktemp", "rm", "tar", "gzip", "python3"}
+
+// commandsBin returns a directory holding only commands, to be the PATH of scripts run by tests.  It skips the test
+// when a command is not available.
+func commandsBin(t *testing.T, commands []string) string {
+	t.Helper()
+	bin, err := ioutil.TempDir("", "commands-bin")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, command := range commands {
+		path, err := exec.LookPath(command)
+		if err != nil {
+			os.RemoveAll(bin)
+			t.Skipf("%s not available: (%v)", command, err)
+		}
+		if command == "python3" {
+			// Link the interpreter rather than any wrapper script in front of it
+			out, err := exec.Command(path, "-c", "import sys; print(sys.executable)").Output()
+			if err != nil {
+				os.RemoveAll(bin)
+				t.Skipf("%s not available: (%v)", command, err)
+			}
+			path = strings.TrimSpace(string(out))
+		}
+		if err := os.Symlink(path, filepath.Join(bin, command)); err != nil {
+			os.RemoveAll(bin)
+			t.Fatal(err)
+		}
+	}
+	return bin
+}
+
+func TestRestoreScript(t *testing.T) {
+	bin := commandsBin(t, defaultImageCommands)
+	defer os.RemoveAll(bin)
+
+	sonarrBackup := func(db string) []byte {
+		buf := &bytes.Buffer{}
+		w := zip.NewWriter(buf)
+		for name, content := range map[string]string{"sonarr.db": db, "config.xml": "<Config></Config>"} {
+			f, err := w.Create(name)
+			if err != nil {
+				t.Fatal(err)
+			}
+			_, _ = f.Write([]byte(content))
+		}
+		if err := w.Close(); err != nil {
+			t.Fatal(err)
+		}
+		return buf.Bytes()
+	}
+	scheduledBackup := func(files map[string][]byte) []byte {
+		buf := &bytes.Buffer{}
+		gz := gzip.NewWriter(buf)
+		w := tar.NewWriter(gz)
+		modTime := time.Date(2020, 3, 1, 4, 0, 0, 0, time.UTC)
+		for _, name := range []string{"Backups/scheduled/old.zip", "Backups/scheduled/new.zip"} {
+			if err := w.WriteHeader(&tar.Header{Name: name, Mode: 0644, Size: int64(len(files[name])), ModTime: modTime}); err != nil {
+				t.Fatal(err)
+			}
+			_, _ = w.Write(files[name])
+			modTime = modTime.Add(time.Hour)
+		}
+		if err := w.Close(); err != nil {
+			t.Fatal(err)
+		}
+		if err := gz.Close(); err != nil {
+			t.Fatal(err)
+		}
+		return buf.Bytes()
+	}
+
+	tests := []struct {
+		name    string
+		archive []byte
+	}{
+		{"sonarr_backup.zip", sonarrBackup("restored")},
+		{"sonarr-20200301040000.tar.gz", scheduledBackup(map[string][]byte{
+			"Backups/scheduled/old.zip": sonarrBackup("old"),
+			"Backups/scheduled/new.zip": sonarrBackup("restored"),
+		})},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir, err := ioutil.TempDir("", "restore")
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer os.RemoveAll(dir)
+			for _, d := range []string{"config", "restore"} {
+				if err := os.Mkdir(filepath.Join(dir, d), 0755); err != nil {
+					t.Fatal(err)
+				}
+			}
+			if err := ioutil.WriteFile(filepath.Join(dir, "restore", tt.name), tt.archive, 0644); err != nil {
+				t.Fatal(err)
+			}
+			if err := ioutil.WriteFile(filepath.Join(dir, "config", "sonarr.db-wal"), []byte("stale"), 0644); err != nil {
+				t.Fatal(err)
+			}
+
+			script := strings.NewReplacer("/restore/", dir+"/restore/", "/config", dir+"/config").Replace(restoreScript)
+			cmd := exec.Command(filepath.Join(bin, "sh"), "-c", script)
+			cmd.Env = []string{"PATH=" + bin, "RESTORE_PATH=" + tt.name}
+			if out, err := cmd.CombinedOutput(); err != nil {
+				t.Fatalf("restore script failed: (%v) %s", err, out)
+			}
+			db, err := ioutil.ReadFile(filepath.Join(dir, "config", "sonarr.db"))
+			if err != nil || string(db) != "restored" {
+				t.Errorf("database not restored: %s (%v)", db, err)
+			}
+			if _, err := os.Stat(filepath.Join(dir, "config", "sonarr.db-wal")); !os.IsNotExist(err) {
+				t.Errorf("stale write-ahead log kept: (%v)", err)
+			}
+		})
+	}
+}
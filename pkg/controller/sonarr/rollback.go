@@ -0,0 +1,79 @@
+package sonarr
+
+import (
+	"fmt"
+
+	sonarrv1alpha1 "github.com/parflesh/sonarr-operator/pkg/apis/sonarr/v1alpha1"
+	"github.com/parflesh/sonarr-operator/pkg/image_inspect"
+	appsv1 "k8s.io/api/apps/v1"
+)
+
+// maxBlockedImages is the number of failed images remembered, the oldest are forgotten first
+const maxBlockedImages = 10
+
+// rollbackImage remembers the last image that became available and blocks images failing to become available
+// after an update.  It returns the image to roll out in place of image, and whether this rolls back the running
+// image: a blocked image is replaced by the last good image.  An image pinned by digest in the spec is what the
+// user asked for and is never blocked or rolled back.
+func (r *ReconcileSonarr) rollbackImage(cr *sonarrv1alpha1.Sonarr, dep *appsv1.Deployment, image string, status *sonarrv1alpha1.SonarrStatus) (string, bool) {
+	running := dep.Spec.Template.Spec.Containers[0].Image
+
+	// A Deployment scaled to zero rolls out any image without running it
+	rolledOut := dep.Status.ObservedGeneration >= dep.Generation && dep.Spec.Replicas != nil && *dep.Spec.Replicas > 0 &&
+		dep.Status.UpdatedReplicas == *dep.Spec.Replicas && dep.Status.AvailableReplicas > 0
+	if rolledOut && len(status.Deployments[appsv1.DeploymentAvailable]) > 0 {
+		status.LastGoodImage = running
+	}
+
+	failed := len(status.Deployments[appsv1.DeploymentReplicaFailure]) > 0
+	if failed && status.LastGoodImage != "" && running != status.LastGoodImage && !imageBlocked(status, running) && !r.imagePinned(cr, running) {
+		status.BlockedImages = append(status.BlockedImages, running)
+	}
+	status.BlockedImages = r.selectableImages(cr, status.BlockedImages)
+
+	if !imageBlocked(status, image) {
+		return image, false
+	}
+	if status.LastGoodImage == "" {
+		return running, false
+	}
+	return status.LastGoodImage, running != status.LastGoodImage
+}
+
+// pinnedImageFailure returns the reason Sonarr is degraded when the image pinned by digest in the spec fails to
+// become available, which is not rolled back
+func (r *ReconcileSonarr) pinnedImageFailure(cr *sonarrv1alpha1.Sonarr, status *sonarrv1alpha1.SonarrStatus) string {
+	if len(status.Deployments[appsv1.DeploymentReplicaFailure]) == 0 || !r.imagePinned(cr, status.Image) {
+		return ""
+	}
+	return fmt.Sprintf("Image %s pinned in the spec failed to become available", status.Image)
+}
+
+// imagePinned reports whether image is the image pinned by digest in the spec of cr
+func (r *ReconcileSonarr) imagePinned(cr *sonarrv1alpha1.Sonarr, image string) bool {
+	return image_inspect.HasDigest(cr.Spec.Image) && image == cr.Spec.Image
+}
+
+// selectableImages returns the blocked images that the spec of cr may still select, keeping at most
+// maxBlockedImages of the most recent
+func (r *ReconcileSonarr) selectableImages(cr *sonarrv1alpha1.Sonarr, blocked []string) []string {
+	var images []string
+	for _, image := range blocked {
+		if r.imageFromSpec(cr, image) || r.imageFromSpec(cr, image_inspect.TrimDigest(image)) {
+			images = append(images, image)
+		}
+	}
+	if len(images) > maxBlockedImages {
+		images = images[len(images)-maxBlockedImages:]
+	}
+	return images
+}
+
+func imageBlocked(status *sonarrv1alpha1.SonarrStatus, image string) bool {
+	for _, blocked := range status.BlockedImages {
+		if blocked == image {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,180 @@
+package sonarr
+
+import (
+	"context"
+	"testing"
+
+	sonarrv1alpha1 "github.com/parflesh/sonarr-operator/pkg/apis/sonarr/v1alpha1"
+	"github.com/parflesh/sonarr-operator/pkg/image_inspect"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+)
+
+func TestSonarrControllerRollback(t *testing.T) {
+	cr := newTestSonarr(sonarrv1alpha1.SonarrSpec{})
+
+	r, req := newTestReconciler(cr)
+	inspector := r.imageInspector.(*image_inspect.MockImageInspector)
+	goodImage := cr.Spec.Image + "@" + testDigest
+	badImage := cr.Spec.Image + "@" + testNewDigest
+
+	setDeploymentStatus := func(condition appsv1.DeploymentCondition) {
+		dep := getDeployment(t, r, req)
+		dep.Status.UpdatedReplicas = *dep.Spec.Replicas
+		dep.Status.AvailableReplicas = 0
+		if condition.Type == appsv1.DeploymentAvailable {
+			dep.Status.AvailableReplicas = *dep.Spec.Replicas
+		}
+		dep.Status.Conditions = []appsv1.DeploymentCondition{condition}
+		if err := r.client.Status().Update(context.TODO(), dep); err != nil {
+			t.Fatalf("update deployment status: (%v)", err)
+		}
+	}
+	deploymentImage := func() string {
+		return getDeployment(t, r, req).Spec.Template.Spec.Containers[0].Image
+	}
+
+	reconcileTimes(t, r, req, 2)
+	setDeploymentStatus(appsv1.DeploymentCondition{Type: appsv1.DeploymentAvailable, Status: corev1.ConditionTrue})
+	reconcileTimes(t, r, req, 1)
+	cr = getSonarr(t, r, req)
+	if cr.Status.LastGoodImage != goodImage {
+		t.Errorf("last good image not recorded: %s", cr.Status.LastGoodImage)
+	}
+
+	// Update to an image that never becomes available
+	inspector.GetDigestOutput = testNewDigest
+	expireImageCheck(t, r, req)
+	reconcileTimes(t, r, req, 1)
+	if deploymentImage() != badImage {
+		t.Fatalf("deployment not updated: %s", deploymentImage())
+	}
+	setDeploymentStatus(appsv1.DeploymentCondition{
+		Type:   appsv1.DeploymentProgressing,
+		Status: corev1.ConditionFalse,
+		Reason: "ProgressDeadlineExceeded",
+	})
+	reconcileTimes(t, r, req, 1)
+	if deploymentImage() != goodImage {
+		t.Errorf("deployment not rolled back: %s", deploymentImage())
+	}
+	cr = getSonarr(t, r, req)
+	if len(cr.Status.BlockedImages) != 1 || cr.Status.BlockedImages[0] != badImage {
+		t.Errorf("failed image not blocked: %v", cr.Status.BlockedImages)
+	}
+	if cr.Status.Phase != "RollingBack" {
+		t.Errorf("unexpected phase: %s", cr.Status.Phase)
+	}
+
+	// Blocked image is not retried
+	setDeploymentStatus(appsv1.DeploymentCondition{Type: appsv1.DeploymentAvailable, Status: corev1.ConditionTrue})
+	reconcileTimes(t, r, req, 1)
+	if deploymentImage() != goodImage {
+		t.Errorf("blocked image retried: %s", deploymentImage())
+	}
+}
+
+func TestSonarrControllerRollbackPinned(t *testing.T) {
+	cr := newTestSonarr(sonarrv1alpha1.SonarrSpec{})
+
+	r, req := newTestReconciler(cr)
+	goodImage := cr.Spec.Image + "@" + testDigest
+	pinnedImage := cr.Spec.Image + "@" + testNewDigest
+
+	setDeploymentStatus := func(condition appsv1.DeploymentCondition) {
+		dep := getDeployment(t, r, req)
+		dep.Status.UpdatedReplicas = *dep.Spec.Replicas
+		dep.Status.AvailableReplicas = 0
+		if condition.Type == appsv1.DeploymentAvailable {
+			dep.Status.AvailableReplicas = *dep.Spec.Replicas
+		}
+		dep.Status.Conditions = []appsv1.DeploymentCondition{condition}
+		if err := r.client.Status().Update(context.TODO(), dep); err != nil {
+			t.Fatalf("update deployment status: (%v)", err)
+		}
+	}
+	deploymentImage := func() string {
+		return getDeployment(t, r, req).Spec.Template.Spec.Containers[0].Image
+	}
+
+	reconcileTimes(t, r, req, 2)
+	setDeploymentStatus(appsv1.DeploymentCondition{Type: appsv1.DeploymentAvailable, Status: corev1.ConditionTrue})
+	reconcileTimes(t, r, req, 1)
+	cr = getSonarr(t, r, req)
+	if cr.Status.LastGoodImage != goodImage {
+		t.Fatalf("last good image not recorded: %s", cr.Status.LastGoodImage)
+	}
+	cr.Status.BlockedImages = []string{"quay.io/other/sonarr@" + testNewDigest}
+	if err := r.client.Status().Update(context.TODO(), cr); err != nil {
+		t.Fatalf("update sonarr status: (%v)", err)
+	}
+
+	// Pin an image that never becomes available
+	cr = getSonarr(t, r, req)
+	cr.Spec.Image = pinnedImage
+	updateSonarr(t, r, cr)
+	reconcileTimes(t, r, req, 1)
+	if deploymentImage() != pinnedImage {
+		t.Fatalf("deployment not updated: %s", deploymentImage())
+	}
+	setDeploymentStatus(appsv1.DeploymentCondition{
+		Type:   appsv1.DeploymentProgressing,
+		Status: corev1.ConditionFalse,
+		Reason: "ProgressDeadlineExceeded",
+	})
+	reconcileTimes(t, r, req, 2)
+	if deploymentImage() != pinnedImage {
+		t.Errorf("pinned image rolled back: %s", deploymentImage())
+	}
+	cr = getSonarr(t, r, req)
+	if len(cr.Status.BlockedImages) != 0 {
+		t.Errorf("blocked images not pruned: %v", cr.Status.BlockedImages)
+	}
+	if cr.Status.Phase != "Degraded" {
+		t.Errorf("unexpected phase: %s", cr.Status.Phase)
+	}
+}
+
+func TestSonarrControllerRollbackScaledDown(t *testing.T) {
+	cr := newTestSonarr(sonarrv1alpha1.SonarrSpec{})
+
+	r, req := newTestReconciler(cr)
+	inspector := r.imageInspector.(*image_inspect.MockImageInspector)
+	goodImage := cr.Spec.Image + "@" + testDigest
+
+	reconcileTimes(t, r, req, 2)
+	dep := getDeployment(t, r, req)
+	dep.Status.UpdatedReplicas = 1
+	dep.Status.AvailableReplicas = 1
+	dep.Status.Conditions = []appsv1.DeploymentCondition{{Type: appsv1.DeploymentAvailable, Status: corev1.ConditionTrue}}
+	if err := r.client.Status().Update(context.TODO(), dep); err != nil {
+		t.Fatalf("update deployment status: (%v)", err)
+	}
+	reconcileTimes(t, r, req, 1)
+	if cr = getSonarr(t, r, req); cr.Status.LastGoodImage != goodImage {
+		t.Fatalf("last good image not recorded: %s", cr.Status.LastGoodImage)
+	}
+
+	// An image rolled out while scaled to zero never ran a pod and is not good
+	dep = getDeployment(t, r, req)
+	dep.Spec.Replicas = &[]int32{0}[0]
+	if err := r.client.Update(context.TODO(), dep); err != nil {
+		t.Fatalf("scale deployment: (%v)", err)
+	}
+	inspector.GetDigestOutput = testNewDigest
+	expireImageCheck(t, r, req)
+	reconcileTimes(t, r, req, 1)
+	dep = getDeployment(t, r, req)
+	if dep.Spec.Template.Spec.Containers[0].Image != cr.Spec.Image+"@"+testNewDigest {
+		t.Fatalf("deployment not updated: %s", dep.Spec.Template.Spec.Containers[0].Image)
+	}
+	dep.Status.UpdatedReplicas = 0
+	dep.Status.AvailableReplicas = 0
+	if err := r.client.Status().Update(context.TODO(), dep); err != nil {
+		t.Fatalf("update deployment status: (%v)", err)
+	}
+	reconcileTimes(t, r, req, 1)
+	if cr = getSonarr(t, r, req); cr.Status.LastGoodImage != goodImage {
+		t.Errorf("image of a scaled down deployment recorded as good: %s", cr.Status.LastGoodImage)
+	}
+}
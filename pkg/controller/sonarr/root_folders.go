@@ -0,0 +1,88 @@
+package sonarr
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"strings"
+
+	"github.com/parflesh/sonarr-operator/defaults"
+	sonarrv1alpha1 "github.com/parflesh/sonarr-operator/pkg/apis/sonarr/v1alpha1"
+	"github.com/parflesh/sonarr-operator/pkg/sonarrapi"
+)
+
+// rootFolderOnVolume reports whether the root folder p is on one of the volumes of cr, other than the config volume
+func (r *ReconcileSonarr) rootFolderOnVolume(cr *sonarrv1alpha1.Sonarr, p string) bool {
+	for _, vol := range cr.Spec.Volumes {
+		mount := path.Clean(vol.MountPath)
+		if mount == defaults.ConfigMountPath {
+			continue
+		}
+		if p == mount || strings.HasPrefix(p, mount+"/") {
+			return true
+		}
+	}
+	return false
+}
+
+// reconcileRootFolders adds the root folders of cr missing in Sonarr, and removes those not listed when pruning.
+// Sonarr returns root folders with a trailing slash, so paths are compared cleaned.  It returns the root folders
+// that are not on a volume, which are skipped.
+func (r *ReconcileSonarr) reconcileRootFolders(ctx context.Context, c *sonarrapi.Client, cr *sonarrv1alpha1.Sonarr, status *sonarrv1alpha1.SonarrStatus, drift *settingsDrift) (string, error) {
+	if len(cr.Spec.RootFolders) == 0 {
+		status.UnmanagedRootFolders = nil
+		return "", nil
+	}
+
+	folders, err := c.GetRootFolders(ctx)
+	if err != nil {
+		return "", err
+	}
+	existing := map[string]sonarrapi.RootFolder{}
+	for _, f := range folders {
+		existing[path.Clean(f.Path)] = f
+	}
+
+	desired := map[string]bool{}
+	var invalid []string
+	for _, p := range cr.Spec.RootFolders {
+		p = path.Clean(p)
+		if !path.IsAbs(p) || !r.rootFolderOnVolume(cr, p) {
+			invalid = append(invalid, p)
+			continue
+		}
+		desired[p] = true
+		if _, ok := existing[p]; ok || !drift.found("root folder %s missing", p) {
+			continue
+		}
+		log.Info("Adding root folder", "Namespace", cr.Namespace, "Name", cr.Name, "Path", p)
+		if _, err := c.CreateRootFolder(ctx, p); err != nil {
+			return "", fmt.Errorf("add root folder %s: %v", p, err)
+		}
+	}
+
+	var unmanaged []string
+	for _, f := range folders {
+		p := path.Clean(f.Path)
+		if desired[p] {
+			continue
+		}
+		if !cr.Spec.PruneRootFolders {
+			unmanaged = append(unmanaged, p)
+			continue
+		}
+		if !drift.found("root folder %s not in spec", p) {
+			continue
+		}
+		log.Info("Removing root folder", "Namespace", cr.Namespace, "Name", cr.Name, "Path", p)
+		if err := c.DeleteRootFolder(ctx, f.ID); err != nil && !sonarrapi.IsNotFound(err) {
+			return "", fmt.Errorf("remove root folder %s: %v", p, err)
+		}
+	}
+	status.UnmanagedRootFolders = unmanaged
+
+	if len(invalid) > 0 {
+		return fmt.Sprintf("Root folders not on a volume: %s", strings.Join(invalid, ", ")), nil
+	}
+	return "", nil
+}
@@ -0,0 +1,53 @@
+package sonarr
+
+import (
+	"testing"
+
+	sonarrv1alpha1 "github.com/parflesh/sonarr-operator/pkg/apis/sonarr/v1alpha1"
+	"github.com/parflesh/sonarr-operator/pkg/sonarrapi"
+	"github.com/parflesh/sonarr-operator/pkg/sonarrapi/sonarrapitest"
+)
+
+func TestSonarrControllerRootFolders(t *testing.T) {
+	cr := newTestSonarr(sonarrv1alpha1.SonarrSpec{
+		APIKeySecret: "sonarr-api-key",
+		Volumes: []sonarrv1alpha1.SonarrSpecVolume{
+			{Name: "config", Claim: "sonarr-config", MountPath: "/config"},
+			{Name: "tv", Claim: "sonarr-tv", MountPath: "/tv"},
+		},
+		RootFolders: []string{"/tv", "/tv/anime/", "/config/series", "/downloads"},
+	})
+	r, server, req := newApplicationTest(t, cr)
+	defer server.Close()
+	server.Add(sonarrapitest.RootFolders, sonarrapi.RootFolder{Path: "/tv/"})
+	server.Add(sonarrapitest.RootFolders, sonarrapi.RootFolder{Path: "/media/tv/"})
+
+	reconcileTimes(t, r, req, 1)
+	var folders []sonarrapi.RootFolder
+	server.List(sonarrapitest.RootFolders, &folders)
+	if len(folders) != 3 || folders[2].Path != "/tv/anime" {
+		t.Errorf("unexpected root folders: %v", folders)
+	}
+	cr = getSonarr(t, r, req)
+	if len(cr.Status.UnmanagedRootFolders) != 1 || cr.Status.UnmanagedRootFolders[0] != "/media/tv" {
+		t.Errorf("unmanaged root folders not reported: %v", cr.Status.UnmanagedRootFolders)
+	}
+	if cr.Status.Phase != "Degraded" || cr.Status.Reason != "Root folders not on a volume: /config/series, /downloads" {
+		t.Errorf("invalid root folders not reported: %s %s", cr.Status.Phase, cr.Status.Reason)
+	}
+
+	// Pruning removes root folders not in the spec
+	cr.Spec.RootFolders = []string{"/tv"}
+	cr.Spec.PruneRootFolders = true
+	updateSonarr(t, r, cr)
+	reconcileTimes(t, r, req, 1)
+	folders = nil
+	server.List(sonarrapitest.RootFolders, &folders)
+	if len(folders) != 1 || folders[0].Path != "/tv/" {
+		t.Errorf("root folders not pruned: %v", folders)
+	}
+	cr = getSonarr(t, r, req)
+	if cr.Status.Phase != "Available" || cr.Status.UnmanagedRootFolders != nil {
+		t.Errorf("unexpected status: %s %s %v", cr.Status.Phase, cr.Status.Reason, cr.Status.UnmanagedRootFolders)
+	}
+}
@@ -0,0 +1,158 @@
+package sonarr
+
+import (
+	"encoding/json"
+	"reflect"
+
+	"github.com/parflesh/sonarr-operator/defaults"
+	sonarrv1alpha1 "github.com/parflesh/sonarr-operator/pkg/apis/sonarr/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+)
+
+// servicePort returns the port the Service of cr serves Sonarr on
+func (r *ReconcileSonarr) servicePort(cr *sonarrv1alpha1.Sonarr) int32 {
+	if cr.Spec.Service != nil && cr.Spec.Service.Port != 0 {
+		return cr.Spec.Service.Port
+	}
+	return defaults.SonarrPort
+}
+
+func (r *ReconcileSonarr) newService(cr *sonarrv1alpha1.Sonarr) (*corev1.Service, error) {
+	labels := r.labelsForCR(cr)
+
+	dep := &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      cr.Name,
+			Namespace: cr.Namespace,
+			Labels:    labels,
+		},
+		Spec: corev1.ServiceSpec{
+			Type: corev1.ServiceTypeClusterIP,
+			Ports: []corev1.ServicePort{
+				{
+					Name:     "http",
+					Protocol: corev1.ProtocolTCP,
+					Port:     r.servicePort(cr),
+					TargetPort: intstr.IntOrString{
+						Type:   intstr.Int,
+						IntVal: r.containerPort(cr),
+						StrVal: "",
+					},
+				},
+			},
+			Selector: labels,
+		},
+	}
+
+	if svc := cr.Spec.Service; svc != nil {
+		if svc.Type != "" {
+			dep.Spec.Type = corev1.ServiceType(svc.Type)
+		}
+		if len(svc.Annotations) > 0 {
+			dep.Annotations = map[string]string{}
+			for k, v := range svc.Annotations {
+				dep.Annotations[k] = v
+			}
+		}
+		if dep.Spec.Type == corev1.ServiceTypeNodePort || dep.Spec.Type == corev1.ServiceTypeLoadBalancer {
+			dep.Spec.Ports[0].NodePort = svc.NodePort
+			dep.Spec.ExternalTrafficPolicy = corev1.ServiceExternalTrafficPolicyType(svc.ExternalTrafficPolicy)
+		}
+		if dep.Spec.Type == corev1.ServiceTypeLoadBalancer {
+			dep.Spec.LoadBalancerIP = svc.LoadBalancerIP
+		}
+	}
+
+	err := controllerutil.SetControllerReference(cr, dep, r.scheme)
+	if err != nil {
+		return dep, err
+	}
+
+	return dep, nil
+}
+
+// reconcileService returns the patch taking the Service f to p, or nil when it is up to date.  Cluster assigned
+// fields, like the cluster IP and node ports, are kept.
+func (r *ReconcileSonarr) reconcileService(f *corev1.Service, p *corev1.Service) (client.Patch, error) {
+	patch, err := mergePatch(f, p)
+	if err != nil {
+		return nil, err
+	}
+
+	ports := servicePorts(f, p)
+	toClusterIP := p.Spec.Type == corev1.ServiceTypeClusterIP &&
+		(f.Spec.ExternalTrafficPolicy != "" || f.Spec.HealthCheckNodePort != 0 || f.Spec.LoadBalancerIP != "")
+	if !toClusterIP && reflect.DeepEqual(f.Spec.Selector, p.Spec.Selector) && reflect.DeepEqual(f.Spec.Ports, ports) {
+		return patch, nil
+	}
+
+	data := []byte("{}")
+	if patch != nil {
+		if data, err = patch.Data(f); err != nil {
+			return nil, err
+		}
+	}
+	obj := map[string]interface{}{}
+	if err := json.Unmarshal(data, &obj); err != nil {
+		return nil, err
+	}
+
+	// Fields the cluster set for NodePort and LoadBalancer services are invalid for ClusterIP services
+	if toClusterIP {
+		for _, field := range []string{"externalTrafficPolicy", "healthCheckNodePort", "loadBalancerIP"} {
+			if err := unstructured.SetNestedField(obj, nil, "spec", field); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	// Selector entries or ports added by others would stop the Service from reaching Sonarr, so the selector and
+	// ports are replaced as a whole
+	selector := map[string]interface{}{"$patch": "replace"}
+	for k, v := range p.Spec.Selector {
+		selector[k] = v
+	}
+	portList := []interface{}{map[string]interface{}{"$patch": "replace"}}
+	for i := range ports {
+		port, err := runtime.DefaultUnstructuredConverter.ToUnstructured(&ports[i])
+		if err != nil {
+			return nil, err
+		}
+		portList = append(portList, port)
+	}
+	unstructured.RemoveNestedField(obj, "spec", "$setElementOrder/ports")
+	if err := unstructured.SetNestedField(obj, selector, "spec", "selector"); err != nil {
+		return nil, err
+	}
+	if err := unstructured.SetNestedSlice(obj, portList, "spec", "ports"); err != nil {
+		return nil, err
+	}
+	if data, err = json.Marshal(obj); err != nil {
+		return nil, err
+	}
+	return client.ConstantPatch(types.StrategicMergePatchType, data), nil
+}
+
+// servicePorts returns the ports of p with the node ports the cluster assigned to f
+func servicePorts(f *corev1.Service, p *corev1.Service) []corev1.ServicePort {
+	ports := make([]corev1.ServicePort, len(p.Spec.Ports))
+	for i, port := range p.Spec.Ports {
+		ports[i] = port
+		if port.NodePort != 0 || p.Spec.Type == corev1.ServiceTypeClusterIP {
+			continue
+		}
+		for _, found := range f.Spec.Ports {
+			if found.Name == port.Name {
+				ports[i].NodePort = found.NodePort
+			}
+		}
+	}
+	return ports
+}
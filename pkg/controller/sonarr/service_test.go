@@ -0,0 +1,251 @@
+package sonarr
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	sonarrv1alpha1 "github.com/parflesh/sonarr-operator/pkg/apis/sonarr/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestReconcileService(t *testing.T) {
+	cr := &sonarrv1alpha1.Sonarr{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "sonarr",
+			Namespace: "sonarr",
+		},
+		Spec: sonarrv1alpha1.SonarrSpec{
+			Service: &sonarrv1alpha1.SonarrSpecService{
+				Type:        "NodePort",
+				Annotations: map[string]string{"example.com/team": "media"},
+			},
+		},
+	}
+	s := scheme.Scheme
+	s.AddKnownTypes(sonarrv1alpha1.SchemeGroupVersion, cr)
+	r := &ReconcileSonarr{scheme: s}
+
+	tests := []struct {
+		name     string
+		mutate   func(svc *corev1.Service)
+		spec     func(spec *sonarrv1alpha1.SonarrSpecService)
+		mismatch bool
+		check    func(t *testing.T, svc *corev1.Service)
+	}{
+		{name: "in sync"},
+		{
+			name:   "cluster assigned fields are kept",
+			mutate: func(svc *corev1.Service) { svc.Spec.ClusterIP = "10.0.0.10"; svc.Spec.Ports[0].NodePort = 31234 },
+		},
+		{
+			name:     "port",
+			mutate:   func(svc *corev1.Service) { svc.Spec.Ports[0].Port = 80 },
+			mismatch: true,
+			check: func(t *testing.T, svc *corev1.Service) {
+				if svc.Spec.Ports[0].Port != 8989 {
+					t.Errorf("port not repaired: %v", svc.Spec.Ports)
+				}
+			},
+		},
+		{
+			name: "port keeps node port",
+			mutate: func(svc *corev1.Service) {
+				svc.Spec.Ports[0].NodePort = 31234
+				svc.Spec.Ports[0].TargetPort.IntVal = 80
+			},
+			mismatch: true,
+			check: func(t *testing.T, svc *corev1.Service) {
+				if svc.Spec.Ports[0].TargetPort.IntVal != 8989 || svc.Spec.Ports[0].NodePort != 31234 {
+					t.Errorf("port not repaired: %v", svc.Spec.Ports)
+				}
+			},
+		},
+		{
+			name:     "requested node port",
+			mutate:   func(svc *corev1.Service) { svc.Spec.Ports[0].NodePort = 31234 },
+			spec:     func(spec *sonarrv1alpha1.SonarrSpecService) { spec.NodePort = 30989 },
+			mismatch: true,
+			check: func(t *testing.T, svc *corev1.Service) {
+				if svc.Spec.Ports[0].NodePort != 30989 {
+					t.Errorf("node port not repaired: %v", svc.Spec.Ports)
+				}
+			},
+		},
+		{
+			name:     "selector",
+			mutate:   func(svc *corev1.Service) { svc.Spec.Selector = map[string]string{"app": "sonarr"} },
+			mismatch: true,
+			check: func(t *testing.T, svc *corev1.Service) {
+				if !reflect.DeepEqual(svc.Spec.Selector, map[string]string{"sonarr": "sonarr"}) {
+					t.Errorf("selector not repaired: %v", svc.Spec.Selector)
+				}
+			},
+		},
+		{
+			name:     "labels",
+			mutate:   func(svc *corev1.Service) { svc.Labels = nil },
+			mismatch: true,
+			check: func(t *testing.T, svc *corev1.Service) {
+				if svc.Labels["sonarr"] != "sonarr" {
+					t.Errorf("labels not repaired: %v", svc.Labels)
+				}
+			},
+		},
+		{
+			name:     "owned annotation changed",
+			mutate:   func(svc *corev1.Service) { svc.Annotations["example.com/team"] = "other" },
+			mismatch: true,
+			check: func(t *testing.T, svc *corev1.Service) {
+				if svc.Annotations["example.com/team"] != "media" {
+					t.Errorf("annotation not repaired: %v", svc.Annotations)
+				}
+			},
+		},
+		{
+			name:   "foreign annotation kept",
+			mutate: func(svc *corev1.Service) { svc.Annotations["example.com/other"] = "kept" },
+		},
+		{
+			name:     "annotation removed from spec",
+			mutate:   func(svc *corev1.Service) { svc.Annotations["example.com/other"] = "kept" },
+			spec:     func(spec *sonarrv1alpha1.SonarrSpecService) { spec.Annotations = nil },
+			mismatch: true,
+			check: func(t *testing.T, svc *corev1.Service) {
+				delete(svc.Annotations, lastAppliedKey)
+				if !reflect.DeepEqual(svc.Annotations, map[string]string{"example.com/other": "kept"}) {
+					t.Errorf("unexpected annotations: %v", svc.Annotations)
+				}
+			},
+		},
+		{
+			name:     "type",
+			mutate:   func(svc *corev1.Service) { svc.Spec.Ports[0].NodePort = 31234 },
+			spec:     func(spec *sonarrv1alpha1.SonarrSpecService) { spec.Type = "ClusterIP" },
+			mismatch: true,
+			check: func(t *testing.T, svc *corev1.Service) {
+				if svc.Spec.Type != corev1.ServiceTypeClusterIP || svc.Spec.Ports[0].NodePort != 0 {
+					t.Errorf("type not repaired: %v", svc.Spec)
+				}
+			},
+		},
+	}
+
+	for _, test := range tests {
+		found, err := r.newService(cr)
+		if err != nil {
+			t.Fatalf("%s: new service: (%v)", test.name, err)
+		}
+		if err := setLastApplied(found); err != nil {
+			t.Fatalf("%s: set last applied: (%v)", test.name, err)
+		}
+		if test.mutate != nil {
+			test.mutate(found)
+		}
+		clusterIP := found.Spec.ClusterIP
+		r.client = fake.NewFakeClientWithScheme(s, found)
+
+		desiredCR := cr.DeepCopy()
+		if test.spec != nil {
+			test.spec(desiredCR.Spec.Service)
+		}
+		desired, err := r.newService(desiredCR)
+		if err != nil {
+			t.Fatalf("%s: new service: (%v)", test.name, err)
+		}
+
+		patch, err := r.reconcileService(found, desired)
+		if err != nil {
+			t.Fatalf("%s: reconcile service: (%v)", test.name, err)
+		}
+		if test.mismatch != (patch != nil) {
+			t.Errorf("%s: expected mismatch %t, got patch %v", test.name, test.mismatch, patch)
+		}
+		if patch != nil {
+			if err := r.client.Patch(context.TODO(), found, patch); err != nil {
+				t.Fatalf("%s: patch service: (%v)", test.name, err)
+			}
+		}
+
+		svc := &corev1.Service{}
+		if err := r.client.Get(context.TODO(), types.NamespacedName{Namespace: found.Namespace, Name: found.Name}, svc); err != nil {
+			t.Fatalf("%s: get service: (%v)", test.name, err)
+		}
+		if test.check != nil {
+			test.check(t, svc)
+		}
+		if svc.Spec.ClusterIP != clusterIP {
+			t.Errorf("%s: cluster ip changed: %s", test.name, svc.Spec.ClusterIP)
+		}
+	}
+}
+
+func TestSonarrControllerService(t *testing.T) {
+	cr := newTestSonarr(sonarrv1alpha1.SonarrSpec{
+		Service: &sonarrv1alpha1.SonarrSpecService{
+			Type:                  "LoadBalancer",
+			Port:                  80,
+			LoadBalancerIP:        "192.0.2.10",
+			ExternalTrafficPolicy: "Local",
+			Annotations:           map[string]string{"metallb.universe.tf/address-pool": "media"},
+		},
+	})
+
+	r, req := newTestReconciler(cr)
+
+	reconcileTimes(t, r, req, 3)
+	svc := &corev1.Service{}
+	if err := r.client.Get(context.TODO(), req.NamespacedName, svc); err != nil {
+		t.Fatalf("service not created: (%v)", err)
+	}
+	if svc.Spec.Type != corev1.ServiceTypeLoadBalancer || svc.Spec.Ports[0].Port != 80 || svc.Spec.Ports[0].TargetPort.IntVal != 8989 {
+		t.Errorf("unexpected service: %v", svc.Spec)
+	}
+	if svc.Spec.LoadBalancerIP != "192.0.2.10" || svc.Spec.ExternalTrafficPolicy != corev1.ServiceExternalTrafficPolicyTypeLocal {
+		t.Errorf("unexpected load balancer settings: %v", svc.Spec)
+	}
+	if svc.Annotations["metallb.universe.tf/address-pool"] != "media" {
+		t.Errorf("service annotations not set: %v", svc.Annotations)
+	}
+
+	// Fields assigned by the cluster are kept while drift is corrected
+	svc.Spec.ClusterIP = "10.0.0.10"
+	svc.Spec.Ports[0].NodePort = 31234
+	svc.Spec.Ports[0].Port = 8080
+	if err := r.client.Update(context.TODO(), svc); err != nil {
+		t.Fatalf("update service: (%v)", err)
+	}
+	reconcileTimes(t, r, req, 1)
+	svc = &corev1.Service{}
+	if err := r.client.Get(context.TODO(), req.NamespacedName, svc); err != nil {
+		t.Fatalf("get service: (%v)", err)
+	}
+	if svc.Spec.Ports[0].Port != 80 || svc.Spec.Ports[0].NodePort != 31234 || svc.Spec.ClusterIP != "10.0.0.10" {
+		t.Errorf("service drift not corrected: %v", svc.Spec)
+	}
+
+	// Switching to ClusterIP drops the node port and load balancer settings
+	cr = getSonarr(t, r, req)
+	cr.Spec.Service.Type = "ClusterIP"
+	updateSonarr(t, r, cr)
+	reconcileTimes(t, r, req, 1)
+	svc = &corev1.Service{}
+	if err := r.client.Get(context.TODO(), req.NamespacedName, svc); err != nil {
+		t.Fatalf("get service: (%v)", err)
+	}
+	if svc.Spec.Type != corev1.ServiceTypeClusterIP || svc.Spec.Ports[0].NodePort != 0 || svc.Spec.LoadBalancerIP != "" || svc.Spec.ExternalTrafficPolicy != "" {
+		t.Errorf("service type not switched: %v", svc.Spec)
+	}
+
+	res, err := r.Reconcile(req)
+	if err != nil {
+		t.Fatalf("reconcile: (%v)", err)
+	}
+	if res.Requeue {
+		t.Error("reconcile requeued even though all should be good")
+	}
+}
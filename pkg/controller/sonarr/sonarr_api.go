@@ -0,0 +1,115 @@
+package sonarr
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/parflesh/sonarr-operator/defaults"
+	sonarrv1alpha1 "github.com/parflesh/sonarr-operator/pkg/apis/sonarr/v1alpha1"
+	"github.com/parflesh/sonarr-operator/pkg/sonarrapi"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+var (
+	// backupTimeout limits how long an update waits for the pre-update backup
+	backupTimeout = 5 * time.Minute
+
+	// backupPollInterval is the time between checks of a running pre-update backup
+	backupPollInterval = 5 * time.Second
+)
+
+// sonarrURL returns the base URL of the Sonarr API of cr, reached through its Service
+func (r *ReconcileSonarr) sonarrURL(cr *sonarrv1alpha1.Sonarr) string {
+	if r.sonarrBaseURL != nil {
+		return r.sonarrBaseURL(cr)
+	}
+	return fmt.Sprintf("http://%s.%s.svc:%d%s", cr.Name, cr.Namespace, r.servicePort(cr), r.urlBase(cr))
+}
+
+// sonarrClient returns a client for the Sonarr API of cr using the API key from the API key secret
+func (r *ReconcileSonarr) sonarrClient(cr *sonarrv1alpha1.Sonarr) (*sonarrapi.Client, error) {
+	if cr.Spec.APIKeySecret == "" {
+		return nil, fmt.Errorf("api key secret not set")
+	}
+
+	secret := &corev1.Secret{}
+	err := r.client.Get(context.TODO(), client.ObjectKey{Namespace: cr.Namespace, Name: cr.Spec.APIKeySecret}, secret)
+	if err != nil {
+		return nil, err
+	}
+	apiKey, ok := secret.Data[defaults.APIKeySecretKey]
+	if !ok || len(apiKey) == 0 {
+		return nil, fmt.Errorf("secret %s has no %s", secret.Name, defaults.APIKeySecretKey)
+	}
+
+	return sonarrapi.NewClient(r.sonarrURL(cr), strings.TrimSpace(string(apiKey))), nil
+}
+
+// Client returns a client for the Sonarr API of cr, for controllers of resources managed through Sonarr
+func Client(c client.Client, cr *sonarrv1alpha1.Sonarr) (*sonarrapi.Client, error) {
+	return (&ReconcileSonarr{client: c}).sonarrClient(cr)
+}
+
+// Reachable reports whether the last poll of cr reached the Sonarr API
+func Reachable(cr *sonarrv1alpha1.Sonarr) bool {
+	return applicationReachable(&cr.Status)
+}
+
+// preUpdateBackup has Sonarr back up its database and settings before image is rolled out, without waiting for the
+// backup to complete.  The backup command is started on the first call and checked on later calls, it reports
+// whether the backup of image completed.  A completed backup is not repeated until image changes.
+func (r *ReconcileSonarr) preUpdateBackup(cr *sonarrv1alpha1.Sonarr, image string, status *sonarrv1alpha1.SonarrStatus) (bool, error) {
+	if status.PreUpdateBackupImage == image && status.PreUpdateBackupCommand == 0 {
+		return true, nil
+	}
+
+	c, err := r.sonarrClient(cr)
+	if err != nil {
+		return false, err
+	}
+	ctx, cancel := context.WithTimeout(context.TODO(), applicationTimeout)
+	defer cancel()
+
+	if status.PreUpdateBackupImage != image {
+		command, err := c.RunCommand(ctx, sonarrapi.BackupCommand)
+		if err != nil {
+			return false, err
+		}
+		status.PreUpdateBackupImage = image
+		status.PreUpdateBackupCommand = command.ID
+		return false, nil
+	}
+
+	// Start over with a new backup on the next call when this one failed
+	fail := func(err error) (bool, error) {
+		status.PreUpdateBackupImage = ""
+		status.PreUpdateBackupCommand = 0
+		return false, err
+	}
+	command, err := c.GetCommand(ctx, status.PreUpdateBackupCommand)
+	if err != nil {
+		return fail(err)
+	}
+	if !command.Done() {
+		if command.Queued != nil && time.Since(*command.Queued) > backupTimeout {
+			return fail(fmt.Errorf("backup still %s after %s", command.Status, backupTimeout))
+		}
+		return false, nil
+	}
+	if command.Status != sonarrapi.CommandCompleted {
+		return fail(fmt.Errorf("backup %s: %s", command.Status, command.Message))
+	}
+
+	backup, err := c.NewestBackup(ctx)
+	if err != nil {
+		return fail(err)
+	}
+	status.PreUpdateBackup = backup.Name
+	status.PreUpdateBackupTime = &metav1.Time{Time: backup.Time}
+	status.PreUpdateBackupCommand = 0
+	return true, nil
+}
@@ -3,9 +3,14 @@ package sonarr
 import (
 	"context"
 	"fmt"
+	imagetypes "github.com/containers/image/v5/types"
 	"github.com/parflesh/sonarr-operator/defaults"
+	"github.com/parflesh/sonarr-operator/pkg/image_inspect"
 	appsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
+	batchv1beta1 "k8s.io/api/batch/v1beta1"
 	corev1 "k8s.io/api/core/v1"
+	networkingv1beta1 "k8s.io/api/networking/v1beta1"
 	"k8s.io/apimachinery/pkg/util/intstr"
 	"reflect"
 	"strings"
@@ -13,8 +18,10 @@ import (
 
 	sonarrv1alpha1 "github.com/parflesh/sonarr-operator/pkg/apis/sonarr/v1alpha1"
 	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/record"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
@@ -27,6 +34,9 @@ import (
 
 var log = logf.Log.WithName("controller_sonarr")
 
+// registryTimeout limits how long a lookup in the image registry may take
+var registryTimeout = 30 * time.Second
+
 // Add creates a new Sonarr Controller and adds it to the Manager. The Manager will set fields on the Controller
 // and Start it when the Manager is Started.
 func Add(mgr manager.Manager) error {
@@ -35,7 +45,17 @@ func Add(mgr manager.Manager) error {
 
 // newReconciler returns a new reconcile.Reconciler
 func newReconciler(mgr manager.Manager) reconcile.Reconciler {
-	return &ReconcileSonarr{client: mgr.GetClient(), scheme: mgr.GetScheme()}
+	routeAPI, err := routeAPIAvailable(mgr.GetConfig())
+	if err != nil {
+		log.Error(err, "Failed to discover the OpenShift Route API, using Ingresses")
+	}
+	return &ReconcileSonarr{
+		client:         mgr.GetClient(),
+		scheme:         mgr.GetScheme(),
+		recorder:       mgr.GetEventRecorderFor("sonarr-controller"),
+		imageInspector: image_inspect.NewImageInspector(),
+		routeAPI:       routeAPI,
+	}
 }
 
 // add adds a new Controller to mgr with r as the reconcile.Reconciler
@@ -68,6 +88,56 @@ func add(mgr manager.Manager, r reconcile.Reconciler) error {
 		return err
 	}
 
+	err = c.Watch(&source.Kind{Type: &corev1.ConfigMap{}}, &handler.EnqueueRequestForOwner{
+		IsController: true,
+		OwnerType:    &sonarrv1alpha1.Sonarr{},
+	})
+	if err != nil {
+		return err
+	}
+
+	err = c.Watch(&source.Kind{Type: &corev1.Secret{}}, &handler.EnqueueRequestForOwner{
+		IsController: true,
+		OwnerType:    &sonarrv1alpha1.Sonarr{},
+	})
+	if err != nil {
+		return err
+	}
+
+	err = c.Watch(&source.Kind{Type: &networkingv1beta1.Ingress{}}, &handler.EnqueueRequestForOwner{
+		IsController: true,
+		OwnerType:    &sonarrv1alpha1.Sonarr{},
+	})
+	if err != nil {
+		return err
+	}
+
+	if rs, ok := r.(*ReconcileSonarr); ok && rs.routeAPI {
+		err = c.Watch(&source.Kind{Type: newRouteObject()}, &handler.EnqueueRequestForOwner{
+			IsController: true,
+			OwnerType:    &sonarrv1alpha1.Sonarr{},
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	err = c.Watch(&source.Kind{Type: &batchv1.Job{}}, &handler.EnqueueRequestForOwner{
+		IsController: true,
+		OwnerType:    &sonarrv1alpha1.Sonarr{},
+	})
+	if err != nil {
+		return err
+	}
+
+	err = c.Watch(&source.Kind{Type: &batchv1beta1.CronJob{}}, &handler.EnqueueRequestForOwner{
+		IsController: true,
+		OwnerType:    &sonarrv1alpha1.Sonarr{},
+	})
+	if err != nil {
+		return err
+	}
+
 	return nil
 }
 
@@ -78,8 +148,16 @@ var _ reconcile.Reconciler = &ReconcileSonarr{}
 type ReconcileSonarr struct {
 	// This client, initialized using mgr.Client() above, is a split client
 	// that reads objects from the cache and writes to the apiserver
-	client client.Client
-	scheme *runtime.Scheme
+	client         client.Client
+	scheme         *runtime.Scheme
+	recorder       record.EventRecorder
+	imageInspector image_inspect.ImageInspector
+
+	// sonarrBaseURL overrides the URL used to reach the Sonarr API
+	sonarrBaseURL func(cr *sonarrv1alpha1.Sonarr) string
+
+	// routeAPI is set when the cluster serves OpenShift Routes, which are then used instead of Ingresses
+	routeAPI bool
 }
 
 func (r *ReconcileSonarr) Reconcile(request reconcile.Request) (reconcile.Result, error) {
@@ -109,23 +187,54 @@ func (r *ReconcileSonarr) Reconcile(request reconcile.Request) (reconcile.Result
 		newStatus.Phase = "Initializing"
 		newStatus.Reason = "Setting default spec settings"
 		newStatus.Deployments = r.checkDeploymentStatus(&appsv1.Deployment{})
-		newStatus.Image = instance.Spec.Image
 		_ = r.updateStatus(newStatus, instance)
 		return reconcile.Result{Requeue: true}, nil
 	}
 
-	/*imageManifest, err := r.imageInspector.GetImageLabels(ctx, instance.Spec.Image)
+	image, err := r.desiredImage(instance, &newStatus)
+	if err != nil {
+		reqLogger.Error(err, "Failed to resolve image digest", "Image", instance.Spec.Image)
+	}
+	newStatus.Image = image
+
+	phase, reason, err := r.reconcileAPIKeySecret(instance, &newStatus)
 	if err != nil {
 		return reconcile.Result{}, err
-	}*/
+	}
+	if phase != "" {
+		newStatus.Phase = phase
+		newStatus.Reason = reason
+		_ = r.updateStatus(newStatus, instance)
+		return reconcile.Result{Requeue: true}, nil
+	}
 
-	newDep, err := r.newDeployment(instance)
+	phase, reason, err = r.reconcileConfigMap(instance)
 	if err != nil {
 		return reconcile.Result{}, err
 	}
+	if phase != "" {
+		newStatus.Phase = phase
+		newStatus.Reason = reason
+		_ = r.updateStatus(newStatus, instance)
+		return reconcile.Result{Requeue: true}, nil
+	}
+
+	newDep, err := r.newDeployment(instance, image)
+	if err != nil {
+		return reconcile.Result{}, err
+	}
+	restoring := r.restoreRequested(instance)
 	foundDep := &appsv1.Deployment{}
 	err = r.client.Get(context.TODO(), request.NamespacedName, foundDep)
 	if err != nil && errors.IsNotFound(err) {
+		if err := setLastApplied(newDep); err != nil {
+			return reconcile.Result{}, err
+		}
+		// Replicas are only set on create, so scaling by others is kept
+		newDep.Spec.Replicas = &[]int32{1}[0]
+		if restoring {
+			scaleForRestore(newDep, true)
+		}
 		err := r.client.Create(context.TODO(), newDep)
 		if err != nil {
 			return reconcile.Result{}, err
@@ -139,20 +248,91 @@ func (r *ReconcileSonarr) Reconcile(request reconcile.Request) (reconcile.Result
 	}
 
 	newStatus.Deployments = r.checkDeploymentStatus(foundDep)
+	image, rollback := r.rollbackImage(instance, foundDep, image, &newStatus)
+	newStatus.PendingImage = ""
+	var nextWindow time.Time
+	if foundImage := foundDep.Spec.Template.Spec.Containers[0].Image; foundImage != image && !rollback {
+		open, next, err := updateWindowOpen(instance.Spec.UpdateWindow, time.Now())
+		if err != nil {
+			reqLogger.Error(err, "Invalid update window", "UpdateWindow", instance.Spec.UpdateWindow)
+		}
+		if !open {
+			// Keep the running image until the window opens
+			newStatus.PendingImage = image
+			nextWindow = next
+			image = foundImage
+		}
+	}
+	newDep.Spec.Template.Spec.Containers[0].Image = image
+	newStatus.Image = image
+	newStatus.CurrentVersion = r.imageVersion(instance, newStatus.Image)
 	_ = r.updateStatus(newStatus, instance)
 
-	if err := r.reconcileDeployment(foundDep, newDep); err != nil {
-		reqLogger.Error(err, "Deployment.Namespace", foundDep.Namespace, "Deployment.Name", foundDep.Name)
-		if err := r.client.Update(context.TODO(), foundDep); err != nil {
+	patch, err := r.reconcileDeployment(foundDep, newDep)
+	if err != nil {
+		return reconcile.Result{}, err
+	}
+	if patch != nil {
+		reqLogger.Info("Deployment drifted from spec", "Deployment.Namespace", foundDep.Namespace, "Deployment.Name", foundDep.Name)
+		if foundDep.Spec.Template.Spec.Containers[0].Image != image && !rollback && instance.Spec.BackupBeforeUpdate {
+			if foundDep.Status.AvailableReplicas == 0 {
+				reqLogger.Info("Sonarr not running, skipping pre-update backup")
+			} else {
+				done, err := r.preUpdateBackup(instance, image, &newStatus)
+				if err != nil {
+					newStatus.Phase = "UpdateFailed"
+					newStatus.Reason = fmt.Sprintf("Pre-update backup failed: %v", err)
+					_ = r.updateStatus(newStatus, instance)
+					return reconcile.Result{}, err
+				}
+				if !done {
+					newStatus.Phase = "BackingUp"
+					newStatus.Reason = "Waiting for pre-update backup"
+					_ = r.updateStatus(newStatus, instance)
+					return reconcile.Result{RequeueAfter: backupPollInterval}, nil
+				}
+			}
+		}
+		if err := r.client.Patch(context.TODO(), foundDep, patch); err != nil {
 			return reconcile.Result{}, err
 		}
-		newStatus.Image = instance.Spec.Image
 		newStatus.Phase = "Updating"
 		newStatus.Reason = "Updating deployment"
+		if rollback {
+			newStatus.Phase = "RollingBack"
+			newStatus.Reason = fmt.Sprintf("Rolling back to %s after failed update", image)
+		}
+		_ = r.updateStatus(newStatus, instance)
+		return reconcile.Result{Requeue: true}, nil
+	}
+
+	scaledDep := foundDep.DeepCopy()
+	if scaleForRestore(scaledDep, restoring) {
+		if err := r.client.Patch(context.TODO(), scaledDep, client.MergeFrom(foundDep)); err != nil {
+			return reconcile.Result{}, err
+		}
+		newStatus.Phase = "Restoring"
+		newStatus.Reason = "Scaling Sonarr down for restore"
+		if !restoring {
+			newStatus.Reason = "Scaling Sonarr back up after restore"
+		}
 		_ = r.updateStatus(newStatus, instance)
 		return reconcile.Result{Requeue: true}, nil
 	}
 
+	if restoring {
+		result, err := r.reconcileRestore(instance, foundDep, &newStatus)
+		if err != nil {
+			return result, err
+		}
+		if phase := newStatus.Restore.Phase; phase == sonarrv1alpha1.RestoreSucceeded || phase == sonarrv1alpha1.RestoreFailed {
+			newStatus.Phase = "Restoring"
+			newStatus.Reason = fmt.Sprintf("Restore %s", strings.ToLower(phase))
+		}
+		_ = r.updateStatus(newStatus, instance)
+		return result, nil
+	}
+
 	newSvc, err := r.newService(instance)
 	if err != nil {
 		return reconcile.Result{}, err
@@ -160,6 +340,9 @@ func (r *ReconcileSonarr) Reconcile(request reconcile.Request) (reconcile.Result
 	foundSvc := &corev1.Service{}
 	err = r.client.Get(context.TODO(), request.NamespacedName, foundSvc)
 	if err != nil && errors.IsNotFound(err) {
+		if err := setLastApplied(newSvc); err != nil {
+			return reconcile.Result{}, err
+		}
 		err := r.client.Create(context.TODO(), newSvc)
 		if err != nil {
 			return reconcile.Result{}, err
@@ -172,6 +355,89 @@ func (r *ReconcileSonarr) Reconcile(request reconcile.Request) (reconcile.Result
 		return reconcile.Result{}, err
 	}
 
+	patch, err = r.reconcileService(foundSvc, newSvc)
+	if err != nil {
+		return reconcile.Result{}, err
+	}
+	if patch != nil {
+		reqLogger.Info("Service drifted from spec", "Service.Namespace", foundSvc.Namespace, "Service.Name", foundSvc.Name)
+		if err := r.client.Patch(context.TODO(), foundSvc, patch); err != nil {
+			return reconcile.Result{}, err
+		}
+		newStatus.Phase = "Updating"
+		newStatus.Reason = "Updating service"
+		_ = r.updateStatus(newStatus, instance)
+		return reconcile.Result{Requeue: true}, nil
+	}
+
+	phase, reason, err = r.reconcileExposure(instance)
+	if err != nil {
+		return reconcile.Result{}, err
+	}
+	if phase != "" {
+		newStatus.Phase = phase
+		newStatus.Reason = reason
+		_ = r.updateStatus(newStatus, instance)
+		return reconcile.Result{Requeue: true}, nil
+	}
+
+	foundCron := &batchv1beta1.CronJob{}
+	err = r.client.Get(context.TODO(), client.ObjectKey{Namespace: instance.Namespace, Name: r.backupName(instance)}, foundCron)
+	if err != nil && !errors.IsNotFound(err) {
+		return reconcile.Result{}, err
+	}
+	cronFound := err == nil
+	var backupProblem string
+	if _, err := r.configVolume(instance); instance.Spec.Backup != nil && err != nil {
+		// Keep any existing cronjob, there is no configuration to back up with the volumes in the spec
+		backupProblem = fmt.Sprintf("Backups not scheduled: %v", err)
+	} else if instance.Spec.Backup != nil {
+		newCron, err := r.newCronJob(instance)
+		if err != nil {
+			return reconcile.Result{}, err
+		}
+		if !cronFound {
+			if err := setLastApplied(newCron); err != nil {
+				return reconcile.Result{}, err
+			}
+			err := r.client.Create(context.TODO(), newCron)
+			if err != nil {
+				return reconcile.Result{}, err
+			}
+			newStatus.Phase = "Initializing"
+			newStatus.Reason = "Created backup cronjob"
+			_ = r.updateStatus(newStatus, instance)
+			return reconcile.Result{Requeue: true}, nil
+		}
+
+		patch, err := r.reconcileCronJob(foundCron, newCron)
+		if err != nil {
+			return reconcile.Result{}, err
+		}
+		if patch != nil {
+			reqLogger.Info("CronJob drifted from spec", "CronJob.Namespace", foundCron.Namespace, "CronJob.Name", foundCron.Name)
+			if err := r.client.Patch(context.TODO(), foundCron, patch); err != nil {
+				return reconcile.Result{}, err
+			}
+			newStatus.Phase = "Updating"
+			newStatus.Reason = "Updating backup cronjob"
+			_ = r.updateStatus(newStatus, instance)
+			return reconcile.Result{Requeue: true}, nil
+		}
+
+		lastBackup, err := r.lastBackupTime(instance)
+		if err != nil {
+			return reconcile.Result{}, err
+		}
+		if lastBackup != nil {
+			newStatus.LastBackupTime = lastBackup
+		}
+	} else if cronFound {
+		if err := r.client.Delete(context.TODO(), foundCron); err != nil && !errors.IsNotFound(err) {
+			return reconcile.Result{}, err
+		}
+	}
+
 	if len(newStatus.Deployments[appsv1.DeploymentAvailable]) > 0 {
 		newStatus.Phase = string(appsv1.DeploymentAvailable)
 		newStatus.Reason = ""
@@ -182,11 +448,54 @@ func (r *ReconcileSonarr) Reconcile(request reconcile.Request) (reconcile.Result
 		newStatus.Phase = string(appsv1.DeploymentReplicaFailure)
 		newStatus.Reason = "Deployment replica failure"
 	}
+	if backupProblem != "" {
+		newStatus.Phase = "Degraded"
+		newStatus.Reason = backupProblem
+	}
+	if degraded := updatePolicyProblem(instance); degraded != "" {
+		newStatus.Phase = "Degraded"
+		newStatus.Reason = degraded
+	}
+	if degraded := r.pinnedImageFailure(instance, &newStatus); degraded != "" {
+		newStatus.Phase = "Degraded"
+		newStatus.Reason = degraded
+	}
+	available := len(newStatus.Deployments[appsv1.DeploymentAvailable]) > 0
+	if degraded := r.checkApplication(instance, &newStatus, available); degraded != "" {
+		newStatus.Phase = "Degraded"
+		newStatus.Reason = degraded
+	}
+	degraded, err := r.reconcileApplication(instance, &newStatus)
+	if err != nil {
+		newStatus.Phase = "Degraded"
+		newStatus.Reason = fmt.Sprintf("Failed to apply Sonarr settings: %v", err)
+		_ = r.updateStatus(newStatus, instance)
+		return reconcile.Result{}, err
+	}
+	if degraded != "" {
+		newStatus.Phase = "Degraded"
+		newStatus.Reason = degraded
+	}
+	if degraded := r.apiKeyProblem(instance); degraded != "" {
+		newStatus.Phase = "Degraded"
+		newStatus.Reason = degraded
+	}
+	if newStatus.PendingImage != "" {
+		newStatus.Phase = "UpdatePending"
+		if nextWindow.IsZero() {
+			newStatus.Reason = "Update window is invalid"
+		} else {
+			newStatus.Reason = fmt.Sprintf("Update waiting for window starting %s", nextWindow.Format(time.RFC3339))
+		}
+	}
 	_ = r.updateStatus(newStatus, instance)
 
-	requeueTime, err := time.ParseDuration(instance.Spec.WatchFrequency)
-	if err != nil {
-		return reconcile.Result{RequeueAfter: time.Second * 60}, nil
+	requeueTime := r.watchFrequency(instance)
+	if untilWindow := time.Until(nextWindow) + time.Second; !nextWindow.IsZero() && untilWindow < requeueTime {
+		requeueTime = untilWindow
+		if requeueTime < time.Second {
+			requeueTime = time.Second
+		}
 	}
 	return reconcile.Result{RequeueAfter: requeueTime}, nil
 }
@@ -203,7 +512,16 @@ func (r *ReconcileSonarr) reconcileSpec(cr *sonarrv1alpha1.Sonarr) error {
 	return nil
 }
 
-func (r *ReconcileSonarr) newDeployment(cr *sonarrv1alpha1.Sonarr) (*appsv1.Deployment, error) {
+// watchFrequency returns the time between polls of cr
+func (r *ReconcileSonarr) watchFrequency(cr *sonarrv1alpha1.Sonarr) time.Duration {
+	frequency, err := time.ParseDuration(cr.Spec.WatchFrequency)
+	if err != nil {
+		return time.Second * 60
+	}
+	return frequency
+}
+
+func (r *ReconcileSonarr) newDeployment(cr *sonarrv1alpha1.Sonarr, image string) (*appsv1.Deployment, error) {
 	labels := r.labelsForCR(cr)
 
 	volumes, volumeMounts, err := r.parseVolumes(cr.Spec.Volumes)
@@ -223,7 +541,6 @@ func (r *ReconcileSonarr) newDeployment(cr *sonarrv1alpha1.Sonarr) (*appsv1.Depl
 			Labels:    labels,
 		},
 		Spec: appsv1.DeploymentSpec{
-			Replicas: &[]int32{1}[0],
 			Selector: &metav1.LabelSelector{
 				MatchLabels: labels,
 			},
@@ -236,23 +553,23 @@ func (r *ReconcileSonarr) newDeployment(cr *sonarrv1alpha1.Sonarr) (*appsv1.Depl
 					Containers: []corev1.Container{
 						{
 							Name:  "sonarr",
-							Image: cr.Spec.Image,
+							Image: image,
 							Ports: []corev1.ContainerPort{
 								{
 									Name:          "http",
-									ContainerPort: 8989,
+									ContainerPort: r.containerPort(cr),
 									Protocol:      corev1.ProtocolTCP,
 								},
 							},
-							Resources:    corev1.ResourceRequirements{},
+							Resources:    r.resources(cr),
 							VolumeMounts: volumeMounts,
 							LivenessProbe: &corev1.Probe{
 								Handler: corev1.Handler{
 									HTTPGet: &corev1.HTTPGetAction{
-										Path: "",
+										Path: r.urlBase(cr),
 										Port: intstr.IntOrString{
 											Type:   intstr.Int,
-											IntVal: 8989,
+											IntVal: r.containerPort(cr),
 											StrVal: "",
 										},
 										Scheme: corev1.URISchemeHTTP,
@@ -262,10 +579,10 @@ func (r *ReconcileSonarr) newDeployment(cr *sonarrv1alpha1.Sonarr) (*appsv1.Depl
 							ReadinessProbe: &corev1.Probe{
 								Handler: corev1.Handler{
 									HTTPGet: &corev1.HTTPGetAction{
-										Path: "",
+										Path: r.urlBase(cr),
 										Port: intstr.IntOrString{
 											Type:   intstr.Int,
-											IntVal: 8989,
+											IntVal: r.containerPort(cr),
 											StrVal: "",
 										},
 										Scheme: corev1.URISchemeHTTP,
@@ -275,10 +592,14 @@ func (r *ReconcileSonarr) newDeployment(cr *sonarrv1alpha1.Sonarr) (*appsv1.Depl
 							ImagePullPolicy: corev1.PullIfNotPresent,
 						},
 					},
-					RestartPolicy:     corev1.RestartPolicyAlways,
-					SecurityContext:   &corev1.PodSecurityContext{},
-					ImagePullSecrets:  imagePullSecrets,
-					PriorityClassName: cr.Spec.PriorityClassName,
+					RestartPolicy:             corev1.RestartPolicyAlways,
+					SecurityContext:           r.podSecurityContext(cr),
+					ImagePullSecrets:          imagePullSecrets,
+					PriorityClassName:         cr.Spec.PriorityClassName,
+					NodeSelector:              cr.Spec.NodeSelector,
+					Affinity:                  cr.Spec.Affinity.DeepCopy(),
+					Tolerations:               cr.Spec.Tolerations,
+					TopologySpreadConstraints: r.topologySpreadConstraints(cr),
 				},
 			},
 			Strategy: appsv1.DeploymentStrategy{
@@ -288,16 +609,9 @@ func (r *ReconcileSonarr) newDeployment(cr *sonarrv1alpha1.Sonarr) (*appsv1.Depl
 		},
 	}
 
-	if cr.Spec.RunAsUser != int64(0) {
-		dep.Spec.Template.Spec.SecurityContext.RunAsUser = &cr.Spec.RunAsUser
-	}
-
-	if cr.Spec.RunAsGroup != int64(0) {
-		dep.Spec.Template.Spec.SecurityContext.RunAsUser = &cr.Spec.RunAsUser
-	}
-
-	if cr.Spec.FSGroup != int64(0) {
-		dep.Spec.Template.Spec.SecurityContext.FSGroup = &cr.Spec.FSGroup
+	err = r.configPodSpec(cr, &dep.Spec.Template)
+	if err != nil {
+		return dep, err
 	}
 
 	err = controllerutil.SetControllerReference(cr, dep, r.scheme)
@@ -307,89 +621,185 @@ func (r *ReconcileSonarr) newDeployment(cr *sonarrv1alpha1.Sonarr) (*appsv1.Depl
 	return dep, nil
 }
 
-func (r *ReconcileSonarr) newService(cr *sonarrv1alpha1.Sonarr) (*corev1.Service, error) {
-	labels := r.labelsForCR(cr)
+// desiredImage returns the image the Deployment should run.  With an update policy the newest tag allowed by the
+// policy is chosen, and the image tag is resolved to the digest currently served by the registry so pods are only
+// restarted when the tag moves.  The registry is checked at most once per watch frequency, and with updates disabled
+// an already resolved digest is kept for as long as the spec does not ask for a different image.
+func (r *ReconcileSonarr) desiredImage(cr *sonarrv1alpha1.Sonarr, status *sonarrv1alpha1.SonarrStatus) (string, error) {
+	if !selectsTags(cr.Spec.UpdatePolicy) || updatePolicyProblem(cr) != "" {
+		status.AvailableVersion = ""
+	}
+	if image_inspect.HasDigest(cr.Spec.Image) {
+		return cr.Spec.Image, nil
+	}
 
-	dep := &corev1.Service{
-		ObjectMeta: metav1.ObjectMeta{
-			Name:      cr.Name,
-			Namespace: cr.Namespace,
-			Labels:    labels,
-		},
-		Spec: corev1.ServiceSpec{
-			Ports: []corev1.ServicePort{
-				{
-					Name:     "http",
-					Protocol: corev1.ProtocolTCP,
-					Port:     8989,
-					TargetPort: intstr.IntOrString{
-						Type:   intstr.Int,
-						IntVal: 8989,
-						StrVal: "",
-					},
-				},
-			},
-			Selector: labels,
-		},
+	current := cr.Status.Image
+	resolved := image_inspect.HasDigest(current) && r.imageFromSpec(cr, image_inspect.TrimDigest(current))
+	if resolved && cr.Spec.DisableUpdates {
+		return current, nil
+	}
+	if resolved && !r.imageCheckDue(cr, time.Now()) {
+		// The image found by the last check may still be waiting for the update window
+		pending := cr.Status.PendingImage
+		if image_inspect.HasDigest(pending) && r.imageFromSpec(cr, image_inspect.TrimDigest(pending)) {
+			return pending, nil
+		}
+		return current, nil
+	}
+	fallback := cr.Spec.Image
+	if resolved {
+		fallback = current
 	}
 
-	err := controllerutil.SetControllerReference(cr, dep, r.scheme)
+	auth, err := r.registryAuth(cr, cr.Spec.Image)
 	if err != nil {
-		return dep, err
+		return fallback, err
 	}
 
-	return dep, nil
-}
+	ctx, cancel := context.WithTimeout(context.TODO(), registryTimeout)
+	defer cancel()
+	status.ImageCheckTime = &metav1.Time{Time: time.Now()}
 
-func (r *ReconcileSonarr) reconcileDeployment(f *appsv1.Deployment, p *appsv1.Deployment) error {
-	if !reflect.DeepEqual(f.Spec.Template.Spec.Volumes, p.Spec.Template.Spec.Volumes) || !reflect.DeepEqual(f.Spec.Template.Spec.Containers[0].VolumeMounts, p.Spec.Template.Spec.Containers[0].VolumeMounts) {
-		f.Spec.Template.Spec.Volumes = p.Spec.Template.Spec.Volumes
-		f.Spec.Template.Spec.Containers[0].VolumeMounts = p.Spec.Template.Spec.Containers[0].VolumeMounts
-		return fmt.Errorf("deployment volumes/volumemounts mismatch")
+	target := cr.Spec.Image
+	if selectsTags(cr.Spec.UpdatePolicy) && !cr.Spec.DisableUpdates && updatePolicyProblem(cr) == "" {
+		policy, err := newUpdatePolicy(cr.Spec.UpdatePolicy, cr.Spec.Image)
+		if err != nil {
+			return fallback, err
+		}
+		tags, err := r.imageInspector.GetTags(ctx, cr.Spec.Image, auth)
+		if err != nil {
+			return fallback, err
+		}
+		tag, available := policy.latest(image_inspect.Tag(cr.Spec.Image), tags)
+		status.AvailableVersion = available.Original()
+		target = image_inspect.WithTag(cr.Spec.Image, tag)
 	}
 
-	if f.Spec.Template.Spec.PriorityClassName != p.Spec.Template.Spec.PriorityClassName {
-		f.Spec.Template.Spec.PriorityClassName = p.Spec.Template.Spec.PriorityClassName
-		return fmt.Errorf("priority class name mismatch")
+	digest, err := r.imageInspector.GetDigest(ctx, target, auth)
+	if err != nil {
+		return fallback, err
+	}
+	image, err := image_inspect.PinDigest(target, digest)
+	if err != nil {
+		return fallback, err
 	}
+	return image, nil
+}
+
+// imageCheckDue reports whether the registry should be checked for a new image of cr at now, which happens once
+// per watch frequency rather than on every reconcile
+func (r *ReconcileSonarr) imageCheckDue(cr *sonarrv1alpha1.Sonarr, now time.Time) bool {
+	last := cr.Status.ImageCheckTime
+	return last == nil || now.Sub(last.Time) >= r.watchFrequency(cr)
+}
 
-	if !reflect.DeepEqual(f.Spec.Template.Spec.SecurityContext.RunAsUser, p.Spec.Template.Spec.SecurityContext.RunAsUser) {
-		f.Spec.Template.Spec.SecurityContext.RunAsUser = p.Spec.Template.Spec.SecurityContext.RunAsUser
-		return fmt.Errorf("user mismatch")
+// imageFromSpec reports whether image (without digest) satisfies the spec: it is the spec image itself or, with an
+// update policy, a tag of the same repository allowed by the policy
+func (r *ReconcileSonarr) imageFromSpec(cr *sonarrv1alpha1.Sonarr, image string) bool {
+	if image == cr.Spec.Image {
+		return true
+	}
+	if cr.Spec.UpdatePolicy == nil || image_inspect.Repository(image) != image_inspect.Repository(cr.Spec.Image) {
+		return false
+	}
+	policy, err := newUpdatePolicy(cr.Spec.UpdatePolicy, cr.Spec.Image)
+	if err != nil {
+		return false
 	}
+	v, ok := policy.version(image_inspect.Tag(image))
+	return ok && policy.allows(v)
+}
 
-	if !reflect.DeepEqual(f.Spec.Template.Spec.SecurityContext.RunAsGroup, p.Spec.Template.Spec.SecurityContext.RunAsGroup) {
-		f.Spec.Template.Spec.SecurityContext.RunAsGroup = p.Spec.Template.Spec.SecurityContext.RunAsGroup
-		return fmt.Errorf("group mismatch")
+// imageVersion returns the version of the image tag as understood by the update policy
+func (r *ReconcileSonarr) imageVersion(cr *sonarrv1alpha1.Sonarr, image string) string {
+	if cr.Spec.UpdatePolicy == nil {
+		return ""
+	}
+	policy, err := newUpdatePolicy(cr.Spec.UpdatePolicy, cr.Spec.Image)
+	if err != nil {
+		return ""
+	}
+	if v, ok := policy.version(image_inspect.Tag(image)); ok {
+		return v.Original()
 	}
+	return ""
+}
+
+// registryAuth returns the credentials for image held by the image pull secrets of the CR, so registry lookups
+// are authorized the same way as the pods pulling the image.  Like the kubelet, secrets that are missing or hold
+// no docker config are ignored, so an unrelated pull secret cannot block updates.
+func (r *ReconcileSonarr) registryAuth(cr *sonarrv1alpha1.Sonarr, image string) (*imagetypes.DockerAuthConfig, error) {
+	reqLogger := log.WithValues("Request.Namespace", cr.Namespace, "Request.Name", cr.Name)
+	var secrets []corev1.Secret
+	for _, name := range cr.Spec.ImagePullSecrets {
+		secret := corev1.Secret{}
+		err := r.client.Get(context.TODO(), client.ObjectKey{Namespace: cr.Namespace, Name: name}, &secret)
+		if errors.IsNotFound(err) {
+			reqLogger.Info("Ignoring missing image pull secret", "Secret", name)
+			continue
+		} else if err != nil {
+			return nil, err
+		}
+		if _, err := image_inspect.AuthFromSecrets(image, []corev1.Secret{secret}); err != nil {
+			reqLogger.Info("Ignoring unusable image pull secret", "Secret", name, "Error", err.Error())
+			continue
+		}
+		secrets = append(secrets, secret)
+	}
+	return image_inspect.AuthFromSecrets(image, secrets)
+}
+
+// reconcileDeployment returns the patch taking the Deployment f to p, or nil when it is up to date
+func (r *ReconcileSonarr) reconcileDeployment(f *appsv1.Deployment, p *appsv1.Deployment) (client.Patch, error) {
+	return mergePatch(f, p)
+}
 
-	if !reflect.DeepEqual(f.Spec.Template.Spec.SecurityContext.FSGroup, p.Spec.Template.Spec.SecurityContext.FSGroup) {
-		f.Spec.Template.Spec.SecurityContext.FSGroup = p.Spec.Template.Spec.SecurityContext.FSGroup
-		return fmt.Errorf("filesystem group mismatch")
+// topologySpreadConstraints returns the topology spread constraints of cr, selecting the Sonarr pods when a
+// constraint has no label selector, since the scheduler counts no pods without one
+func (r *ReconcileSonarr) topologySpreadConstraints(cr *sonarrv1alpha1.Sonarr) []corev1.TopologySpreadConstraint {
+	var constraints []corev1.TopologySpreadConstraint
+	for _, c := range cr.Spec.TopologySpreadConstraints {
+		if c.LabelSelector == nil {
+			c.LabelSelector = &metav1.LabelSelector{MatchLabels: r.labelsForCR(cr)}
+		}
+		constraints = append(constraints, c)
 	}
+	return constraints
+}
 
-	if f.Spec.Template.Spec.Containers[0].Image != p.Spec.Template.Spec.Containers[0].Image {
-		f.Spec.Template.Spec.Containers[0].Image = p.Spec.Template.Spec.Containers[0].Image
-		return fmt.Errorf("image mismatch")
+// resources returns the compute resources of the Sonarr container, defaulting to requests fitting a small library
+// and a memory limit protecting the node
+func (r *ReconcileSonarr) resources(cr *sonarrv1alpha1.Sonarr) corev1.ResourceRequirements {
+	if cr.Spec.Resources != nil {
+		return *cr.Spec.Resources
 	}
+	return corev1.ResourceRequirements{
+		Requests: corev1.ResourceList{
+			corev1.ResourceCPU:    resource.MustParse(defaults.SonarrCPURequest),
+			corev1.ResourceMemory: resource.MustParse(defaults.SonarrMemoryRequest),
+		},
+		Limits: corev1.ResourceList{
+			corev1.ResourceMemory: resource.MustParse(defaults.SonarrMemoryLimit),
+		},
+	}
+}
+
+func (r *ReconcileSonarr) podSecurityContext(cr *sonarrv1alpha1.Sonarr) *corev1.PodSecurityContext {
+	securityContext := &corev1.PodSecurityContext{}
 
-	if !reflect.DeepEqual(f.Spec.Template.Spec.ImagePullSecrets, p.Spec.Template.Spec.ImagePullSecrets) {
-		f.Spec.Template.Spec.ImagePullSecrets = p.Spec.Template.Spec.ImagePullSecrets
-		return fmt.Errorf("image pull secrets mismatch")
+	if cr.Spec.RunAsUser != int64(0) {
+		securityContext.RunAsUser = &cr.Spec.RunAsUser
 	}
 
-	if !reflect.DeepEqual(f.Labels, p.Labels) || !reflect.DeepEqual(f.Spec.Template.Labels, p.Spec.Template.Labels) || !reflect.DeepEqual(f.Spec.Selector.MatchLabels, p.Spec.Selector.MatchLabels) {
-		f.Labels = p.Labels
-		f.Spec.Template.Labels = p.Spec.Template.Labels
-		f.Spec.Selector.MatchLabels = p.Spec.Selector.MatchLabels
-		return fmt.Errorf("labels mismatch")
+	if cr.Spec.RunAsGroup != int64(0) {
+		securityContext.RunAsUser = &cr.Spec.RunAsUser
 	}
 
-	if *f.Spec.Replicas != *p.Spec.Replicas {
-		f.Spec.Replicas = p.Spec.Replicas
-		return fmt.Errorf("replicas mismatch")
+	if cr.Spec.FSGroup != int64(0) {
+		securityContext.FSGroup = &cr.Spec.FSGroup
 	}
-	return nil
+
+	return securityContext
 }
 
 func (r *ReconcileSonarr) labelsForCR(cr *sonarrv1alpha1.Sonarr) map[string]string {
@@ -441,6 +851,10 @@ func (r *ReconcileSonarr) checkDeploymentStatus(dep *appsv1.Deployment) map[apps
 	}
 
 	for _, c := range dep.Status.Conditions {
+		if c.Type == appsv1.DeploymentProgressing && c.Status == corev1.ConditionFalse && c.Reason == "ProgressDeadlineExceeded" {
+			output[appsv1.DeploymentReplicaFailure] = append(output[appsv1.DeploymentReplicaFailure], dep.Name)
+			continue
+		}
 		if c.Status == corev1.ConditionTrue {
 			if c.Type == appsv1.DeploymentProgressing && strings.Contains(c.Message, "has successfully progressed") {
 				continue
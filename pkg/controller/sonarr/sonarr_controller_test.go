@@ -2,20 +2,120 @@ package sonarr
 
 import (
 	"context"
+	"reflect"
+	"testing"
+
+	"github.com/parflesh/sonarr-operator/pkg/image_inspect"
 	appsv1 "k8s.io/api/apps/v1"
+	batchv1beta1 "k8s.io/api/batch/v1beta1"
 	corev1 "k8s.io/api/core/v1"
-	"testing"
 
 	sonarrv1alpha1 "github.com/parflesh/sonarr-operator/pkg/apis/sonarr/v1alpha1"
 
+	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/record"
 	"sigs.k8s.io/controller-runtime/pkg/client/fake"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 )
 
+const (
+	testName      = "sonarr-operator"
+	testNamespace = "sonarr"
+	testDigest    = "sha256:0000000000000000000000000000000000000000000000000000000000000001"
+	testNewDigest = "sha256:0000000000000000000000000000000000000000000000000000000000000002"
+)
+
+// newTestSonarr returns a Sonarr with spec, defaulting the image and watch frequency so the spec is not updated
+// on the first reconcile
+func newTestSonarr(spec sonarrv1alpha1.SonarrSpec) *sonarrv1alpha1.Sonarr {
+	if spec.Image == "" {
+		spec.Image = "quay.io/parflesh/sonarr:latest"
+	}
+	if spec.WatchFrequency == "" {
+		spec.WatchFrequency = "1m"
+	}
+	return &sonarrv1alpha1.Sonarr{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      testName,
+			Namespace: testNamespace,
+		},
+		Spec: spec,
+	}
+}
+
+// newTestReconciler returns a reconciler using a fake client holding cr and objs and a mock image inspector
+// resolving tags to testDigest, and the request reconciling cr
+func newTestReconciler(cr *sonarrv1alpha1.Sonarr, objs ...runtime.Object) (*ReconcileSonarr, reconcile.Request) {
+	s := scheme.Scheme
+	s.AddKnownTypes(sonarrv1alpha1.SchemeGroupVersion, cr)
+	r := &ReconcileSonarr{
+		client:         fake.NewFakeClientWithScheme(s, append([]runtime.Object{cr}, objs...)...),
+		scheme:         s,
+		recorder:       record.NewFakeRecorder(100),
+		imageInspector: &image_inspect.MockImageInspector{GetDigestOutput: testDigest},
+	}
+	req := reconcile.Request{
+		NamespacedName: types.NamespacedName{
+			Name:      cr.Name,
+			Namespace: cr.Namespace,
+		},
+	}
+	return r, req
+}
+
+// reconcileTimes reconciles req n times, failing the test on errors
+func reconcileTimes(t *testing.T, r *ReconcileSonarr, req reconcile.Request, n int) {
+	t.Helper()
+	for i := 0; i < n; i++ {
+		if _, err := r.Reconcile(req); err != nil {
+			t.Fatalf("reconcile: (%v)", err)
+		}
+	}
+}
+
+// getSonarr returns the Sonarr of req as stored by the fake client
+func getSonarr(t *testing.T, r *ReconcileSonarr, req reconcile.Request) *sonarrv1alpha1.Sonarr {
+	t.Helper()
+	cr := &sonarrv1alpha1.Sonarr{}
+	if err := r.client.Get(context.TODO(), req.NamespacedName, cr); err != nil {
+		t.Fatalf("get sonarr: (%v)", err)
+	}
+	return cr
+}
+
+// updateSonarr stores cr with the fake client
+func updateSonarr(t *testing.T, r *ReconcileSonarr, cr *sonarrv1alpha1.Sonarr) {
+	t.Helper()
+	if err := r.client.Update(context.TODO(), cr); err != nil {
+		t.Fatalf("update sonarr: (%v)", err)
+	}
+}
+
+// getDeployment returns the Deployment of req as stored by the fake client
+func getDeployment(t *testing.T, r *ReconcileSonarr, req reconcile.Request) *appsv1.Deployment {
+	t.Helper()
+	dep := &appsv1.Deployment{}
+	if err := r.client.Get(context.TODO(), req.NamespacedName, dep); err != nil {
+		t.Fatalf("get deployment: (%v)", err)
+	}
+	return dep
+}
+
+// expireImageCheck clears the time the registry was last checked for the image of req, so the next reconcile
+// checks again
+func expireImageCheck(t *testing.T, r *ReconcileSonarr, req reconcile.Request) {
+	t.Helper()
+	cr := getSonarr(t, r, req)
+	cr.Status.ImageCheckTime = nil
+	if err := r.client.Status().Update(context.TODO(), cr); err != nil {
+		t.Fatalf("update sonarr status: (%v)", err)
+	}
+}
+
 func TestSonarrController(t *testing.T) {
 	var (
 		name      = "sonarr-operator"
@@ -44,12 +144,10 @@ func TestSonarrController(t *testing.T) {
 	r := &ReconcileSonarr{
 		client: cl,
 		scheme: s,
-		/*imageInspector: &image_inspect.MockImageInspector{
-			GetImageLabelsOutput: &imagetypes.ImageInspectInfo{
-				Tag: "test",
-			},
-			GetImageLabelsError: nil,
-		},*/
+		imageInspector: &image_inspect.MockImageInspector{
+			GetDigestOutput: testDigest,
+			GetDigestError:  nil,
+		},
 	}
 
 	// Mock request to simulate Reconcile() being called on an event for a
@@ -102,9 +200,12 @@ func TestSonarrController(t *testing.T) {
 		t.Error("Deployment not created")
 	}
 	err = r.client.Get(context.TODO(), req.NamespacedName, cr)
-	if cr.Status.Image != cr.Spec.Image {
+	if cr.Status.Image != cr.Spec.Image+"@"+testDigest {
 		t.Error("status image mismatch")
 	}
+	if depDep.Spec.Template.Spec.Containers[0].Image != cr.Status.Image {
+		t.Error("deployment image not pinned to digest")
+	}
 
 	depSvc := &corev1.Service{}
 	res, err = r.Reconcile(req)
@@ -130,3 +231,304 @@ func TestSonarrController(t *testing.T) {
 		t.Error("reconcile requeued even though all should be good")
 	}
 }
+
+func TestSonarrControllerImageUpdates(t *testing.T) {
+	cr := newTestSonarr(sonarrv1alpha1.SonarrSpec{})
+
+	r, req := newTestReconciler(cr)
+	inspector := r.imageInspector.(*image_inspect.MockImageInspector)
+
+	// Create deployment and service
+	reconcileTimes(t, r, req, 2)
+
+	// Tag moved to a new digest, deployment should follow
+	inspector.GetDigestOutput = testNewDigest
+	expireImageCheck(t, r, req)
+	res, err := r.Reconcile(req)
+	if err != nil {
+		t.Fatalf("reconcile: (%v)", err)
+	}
+	if !res.Requeue {
+		t.Error("reconcile did not requeue after digest change")
+	}
+	dep := getDeployment(t, r, req)
+	if dep.Spec.Template.Spec.Containers[0].Image != cr.Spec.Image+"@"+testNewDigest {
+		t.Errorf("deployment image not updated to new digest: %s", dep.Spec.Template.Spec.Containers[0].Image)
+	}
+
+	// Updates disabled, digest change should be ignored
+	cr = getSonarr(t, r, req)
+	cr.Spec.DisableUpdates = true
+	updateSonarr(t, r, cr)
+	inspector.GetDigestOutput = testDigest
+	expireImageCheck(t, r, req)
+	reconcileTimes(t, r, req, 1)
+	dep = getDeployment(t, r, req)
+	if dep.Spec.Template.Spec.Containers[0].Image != cr.Spec.Image+"@"+testNewDigest {
+		t.Errorf("deployment image updated while updates disabled: %s", dep.Spec.Template.Spec.Containers[0].Image)
+	}
+	cr = getSonarr(t, r, req)
+	if cr.Status.Image != cr.Spec.Image+"@"+testNewDigest {
+		t.Errorf("status image changed while updates disabled: %s", cr.Status.Image)
+	}
+}
+
+func TestSonarrControllerImageCheckInterval(t *testing.T) {
+	cr := newTestSonarr(sonarrv1alpha1.SonarrSpec{
+		Image:          "quay.io/parflesh/sonarr:3.0.3",
+		WatchFrequency: "1h",
+		UpdatePolicy: &sonarrv1alpha1.SonarrSpecUpdatePolicy{
+			Strategy: sonarrv1alpha1.UpdateStrategyPatch,
+		},
+	})
+	r, req := newTestReconciler(cr)
+	inspector := r.imageInspector.(*image_inspect.MockImageInspector)
+	inspector.GetTagsOutput = []string{"3.0.3", "3.0.4"}
+
+	reconcileTimes(t, r, req, 3)
+	if cr = getSonarr(t, r, req); cr.Status.ImageCheckTime == nil {
+		t.Fatal("image check time not recorded")
+	}
+
+	// Reconciles within the watch frequency do not query the registry
+	calls := inspector.Calls
+	reconcileTimes(t, r, req, 3)
+	if inspector.Calls != calls {
+		t.Errorf("registry queried %d times within the watch frequency", inspector.Calls-calls)
+	}
+
+	// Once due, the tags are listed and the digest resolved
+	expireImageCheck(t, r, req)
+	reconcileTimes(t, r, req, 1)
+	if inspector.Calls != calls+2 {
+		t.Errorf("unexpected registry calls for due check: %d", inspector.Calls-calls)
+	}
+
+	// With updates disabled the resolved image is kept without asking the registry
+	cr = getSonarr(t, r, req)
+	cr.Spec.DisableUpdates = true
+	updateSonarr(t, r, cr)
+	expireImageCheck(t, r, req)
+	calls = inspector.Calls
+	reconcileTimes(t, r, req, 1)
+	if inspector.Calls != calls {
+		t.Errorf("registry queried %d times with updates disabled", inspector.Calls-calls)
+	}
+}
+
+func TestSonarrControllerRegistryAuth(t *testing.T) {
+	cr := newTestSonarr(sonarrv1alpha1.SonarrSpec{
+		Image:            "registry.example.com/parflesh/sonarr:latest",
+		ImagePullSecrets: []string{"unrelated", "mirror"},
+	})
+	unrelated := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "unrelated",
+			Namespace: testNamespace,
+		},
+		Type: corev1.SecretTypeOpaque,
+		Data: map[string][]byte{"token": []byte("secret")},
+	}
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "mirror",
+			Namespace: testNamespace,
+		},
+		Type: corev1.SecretTypeDockerConfigJson,
+		Data: map[string][]byte{
+			corev1.DockerConfigJsonKey: []byte(`{"auths":{"registry.example.com":{"username":"user","password":"pass"}}}`),
+		},
+	}
+
+	r, req := newTestReconciler(cr, unrelated)
+	inspector := r.imageInspector.(*image_inspect.MockImageInspector)
+
+	// Like the kubelet, missing pull secrets and secrets without a docker config are ignored
+	reconcileTimes(t, r, req, 1)
+	if inspector.Auth != nil {
+		t.Errorf("unexpected credentials without usable pull secret: %v", inspector.Auth)
+	}
+	dep := getDeployment(t, r, req)
+	if dep.Spec.Template.Spec.Containers[0].Image != cr.Spec.Image+"@"+testDigest {
+		t.Errorf("deployment image not pinned without pull secret: %s", dep.Spec.Template.Spec.Containers[0].Image)
+	}
+
+	if err := r.client.Create(context.TODO(), secret); err != nil {
+		t.Fatalf("create secret: (%v)", err)
+	}
+	expireImageCheck(t, r, req)
+	reconcileTimes(t, r, req, 1)
+	if inspector.Auth == nil || inspector.Auth.Username != "user" || inspector.Auth.Password != "pass" {
+		t.Errorf("registry lookup did not use pull secret credentials: %v", inspector.Auth)
+	}
+}
+
+func TestSonarrControllerDeploymentDrift(t *testing.T) {
+	cr := newTestSonarr(sonarrv1alpha1.SonarrSpec{
+		PriorityClassName: "media",
+	})
+
+	r, req := newTestReconciler(cr)
+
+	reconcileTimes(t, r, req, 3)
+	dep := getDeployment(t, r, req)
+	dep.Spec.Template.Spec.PriorityClassName = ""
+	dep.Spec.Template.Spec.Containers[0].Image = "quay.io/parflesh/sonarr:old"
+	dep.Spec.Template.Spec.Containers[0].ImagePullPolicy = corev1.PullAlways
+	dep.Spec.Template.Spec.Containers[0].Env = []corev1.EnvVar{{Name: "TZ", Value: "Europe/Berlin"}}
+	dep.Spec.Template.Annotations = map[string]string{"kubectl.kubernetes.io/restartedAt": "2020-03-01T00:00:00Z"}
+	if err := r.client.Update(context.TODO(), dep); err != nil {
+		t.Fatalf("update deployment: (%v)", err)
+	}
+
+	// Every drifted field converges in a single pass, fields set by others are kept
+	reconcileTimes(t, r, req, 1)
+	dep = getDeployment(t, r, req)
+	pod := dep.Spec.Template.Spec
+	if pod.PriorityClassName != "media" || pod.Containers[0].Image != "quay.io/parflesh/sonarr:latest@"+testDigest {
+		t.Errorf("deployment drift not corrected: %v", pod)
+	}
+	if pod.Containers[0].ImagePullPolicy != corev1.PullIfNotPresent {
+		t.Errorf("image pull policy not corrected: %s", pod.Containers[0].ImagePullPolicy)
+	}
+	if dep.Spec.Template.Annotations["kubectl.kubernetes.io/restartedAt"] == "" || len(pod.Containers[0].Env) != 1 {
+		t.Errorf("fields set by others removed: %v %v", dep.Spec.Template.Annotations, pod.Containers[0].Env)
+	}
+
+	res, err := r.Reconcile(req)
+	if err != nil {
+		t.Fatalf("reconcile: (%v)", err)
+	}
+	if res.Requeue {
+		t.Error("reconcile requeued even though all should be good")
+	}
+}
+
+func TestSonarrControllerResources(t *testing.T) {
+	cr := newTestSonarr(sonarrv1alpha1.SonarrSpec{})
+
+	r, req := newTestReconciler(cr)
+	getResources := func() corev1.ResourceRequirements {
+		return getDeployment(t, r, req).Spec.Template.Spec.Containers[0].Resources
+	}
+
+	reconcileTimes(t, r, req, 3)
+	resources := getResources()
+	if resources.Requests.Memory().String() != "512Mi" || resources.Requests.Cpu().String() != "100m" || resources.Limits.Memory().String() != "2Gi" {
+		t.Errorf("default resources not set: %v", resources)
+	}
+
+	// Resources of the spec replace the defaults
+	cr = getSonarr(t, r, req)
+	cr.Spec.Resources = &corev1.ResourceRequirements{
+		Requests: corev1.ResourceList{corev1.ResourceMemory: resource.MustParse("1Gi")},
+	}
+	updateSonarr(t, r, cr)
+	reconcileTimes(t, r, req, 1)
+	resources = getResources()
+	if resources.Requests.Memory().String() != "1Gi" || len(resources.Requests) != 1 || len(resources.Limits) != 0 {
+		t.Errorf("spec resources not applied: %v", resources)
+	}
+
+	// Resources changed on the Deployment are restored
+	dep := getDeployment(t, r, req)
+	dep.Spec.Template.Spec.Containers[0].Resources.Requests = corev1.ResourceList{corev1.ResourceMemory: resource.MustParse("128Mi")}
+	if err := r.client.Update(context.TODO(), dep); err != nil {
+		t.Fatalf("update deployment: (%v)", err)
+	}
+	reconcileTimes(t, r, req, 1)
+	if resources = getResources(); resources.Requests.Memory().String() != "1Gi" {
+		t.Errorf("resource drift not corrected: %v", resources)
+	}
+}
+
+func TestSonarrControllerScheduling(t *testing.T) {
+	cr := newTestSonarr(sonarrv1alpha1.SonarrSpec{
+		NodeSelector: map[string]string{"storage": "nas"},
+		Affinity: &corev1.Affinity{
+			NodeAffinity: &corev1.NodeAffinity{
+				RequiredDuringSchedulingIgnoredDuringExecution: &corev1.NodeSelector{
+					NodeSelectorTerms: []corev1.NodeSelectorTerm{
+						{MatchExpressions: []corev1.NodeSelectorRequirement{
+							{Key: corev1.LabelHostname, Operator: corev1.NodeSelectorOpIn, Values: []string{"nas-1"}},
+						}},
+					},
+				},
+			},
+		},
+		Tolerations: []corev1.Toleration{
+			{Key: "dedicated", Operator: corev1.TolerationOpEqual, Value: "media", Effect: corev1.TaintEffectNoSchedule},
+		},
+		TopologySpreadConstraints: []corev1.TopologySpreadConstraint{
+			{MaxSkew: 1, TopologyKey: corev1.LabelZoneFailureDomain, WhenUnsatisfiable: corev1.ScheduleAnyway},
+			{MaxSkew: 1, TopologyKey: corev1.LabelHostname, WhenUnsatisfiable: corev1.DoNotSchedule, LabelSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"app": "media"}}},
+		},
+		Volumes: []sonarrv1alpha1.SonarrSpecVolume{
+			{Name: "config", Claim: "sonarr-config", MountPath: "/config"},
+		},
+		Backup: &sonarrv1alpha1.SonarrSpecBackup{
+			Schedule: "0 4 * * *",
+			Claim:    "sonarr-backup",
+		},
+	})
+
+	r, req := newTestReconciler(cr)
+	getPod := func() corev1.PodSpec {
+		return getDeployment(t, r, req).Spec.Template.Spec
+	}
+
+	reconcileTimes(t, r, req, 3)
+	pod := getPod()
+	if pod.NodeSelector["storage"] != "nas" || !reflect.DeepEqual(pod.Affinity, cr.Spec.Affinity) || !reflect.DeepEqual(pod.Tolerations, cr.Spec.Tolerations) {
+		t.Errorf("scheduling not applied to deployment: %v", pod)
+	}
+	// Topology spread constraints without a label selector select the Sonarr pods
+	if len(pod.TopologySpreadConstraints) != 2 || !reflect.DeepEqual(pod.TopologySpreadConstraints[1], cr.Spec.TopologySpreadConstraints[1]) || pod.TopologySpreadConstraints[0].TopologyKey != corev1.LabelZoneFailureDomain ||
+		pod.TopologySpreadConstraints[0].LabelSelector == nil || !reflect.DeepEqual(pod.TopologySpreadConstraints[0].LabelSelector.MatchLabels, map[string]string{"sonarr": testName}) {
+		t.Errorf("unexpected topology spread constraints: %v", pod.TopologySpreadConstraints)
+	}
+
+	// Backup pods are placed like Sonarr and prefer its node
+	cron := &batchv1beta1.CronJob{}
+	if err := r.client.Get(context.TODO(), types.NamespacedName{Name: testName + "-backup", Namespace: testNamespace}, cron); err != nil {
+		t.Fatalf("get backup cronjob: (%v)", err)
+	}
+	backupPod := cron.Spec.JobTemplate.Spec.Template.Spec
+	if backupPod.NodeSelector["storage"] != "nas" || !reflect.DeepEqual(backupPod.Tolerations, cr.Spec.Tolerations) || !reflect.DeepEqual(backupPod.Affinity.NodeAffinity, cr.Spec.Affinity.NodeAffinity) {
+		t.Errorf("scheduling not applied to backup cronjob: %v", backupPod)
+	}
+	if backupPod.Affinity.PodAffinity == nil || len(backupPod.Affinity.PodAffinity.PreferredDuringSchedulingIgnoredDuringExecution) != 1 {
+		t.Errorf("backup pods do not prefer the sonarr node: %v", backupPod.Affinity)
+	}
+
+	// Scheduling changed on the Deployment is restored
+	dep := getDeployment(t, r, req)
+	dep.Spec.Template.Spec.NodeSelector = map[string]string{"storage": "local"}
+	dep.Spec.Template.Spec.Tolerations = nil
+	if err := r.client.Update(context.TODO(), dep); err != nil {
+		t.Fatalf("update deployment: (%v)", err)
+	}
+	reconcileTimes(t, r, req, 1)
+	if pod = getPod(); pod.NodeSelector["storage"] != "nas" || len(pod.NodeSelector) != 1 || !reflect.DeepEqual(pod.Tolerations, cr.Spec.Tolerations) {
+		t.Errorf("scheduling drift not corrected: %v", pod)
+	}
+
+	// Removing scheduling from the spec removes it from the Deployment and backup pods
+	cr = getSonarr(t, r, req)
+	cr.Spec.NodeSelector = nil
+	cr.Spec.Affinity = nil
+	cr.Spec.Tolerations = nil
+	cr.Spec.TopologySpreadConstraints = nil
+	updateSonarr(t, r, cr)
+	reconcileTimes(t, r, req, 3)
+	if pod = getPod(); len(pod.NodeSelector) != 0 || pod.Affinity != nil || len(pod.Tolerations) != 0 || len(pod.TopologySpreadConstraints) != 0 {
+		t.Errorf("scheduling not removed from deployment: %v", pod)
+	}
+	cron = &batchv1beta1.CronJob{}
+	if err := r.client.Get(context.TODO(), types.NamespacedName{Name: testName + "-backup", Namespace: testNamespace}, cron); err != nil {
+		t.Fatalf("get backup cronjob: (%v)", err)
+	}
+	if backupPod = cron.Spec.JobTemplate.Spec.Template.Spec; len(backupPod.NodeSelector) != 0 || backupPod.Affinity.NodeAffinity != nil {
+		t.Errorf("scheduling not removed from backup cronjob: %v", backupPod)
+	}
+}
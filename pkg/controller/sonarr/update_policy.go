@@ -0,0 +1,166 @@
+package sonarr
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+
+	"github.com/Masterminds/semver/v3"
+	sonarrv1alpha1 "github.com/parflesh/sonarr-operator/pkg/apis/sonarr/v1alpha1"
+	"github.com/parflesh/sonarr-operator/pkg/image_inspect"
+)
+
+// sonarrBuild matches the fourth, build number of Sonarr versions like 3.0.10.1567, which semantic versions lack
+var sonarrBuild = regexp.MustCompile(`^(v?\d+\.\d+\.\d+)\.(\d+)(.*)$`)
+
+// tagVersion is a semantic version with the optional build number of Sonarr versions, which orders versions equal
+// otherwise
+type tagVersion struct {
+	*semver.Version
+	build    uint64
+	original string
+}
+
+// parseTagVersion returns the version held by s, with or without a build number
+func parseTagVersion(s string) (*tagVersion, error) {
+	v := &tagVersion{original: s}
+	if match := sonarrBuild.FindStringSubmatch(s); match != nil {
+		build, err := strconv.ParseUint(match[2], 10, 64)
+		if err != nil {
+			return nil, err
+		}
+		v.build = build
+		s = match[1] + match[3]
+	}
+	sv, err := semver.NewVersion(s)
+	if err != nil {
+		return nil, err
+	}
+	v.Version = sv
+	return v, nil
+}
+
+// Original returns the version as it was parsed
+func (v *tagVersion) Original() string {
+	return v.original
+}
+
+// compare returns -1, 0 or 1 when v is lower than, equal to or greater than o
+func (v *tagVersion) compare(o *tagVersion) int {
+	if c := v.Version.Compare(o.Version); c != 0 {
+		return c
+	}
+	switch {
+	case v.build < o.build:
+		return -1
+	case v.build > o.build:
+		return 1
+	}
+	return 0
+}
+
+// updatePolicy selects image tags according to a SonarrSpecUpdatePolicy, relative to the version of the image tag
+// in the spec
+type updatePolicy struct {
+	strategy string
+	pattern  *regexp.Regexp
+	baseline *tagVersion
+}
+
+// selectsTags reports whether p moves the image to other tags than the one in the spec, which requires listing the
+// tags of the registry
+func selectsTags(p *sonarrv1alpha1.SonarrSpecUpdatePolicy) bool {
+	return p != nil && p.Strategy != "" && p.Strategy != sonarrv1alpha1.UpdateStrategyNone
+}
+
+// updatePolicyProblem returns why the update policy of cr cannot select tags, like an image tag in the spec holding
+// no version to update from, or an empty string when it can or selects no tags.  Sonarr then runs the spec image.
+func updatePolicyProblem(cr *sonarrv1alpha1.Sonarr) string {
+	if !selectsTags(cr.Spec.UpdatePolicy) || image_inspect.HasDigest(cr.Spec.Image) {
+		return ""
+	}
+	if _, err := newUpdatePolicy(cr.Spec.UpdatePolicy, cr.Spec.Image); err != nil {
+		return fmt.Sprintf("Update policy %s not applied: %v", cr.Spec.UpdatePolicy.Strategy, err)
+	}
+	return ""
+}
+
+func newUpdatePolicy(p *sonarrv1alpha1.SonarrSpecUpdatePolicy, image string) (*updatePolicy, error) {
+	policy := &updatePolicy{strategy: p.Strategy}
+	if policy.strategy == "" {
+		policy.strategy = sonarrv1alpha1.UpdateStrategyNone
+	}
+
+	if p.TagPattern != "" {
+		pattern, err := regexp.Compile(p.TagPattern)
+		if err != nil {
+			return nil, err
+		}
+		policy.pattern = pattern
+	}
+
+	tag := image_inspect.Tag(image)
+	baseline, ok := policy.version(tag)
+	if !ok {
+		return nil, fmt.Errorf("image tag %q does not hold a version", tag)
+	}
+	policy.baseline = baseline
+	return policy, nil
+}
+
+// version returns the version held by tag, if the tag is selected by the policy
+func (p *updatePolicy) version(tag string) (*tagVersion, bool) {
+	if p.pattern != nil {
+		match := p.pattern.FindStringSubmatch(tag)
+		if match == nil {
+			return nil, false
+		}
+		if len(match) > 1 {
+			tag = match[1]
+		}
+	}
+
+	v, err := parseTagVersion(tag)
+	if err != nil {
+		return nil, false
+	}
+	return v, true
+}
+
+// allows reports whether the policy permits automatically moving to version v
+func (p *updatePolicy) allows(v *tagVersion) bool {
+	if v.compare(p.baseline) < 0 || (v.Prerelease() != "" && p.baseline.Prerelease() == "") {
+		return false
+	}
+
+	switch p.strategy {
+	case sonarrv1alpha1.UpdateStrategyMajor:
+		return true
+	case sonarrv1alpha1.UpdateStrategyMinor:
+		return v.Major() == p.baseline.Major()
+	case sonarrv1alpha1.UpdateStrategyPatch:
+		return v.Major() == p.baseline.Major() && v.Minor() == p.baseline.Minor()
+	}
+	return v.compare(p.baseline) == 0
+}
+
+// latest returns the tag with the highest version allowed by the policy, falling back to baseline tag, and the
+// highest version found in tags regardless of the strategy
+func (p *updatePolicy) latest(baseline string, tags []string) (string, *tagVersion) {
+	selected, selectedVersion := baseline, p.baseline
+	available := p.baseline
+
+	for _, tag := range tags {
+		v, ok := p.version(tag)
+		if !ok || (v.Prerelease() != "" && p.baseline.Prerelease() == "") {
+			continue
+		}
+		if v.compare(available) > 0 {
+			available = v
+		}
+		if p.allows(v) && v.compare(selectedVersion) > 0 {
+			selected, selectedVersion = tag, v
+		}
+	}
+	return selected, available
+}
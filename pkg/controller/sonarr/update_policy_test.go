@@ -0,0 +1,152 @@
+package sonarr
+
+import (
+	"testing"
+
+	sonarrv1alpha1 "github.com/parflesh/sonarr-operator/pkg/apis/sonarr/v1alpha1"
+	"github.com/parflesh/sonarr-operator/pkg/image_inspect"
+)
+
+func TestUpdatePolicyLatest(t *testing.T) {
+	tags := []string{"latest", "develop", "2.0.0.5344", "3.0.1", "3.0.3", "3.0.4", "3.1.0", "3.1.2", "3.2.0-beta1", "4.0.0", "v4.1.0"}
+	buildTags := []string{"latest", "3.0.10.1567", "3.0.10.1620", "3.0.10.1567-ls123", "3.0.10.1620-ls124"}
+
+	tests := []struct {
+		name      string
+		image     string
+		policy    sonarrv1alpha1.SonarrSpecUpdatePolicy
+		tags      []string
+		tag       string
+		available string
+		err       bool
+	}{
+		{name: "default none", image: "sonarr:3.0.3", tag: "3.0.3", available: "v4.1.0"},
+		{name: "patch", image: "sonarr:3.0.3", policy: sonarrv1alpha1.SonarrSpecUpdatePolicy{Strategy: "patch"}, tag: "3.0.4", available: "v4.1.0"},
+		{name: "minor", image: "sonarr:3.0.3", policy: sonarrv1alpha1.SonarrSpecUpdatePolicy{Strategy: "minor"}, tag: "3.1.2", available: "v4.1.0"},
+		{name: "major", image: "sonarr:3.0.3", policy: sonarrv1alpha1.SonarrSpecUpdatePolicy{Strategy: "major"}, tag: "v4.1.0", available: "v4.1.0"},
+		{name: "no downgrade", image: "sonarr:3.1.0", policy: sonarrv1alpha1.SonarrSpecUpdatePolicy{Strategy: "patch"}, tag: "3.1.2", available: "v4.1.0"},
+		{
+			name:      "pattern",
+			image:     "sonarr:3.0.1",
+			policy:    sonarrv1alpha1.SonarrSpecUpdatePolicy{Strategy: "major", TagPattern: `^\d+\.\d+\.\d+$`},
+			tag:       "4.0.0",
+			available: "4.0.0",
+		},
+		{
+			name:      "pattern capture group",
+			image:     "sonarr:1.0.0.100",
+			policy:    sonarrv1alpha1.SonarrSpecUpdatePolicy{Strategy: "major", TagPattern: `^(\d+\.\d+\.\d+)\.\d+$`},
+			tag:       "2.0.0.5344",
+			available: "2.0.0",
+		},
+		{
+			name:      "build number",
+			image:     "sonarr:3.0.10.1567",
+			policy:    sonarrv1alpha1.SonarrSpecUpdatePolicy{Strategy: "patch", TagPattern: `^\d+\.\d+\.\d+\.\d+$`},
+			tags:      buildTags,
+			tag:       "3.0.10.1620",
+			available: "3.0.10.1620",
+		},
+		{
+			name:      "build number suffix",
+			image:     "sonarr:3.0.10.1567-ls123",
+			policy:    sonarrv1alpha1.SonarrSpecUpdatePolicy{Strategy: "patch", TagPattern: `-ls\d+$`},
+			tags:      buildTags,
+			tag:       "3.0.10.1620-ls124",
+			available: "3.0.10.1620-ls124",
+		},
+		{name: "tag without version", image: "sonarr:latest", policy: sonarrv1alpha1.SonarrSpecUpdatePolicy{Strategy: "patch"}, err: true},
+		{name: "invalid pattern", image: "sonarr:3.0.3", policy: sonarrv1alpha1.SonarrSpecUpdatePolicy{TagPattern: "("}, err: true},
+	}
+
+	for _, test := range tests {
+		policy, err := newUpdatePolicy(&test.policy, test.image)
+		if test.err {
+			if err == nil {
+				t.Errorf("%s: expected error", test.name)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: (%v)", test.name, err)
+			continue
+		}
+
+		if test.tags == nil {
+			test.tags = tags
+		}
+		tag, available := policy.latest("baseline", test.tags)
+		if test.tag == test.image[len("sonarr:"):] {
+			test.tag = "baseline"
+		}
+		if tag != test.tag {
+			t.Errorf("%s: tag %s, expected %s", test.name, tag, test.tag)
+		}
+		if available.Original() != test.available {
+			t.Errorf("%s: available %s, expected %s", test.name, available.Original(), test.available)
+		}
+	}
+}
+
+func TestSonarrControllerUpdatePolicy(t *testing.T) {
+	cr := newTestSonarr(sonarrv1alpha1.SonarrSpec{
+		Image: "quay.io/parflesh/sonarr:3.0.3",
+		UpdatePolicy: &sonarrv1alpha1.SonarrSpecUpdatePolicy{
+			Strategy: sonarrv1alpha1.UpdateStrategyPatch,
+		},
+	})
+
+	r, req := newTestReconciler(cr)
+	inspector := &image_inspect.MockImageInspector{
+		GetDigestOutput: testDigest,
+		GetTagsOutput:   []string{"latest", "3.0.3", "3.0.4", "3.1.0"},
+	}
+	r.imageInspector = inspector
+
+	reconcileTimes(t, r, req, 3)
+
+	dep := getDeployment(t, r, req)
+	if dep.Spec.Template.Spec.Containers[0].Image != "quay.io/parflesh/sonarr:3.0.4@"+testDigest {
+		t.Errorf("deployment not updated to newest patch release: %s", dep.Spec.Template.Spec.Containers[0].Image)
+	}
+	cr = getSonarr(t, r, req)
+	if cr.Status.CurrentVersion != "3.0.4" || cr.Status.AvailableVersion != "3.1.0" {
+		t.Errorf("unexpected versions: current %s, available %s", cr.Status.CurrentVersion, cr.Status.AvailableVersion)
+	}
+
+	// With updates disabled a newer patch release is only reported
+	cr.Spec.DisableUpdates = true
+	updateSonarr(t, r, cr)
+	inspector.GetTagsOutput = append(inspector.GetTagsOutput, "3.0.5")
+	reconcileTimes(t, r, req, 1)
+	dep = getDeployment(t, r, req)
+	if dep.Spec.Template.Spec.Containers[0].Image != "quay.io/parflesh/sonarr:3.0.4@"+testDigest {
+		t.Errorf("deployment updated while updates disabled: %s", dep.Spec.Template.Spec.Containers[0].Image)
+	}
+}
+
+func TestSonarrControllerUpdatePolicyWithoutVersion(t *testing.T) {
+	cr := newTestSonarr(sonarrv1alpha1.SonarrSpec{
+		Image: "quay.io/parflesh/sonarr:latest",
+		UpdatePolicy: &sonarrv1alpha1.SonarrSpecUpdatePolicy{
+			Strategy: sonarrv1alpha1.UpdateStrategyPatch,
+		},
+	})
+
+	r, req := newTestReconciler(cr)
+	r.imageInspector = &image_inspect.MockImageInspector{
+		GetDigestOutput: testDigest,
+		GetTagsOutput:   []string{"latest", "3.0.3"},
+	}
+
+	// The spec image runs and the policy is reported, rather than failing every reconcile
+	reconcileTimes(t, r, req, 3)
+	dep := getDeployment(t, r, req)
+	if dep.Spec.Template.Spec.Containers[0].Image != "quay.io/parflesh/sonarr:latest@"+testDigest {
+		t.Errorf("unexpected image: %s", dep.Spec.Template.Spec.Containers[0].Image)
+	}
+	cr = getSonarr(t, r, req)
+	if cr.Status.Phase != "Degraded" || cr.Status.Reason != `Update policy patch not applied: image tag "latest" does not hold a version` {
+		t.Errorf("update policy problem not reported: %s %s", cr.Status.Phase, cr.Status.Reason)
+	}
+}
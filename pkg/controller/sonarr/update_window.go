@@ -0,0 +1,47 @@
+package sonarr
+
+import (
+	"time"
+
+	"github.com/parflesh/sonarr-operator/defaults"
+	sonarrv1alpha1 "github.com/parflesh/sonarr-operator/pkg/apis/sonarr/v1alpha1"
+	"github.com/robfig/cron/v3"
+)
+
+// updateWindowOpen reports whether image updates may be rolled out at now.  When the window is closed the start
+// of the next window is returned as well.  Without a configured window updates are always allowed.
+func updateWindowOpen(w *sonarrv1alpha1.SonarrSpecUpdateWindow, now time.Time) (bool, time.Time, error) {
+	if w == nil || w.Schedule == "" {
+		return true, time.Time{}, nil
+	}
+
+	schedule, err := cron.ParseStandard(w.Schedule)
+	if err != nil {
+		return false, time.Time{}, err
+	}
+
+	location := time.UTC
+	if w.TimeZone != "" {
+		location, err = time.LoadLocation(w.TimeZone)
+		if err != nil {
+			return false, time.Time{}, err
+		}
+	}
+
+	length := w.Duration
+	if length == "" {
+		length = defaults.UpdateWindowLength
+	}
+	duration, err := time.ParseDuration(length)
+	if err != nil {
+		return false, time.Time{}, err
+	}
+
+	// The window is open when the last start happened less than duration ago
+	now = now.In(location)
+	start := schedule.Next(now.Add(-duration))
+	if !start.After(now) {
+		return true, time.Time{}, nil
+	}
+	return false, start, nil
+}
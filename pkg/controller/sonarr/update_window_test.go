@@ -0,0 +1,128 @@
+package sonarr
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	sonarrv1alpha1 "github.com/parflesh/sonarr-operator/pkg/apis/sonarr/v1alpha1"
+	"github.com/parflesh/sonarr-operator/pkg/image_inspect"
+)
+
+func TestUpdateWindowOpen(t *testing.T) {
+	now := time.Date(2020, 3, 1, 20, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name   string
+		window *sonarrv1alpha1.SonarrSpecUpdateWindow
+		open   bool
+		next   time.Time
+		err    bool
+	}{
+		{name: "no window", window: nil, open: true},
+		{name: "empty schedule", window: &sonarrv1alpha1.SonarrSpecUpdateWindow{}, open: true},
+		{
+			name:   "inside default duration",
+			window: &sonarrv1alpha1.SonarrSpecUpdateWindow{Schedule: "30 19 * * *"},
+			open:   true,
+		},
+		{
+			name:   "after window",
+			window: &sonarrv1alpha1.SonarrSpecUpdateWindow{Schedule: "0 3 * * *", Duration: "2h"},
+			open:   false,
+			next:   time.Date(2020, 3, 2, 3, 0, 0, 0, time.UTC),
+		},
+		{
+			name:   "time zone",
+			window: &sonarrv1alpha1.SonarrSpecUpdateWindow{Schedule: "0 21 * * *", TimeZone: "Europe/Berlin", Duration: "30m"},
+			open:   true,
+		},
+		{
+			name:   "time zone closed",
+			window: &sonarrv1alpha1.SonarrSpecUpdateWindow{Schedule: "0 3 * * *", TimeZone: "America/New_York"},
+			open:   false,
+			next:   time.Date(2020, 3, 2, 8, 0, 0, 0, time.UTC),
+		},
+		{
+			name:   "window end is exclusive",
+			window: &sonarrv1alpha1.SonarrSpecUpdateWindow{Schedule: "0 19 * * *", Duration: "1h"},
+			open:   false,
+			next:   time.Date(2020, 3, 2, 19, 0, 0, 0, time.UTC),
+		},
+		{name: "invalid schedule", window: &sonarrv1alpha1.SonarrSpecUpdateWindow{Schedule: "never"}, err: true},
+		{name: "invalid time zone", window: &sonarrv1alpha1.SonarrSpecUpdateWindow{Schedule: "0 3 * * *", TimeZone: "Mars/Olympus"}, err: true},
+		{name: "invalid duration", window: &sonarrv1alpha1.SonarrSpecUpdateWindow{Schedule: "0 3 * * *", Duration: "1 hour"}, err: true},
+	}
+
+	for _, test := range tests {
+		open, next, err := updateWindowOpen(test.window, now)
+		if test.err {
+			if err == nil || open {
+				t.Errorf("%s: expected closed window with error, got open=%t err=%v", test.name, open, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: (%v)", test.name, err)
+			continue
+		}
+		if open != test.open {
+			t.Errorf("%s: open %t, expected %t", test.name, open, test.open)
+		}
+		if !next.Equal(test.next) {
+			t.Errorf("%s: next window %s, expected %s", test.name, next, test.next)
+		}
+	}
+}
+
+func TestSonarrControllerUpdateWindow(t *testing.T) {
+	cr := newTestSonarr(sonarrv1alpha1.SonarrSpec{})
+
+	r, req := newTestReconciler(cr)
+	inspector := r.imageInspector.(*image_inspect.MockImageInspector)
+
+	// Initial deployment is not held back by the window
+	cr = getSonarr(t, r, req)
+	now := time.Now().UTC()
+	cr.Spec.UpdateWindow = &sonarrv1alpha1.SonarrSpecUpdateWindow{
+		Schedule: fmt.Sprintf("%d %d * * *", now.Minute(), (now.Hour()+12)%24),
+		Duration: "1m",
+	}
+	updateSonarr(t, r, cr)
+	reconcileTimes(t, r, req, 2)
+
+	// New digest outside the window stays pending
+	inspector.GetDigestOutput = testNewDigest
+	expireImageCheck(t, r, req)
+	res, err := r.Reconcile(req)
+	if err != nil {
+		t.Fatalf("reconcile: (%v)", err)
+	}
+	if res.Requeue {
+		t.Error("reconcile requeued for pending update")
+	}
+	dep := getDeployment(t, r, req)
+	if dep.Spec.Template.Spec.Containers[0].Image != cr.Spec.Image+"@"+testDigest {
+		t.Errorf("deployment updated outside window: %s", dep.Spec.Template.Spec.Containers[0].Image)
+	}
+	cr = getSonarr(t, r, req)
+	if cr.Status.Phase != "UpdatePending" || cr.Status.PendingImage != cr.Spec.Image+"@"+testNewDigest {
+		t.Errorf("pending update not reported: phase %s, pending image %s", cr.Status.Phase, cr.Status.PendingImage)
+	}
+	if cr.Status.Image != cr.Spec.Image+"@"+testDigest {
+		t.Errorf("status image does not match running image: %s", cr.Status.Image)
+	}
+
+	// Window opens
+	cr.Spec.UpdateWindow.Schedule = "* * * * *"
+	updateSonarr(t, r, cr)
+	reconcileTimes(t, r, req, 1)
+	dep = getDeployment(t, r, req)
+	if dep.Spec.Template.Spec.Containers[0].Image != cr.Spec.Image+"@"+testNewDigest {
+		t.Errorf("deployment not updated inside window: %s", dep.Spec.Template.Spec.Containers[0].Image)
+	}
+	cr = getSonarr(t, r, req)
+	if cr.Status.PendingImage != "" {
+		t.Errorf("pending image not cleared: %s", cr.Status.PendingImage)
+	}
+}
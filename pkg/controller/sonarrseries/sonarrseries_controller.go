@@ -0,0 +1,332 @@
+package sonarrseries
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"path"
+	"reflect"
+	"time"
+
+	sonarrv1alpha1 "github.com/parflesh/sonarr-operator/pkg/apis/sonarr/v1alpha1"
+	"github.com/parflesh/sonarr-operator/pkg/controller/sonarr"
+	"github.com/parflesh/sonarr-operator/pkg/sonarrapi"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+)
+
+var log = logf.Log.WithName("controller_sonarrseries")
+
+// seriesTimeout limits how long a reconcile spends on the Sonarr API
+var seriesTimeout = time.Minute
+
+// Add creates a new SonarrSeries Controller and adds it to the Manager. The Manager will set fields on the Controller
+// and Start it when the Manager is Started.
+func Add(mgr manager.Manager) error {
+	return add(mgr, newReconciler(mgr))
+}
+
+// newReconciler returns a new reconcile.Reconciler
+func newReconciler(mgr manager.Manager) reconcile.Reconciler {
+	return &ReconcileSonarrSeries{client: mgr.GetClient(), scheme: mgr.GetScheme()}
+}
+
+// add adds a new Controller to mgr with r as the reconcile.Reconciler
+func add(mgr manager.Manager, r reconcile.Reconciler) error {
+	// Create a new controller
+	c, err := controller.New("sonarrseries-controller", mgr, controller.Options{Reconciler: r})
+	if err != nil {
+		return err
+	}
+
+	// Watch for changes to primary resource SonarrSeries
+	err = c.Watch(&source.Kind{Type: &sonarrv1alpha1.SonarrSeries{}}, &handler.EnqueueRequestForObject{})
+	if err != nil {
+		return err
+	}
+
+	// Watch for changes to Sonarr and requeue the SonarrSeries it owns, so they do not wait for the next resync
+	err = c.Watch(&source.Kind{Type: &sonarrv1alpha1.Sonarr{}}, &handler.EnqueueRequestsFromMapFunc{
+		ToRequests: handler.ToRequestsFunc(func(obj handler.MapObject) []reconcile.Request {
+			return seriesOfSonarr(mgr.GetClient(), obj.Meta.GetNamespace(), obj.Meta.GetName())
+		}),
+	})
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+// seriesOfSonarr returns the requests for the SonarrSeries of the Sonarr name in namespace
+func seriesOfSonarr(c client.Client, namespace string, name string) []reconcile.Request {
+	list := &sonarrv1alpha1.SonarrSeriesList{}
+	if err := c.List(context.TODO(), list, client.InNamespace(namespace)); err != nil {
+		log.Error(err, "Failed to list SonarrSeries", "Namespace", namespace, "Sonarr", name)
+		return nil
+	}
+	var requests []reconcile.Request
+	for _, series := range list.Items {
+		if series.Spec.Sonarr == name {
+			requests = append(requests, reconcile.Request{NamespacedName: types.NamespacedName{Namespace: series.Namespace, Name: series.Name}})
+		}
+	}
+	return requests
+}
+
+// blank assignment to verify that ReconcileSonarrSeries implements reconcile.Reconciler
+var _ reconcile.Reconciler = &ReconcileSonarrSeries{}
+
+// ReconcileSonarrSeries reconciles a SonarrSeries object
+type ReconcileSonarrSeries struct {
+	// This client, initialized using mgr.Client() above, is a split client
+	// that reads objects from the cache and writes to the apiserver
+	client client.Client
+	scheme *runtime.Scheme
+
+	// sonarrClient overrides the client used to reach the Sonarr API
+	sonarrClient func(cr *sonarrv1alpha1.Sonarr) (*sonarrapi.Client, error)
+}
+
+// Reconcile adds the series of a SonarrSeries to its Sonarr, keeps the settings of the series in line with the spec
+// and reports the episodes downloaded.  The series stays in Sonarr when the SonarrSeries is deleted.
+func (r *ReconcileSonarrSeries) Reconcile(request reconcile.Request) (reconcile.Result, error) {
+	reqLogger := log.WithValues("Request.Namespace", request.Namespace, "Request.Name", request.Name)
+	reqLogger.Info("Reconciling SonarrSeries")
+
+	// Fetch the SonarrSeries instance
+	instance := &sonarrv1alpha1.SonarrSeries{}
+	err := r.client.Get(context.TODO(), request.NamespacedName, instance)
+	if err != nil {
+		if errors.IsNotFound(err) {
+			// Request object not found, could have been deleted after reconcile request.
+			return reconcile.Result{}, nil
+		}
+		// Error reading the object - requeue the request.
+		return reconcile.Result{}, err
+	}
+
+	newStatus := instance.Status
+	requeueTime := time.Second * 60
+
+	sonarrCR := &sonarrv1alpha1.Sonarr{}
+	err = r.client.Get(context.TODO(), client.ObjectKey{Namespace: instance.Namespace, Name: instance.Spec.Sonarr}, sonarrCR)
+	if err != nil && errors.IsNotFound(err) {
+		newStatus.Phase = "Pending"
+		newStatus.Reason = fmt.Sprintf("Sonarr %s not found", instance.Spec.Sonarr)
+		_ = r.updateStatus(newStatus, instance)
+		return reconcile.Result{RequeueAfter: requeueTime}, nil
+	} else if err != nil {
+		return reconcile.Result{}, err
+	}
+	if d, err := time.ParseDuration(sonarrCR.Spec.WatchFrequency); err == nil {
+		requeueTime = d
+	}
+	if !sonarr.Reachable(sonarrCR) {
+		newStatus.Phase = "Pending"
+		newStatus.Reason = fmt.Sprintf("Waiting for the Sonarr API of %s", sonarrCR.Name)
+		_ = r.updateStatus(newStatus, instance)
+		return reconcile.Result{RequeueAfter: requeueTime}, nil
+	}
+
+	c, err := r.newSonarrClient(sonarrCR)
+	if err != nil {
+		newStatus.Phase = "Pending"
+		newStatus.Reason = err.Error()
+		_ = r.updateStatus(newStatus, instance)
+		return reconcile.Result{RequeueAfter: requeueTime}, nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.TODO(), seriesTimeout)
+	defer cancel()
+	phase, reason, err := r.reconcileSeries(ctx, c, instance, &newStatus)
+	if err != nil {
+		reqLogger.Error(err, "Failed to reconcile series", "TVDBID", instance.Spec.TVDBID)
+		newStatus.Phase = "Degraded"
+		newStatus.Reason = err.Error()
+		_ = r.updateStatus(newStatus, instance)
+		return reconcile.Result{}, err
+	}
+	newStatus.Phase = phase
+	newStatus.Reason = reason
+	if err := r.updateStatus(newStatus, instance); err != nil {
+		return reconcile.Result{}, err
+	}
+	if phase == "Initializing" || phase == "Updating" {
+		return reconcile.Result{Requeue: true}, nil
+	}
+	return reconcile.Result{RequeueAfter: requeueTime}, nil
+}
+
+func (r *ReconcileSonarrSeries) newSonarrClient(cr *sonarrv1alpha1.Sonarr) (*sonarrapi.Client, error) {
+	if r.sonarrClient != nil {
+		return r.sonarrClient(cr)
+	}
+	return sonarr.Client(r.client, cr)
+}
+
+// reconcileSeries adds the series of cr to Sonarr when missing, otherwise updates the settings of the series that
+// differ from the spec and reports its statistics.  It returns the phase and reason to report.
+func (r *ReconcileSonarrSeries) reconcileSeries(ctx context.Context, c *sonarrapi.Client, cr *sonarrv1alpha1.SonarrSeries, status *sonarrv1alpha1.SonarrSeriesStatus) (string, string, error) {
+	found, err := c.GetSeriesByTVDBID(ctx, int(cr.Spec.TVDBID))
+	if err != nil {
+		return "", "", err
+	}
+
+	profileID, err := r.qualityProfileID(ctx, c, cr)
+	if err != nil {
+		return "", "", err
+	}
+	if profileID == 0 {
+		return "Degraded", fmt.Sprintf("quality profile %s not found", cr.Spec.QualityProfile), nil
+	}
+
+	if found == nil {
+		return r.addSeries(ctx, c, cr, status, profileID)
+	}
+
+	r.setStatus(found, status)
+	if !r.applySpec(cr, found, profileID) {
+		return "Available", "", nil
+	}
+	log.Info("Updating series", "Namespace", cr.Namespace, "Name", cr.Name, "Title", found.Title)
+	updated, err := c.UpdateSeries(ctx, found)
+	if err != nil {
+		return "", "", fmt.Errorf("update series %s: %v", found.Title, err)
+	}
+	r.setStatus(updated, status)
+	return "Updating", "Updated series", nil
+}
+
+// qualityProfileID returns the id of the quality profile of cr, or 0 when Sonarr has no such profile
+func (r *ReconcileSonarrSeries) qualityProfileID(ctx context.Context, c *sonarrapi.Client, cr *sonarrv1alpha1.SonarrSeries) (int, error) {
+	profiles, err := c.GetQualityProfiles(ctx)
+	if err != nil {
+		return 0, err
+	}
+	for _, p := range profiles {
+		if p.Name == cr.Spec.QualityProfile {
+			return p.ID, nil
+		}
+	}
+	return 0, nil
+}
+
+// addSeries looks the series of cr up and adds it to Sonarr
+func (r *ReconcileSonarrSeries) addSeries(ctx context.Context, c *sonarrapi.Client, cr *sonarrv1alpha1.SonarrSeries, status *sonarrv1alpha1.SonarrSeriesStatus, profileID int) (string, string, error) {
+	series, err := c.LookupSeries(ctx, int(cr.Spec.TVDBID))
+	if err != nil {
+		return "", "", err
+	}
+	if series == nil {
+		return "Degraded", fmt.Sprintf("no series with TVDB id %d", cr.Spec.TVDBID), nil
+	}
+
+	folders, err := c.GetRootFolders(ctx)
+	if err != nil {
+		return "", "", err
+	}
+	rootFolder := ""
+	for _, f := range folders {
+		if cr.Spec.RootFolder == "" || path.Clean(f.Path) == path.Clean(cr.Spec.RootFolder) {
+			rootFolder = path.Clean(f.Path)
+			break
+		}
+	}
+	if rootFolder == "" && cr.Spec.RootFolder != "" {
+		return "Degraded", fmt.Sprintf("root folder %s not found", cr.Spec.RootFolder), nil
+	} else if rootFolder == "" {
+		return "Degraded", "Sonarr has no root folders", nil
+	}
+
+	// Sonarr v3 requires a language profile, Sonarr v4 has none
+	languages, err := c.GetLanguageProfiles(ctx)
+	if err != nil && !sonarrapi.IsNotFound(err) {
+		return "", "", err
+	}
+	if len(languages) > 0 {
+		series.LanguageProfileID = languages[0].ID
+	}
+
+	series.RootFolderPath = rootFolder
+	series.SeasonFolder = true
+	r.applySpec(cr, series, profileID)
+	monitor := cr.Spec.Monitor
+	if monitor == "" {
+		monitor = "all"
+	}
+	series.AddOptions = &sonarrapi.AddSeriesOptions{Monitor: monitor, SearchForMissingEpisodes: cr.Spec.SearchOnAdd}
+
+	log.Info("Adding series", "Namespace", cr.Namespace, "Name", cr.Name, "Title", series.Title, "RootFolder", rootFolder)
+	added, err := c.AddSeries(ctx, series)
+	if err != nil {
+		return "", "", fmt.Errorf("add series %s: %v", series.Title, err)
+	}
+	r.setStatus(added, status)
+	return "Initializing", "Added series", nil
+}
+
+// applySpec sets the settings of series from the spec of cr and reports whether any changed
+func (r *ReconcileSonarrSeries) applySpec(cr *sonarrv1alpha1.SonarrSeries, series *sonarrapi.Series, profileID int) bool {
+	original := *series
+	original.Seasons = append([]sonarrapi.Season(nil), series.Seasons...)
+
+	series.QualityProfileID = profileID
+	series.Monitored = cr.Spec.Monitored == nil || *cr.Spec.Monitored
+	if cr.Spec.SeasonFolder != nil {
+		series.SeasonFolder = *cr.Spec.SeasonFolder
+	}
+	if cr.Spec.SeriesType != "" {
+		series.SeriesType = cr.Spec.SeriesType
+	} else if series.SeriesType == "" {
+		series.SeriesType = sonarrapi.SeriesStandard
+	}
+	for _, s := range cr.Spec.Seasons {
+		for i := range series.Seasons {
+			if series.Seasons[i].SeasonNumber == int(s.SeasonNumber) {
+				series.Seasons[i].Monitored = s.Monitored
+			}
+		}
+	}
+
+	return original.QualityProfileID != series.QualityProfileID ||
+		original.Monitored != series.Monitored ||
+		original.SeasonFolder != series.SeasonFolder ||
+		original.SeriesType != series.SeriesType ||
+		!reflect.DeepEqual(original.Seasons, series.Seasons)
+}
+
+// setStatus reports the id, path and episode statistics of series
+func (r *ReconcileSonarrSeries) setStatus(series *sonarrapi.Series, status *sonarrv1alpha1.SonarrSeriesStatus) {
+	status.SeriesID = int32(series.ID)
+	status.Title = series.Title
+	status.Path = series.Path
+	stats := series.Statistics
+	if stats == nil {
+		stats = &sonarrapi.SeriesStatistics{}
+	}
+	status.EpisodeCount = int32(stats.EpisodeCount)
+	status.EpisodeFileCount = int32(stats.EpisodeFileCount)
+	status.TotalEpisodeCount = int32(stats.TotalEpisodeCount)
+	status.SizeOnDisk = stats.SizeOnDisk
+	status.DownloadProgress = int32(math.Floor(stats.PercentOfEpisodes))
+}
+
+func (r *ReconcileSonarrSeries) updateStatus(status sonarrv1alpha1.SonarrSeriesStatus, cr *sonarrv1alpha1.SonarrSeries) error {
+	if !reflect.DeepEqual(status, cr.Status) {
+		cr.Status = status
+		if err := r.client.Status().Update(context.TODO(), cr); err != nil {
+			reqLogger := log.WithValues("Request.Namespace", cr.Namespace, "Request.Name", cr.Name)
+			reqLogger.Error(err, "Status", status)
+			return err
+		}
+	}
+	return nil
+}
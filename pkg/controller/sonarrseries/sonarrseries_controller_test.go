@@ -0,0 +1,183 @@
+package sonarrseries
+
+import (
+	"context"
+	"testing"
+
+	sonarrv1alpha1 "github.com/parflesh/sonarr-operator/pkg/apis/sonarr/v1alpha1"
+	"github.com/parflesh/sonarr-operator/pkg/sonarrapi"
+	"github.com/parflesh/sonarr-operator/pkg/sonarrapi/sonarrapitest"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+func TestSonarrSeriesController(t *testing.T) {
+	sonarrCR := &sonarrv1alpha1.Sonarr{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "sonarr-operator",
+			Namespace: "sonarr",
+		},
+		Spec: sonarrv1alpha1.SonarrSpec{
+			WatchFrequency: "5m",
+			APIKeySecret:   "sonarr-api-key",
+		},
+	}
+	monitored := false
+	cr := &sonarrv1alpha1.SonarrSeries{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "the-expanse",
+			Namespace: "sonarr",
+		},
+		Spec: sonarrv1alpha1.SonarrSeriesSpec{
+			Sonarr:         "sonarr-operator",
+			TVDBID:         280619,
+			QualityProfile: "HD",
+			Monitor:        "future",
+			SearchOnAdd:    true,
+		},
+	}
+
+	server := sonarrapitest.NewServer()
+	defer server.Close()
+	server.Add(sonarrapitest.QualityProfiles, sonarrapi.QualityProfile{Name: "Any"})
+	server.Add(sonarrapitest.QualityProfiles, sonarrapi.QualityProfile{Name: "HD"})
+	server.Add(sonarrapitest.RootFolders, sonarrapi.RootFolder{Path: "/tv/"})
+	server.Add(sonarrapitest.LanguageProfiles, sonarrapi.LanguageProfile{Name: "English"})
+	server.Lookup = []sonarrapi.Series{{
+		Title:   "The Expanse",
+		TvdbID:  280619,
+		Seasons: []sonarrapi.Season{{SeasonNumber: 0}, {SeasonNumber: 1}, {SeasonNumber: 2}},
+	}}
+
+	s := scheme.Scheme
+	s.AddKnownTypes(sonarrv1alpha1.SchemeGroupVersion, sonarrCR, cr)
+	cl := fake.NewFakeClientWithScheme(s, sonarrCR, cr)
+	r := &ReconcileSonarrSeries{
+		client: cl,
+		scheme: s,
+		sonarrClient: func(cr *sonarrv1alpha1.Sonarr) (*sonarrapi.Client, error) {
+			return server.Client(), nil
+		},
+	}
+	req := reconcile.Request{
+		NamespacedName: types.NamespacedName{
+			Name:      cr.Name,
+			Namespace: cr.Namespace,
+		},
+	}
+	getSeries := func() *sonarrv1alpha1.SonarrSeries {
+		series := &sonarrv1alpha1.SonarrSeries{}
+		if err := r.client.Get(context.TODO(), req.NamespacedName, series); err != nil {
+			t.Fatalf("get series: (%v)", err)
+		}
+		return series
+	}
+
+	// Series wait for the Sonarr API to be reachable
+	if _, err := r.Reconcile(req); err != nil {
+		t.Fatalf("reconcile: (%v)", err)
+	}
+	if cr = getSeries(); cr.Status.Phase != "Pending" {
+		t.Errorf("series not pending: %s %s", cr.Status.Phase, cr.Status.Reason)
+	}
+	sonarrCR.Status.Conditions = []sonarrv1alpha1.SonarrCondition{{Type: sonarrv1alpha1.ConditionApplicationReachable, Status: corev1.ConditionTrue}}
+	if err := r.client.Status().Update(context.TODO(), sonarrCR); err != nil {
+		t.Fatalf("update sonarr status: (%v)", err)
+	}
+
+	res, err := r.Reconcile(req)
+	if err != nil {
+		t.Fatalf("reconcile: (%v)", err)
+	}
+	if !res.Requeue {
+		t.Error("reconcile did not requeue after adding the series")
+	}
+	var series []sonarrapi.Series
+	server.List(sonarrapitest.Series, &series)
+	if len(series) != 1 {
+		t.Fatalf("series not added: %v", series)
+	}
+	added := series[0]
+	if added.QualityProfileID != 2 || added.LanguageProfileID != 4 || added.RootFolderPath != "/tv" || !added.Monitored ||
+		!added.SeasonFolder || added.SeriesType != sonarrapi.SeriesStandard {
+		t.Errorf("unexpected series: %+v", added)
+	}
+	if added.AddOptions == nil || added.AddOptions.Monitor != "future" || !added.AddOptions.SearchForMissingEpisodes {
+		t.Errorf("unexpected add options: %+v", added.AddOptions)
+	}
+	if cr = getSeries(); cr.Status.SeriesID != int32(added.ID) || cr.Status.Title != "The Expanse" || cr.Status.Phase != "Initializing" {
+		t.Errorf("unexpected status: %+v", cr.Status)
+	}
+
+	// Sonarr computes the statistics of the series
+	added.Statistics = &sonarrapi.SeriesStatistics{EpisodeFileCount: 5, EpisodeCount: 12, TotalEpisodeCount: 24, SizeOnDisk: 1 << 30, PercentOfEpisodes: 41.67}
+	if _, err := server.Client().UpdateSeries(context.TODO(), &added); err != nil {
+		t.Fatalf("update series statistics: (%v)", err)
+	}
+
+	// Settings drifting from the spec are updated
+	cr.Spec.Monitored = &monitored
+	cr.Spec.SeriesType = sonarrapi.SeriesDaily
+	cr.Spec.Seasons = []sonarrv1alpha1.SonarrSeriesSpecSeason{{SeasonNumber: 0, Monitored: false}, {SeasonNumber: 2, Monitored: true}}
+	if err := r.client.Update(context.TODO(), cr); err != nil {
+		t.Fatalf("update series: (%v)", err)
+	}
+	if _, err := r.Reconcile(req); err != nil {
+		t.Fatalf("reconcile: (%v)", err)
+	}
+	series = nil
+	server.List(sonarrapitest.Series, &series)
+	if len(series) != 1 || series[0].Monitored || series[0].SeriesType != sonarrapi.SeriesDaily || !series[0].Seasons[2].Monitored || series[0].Seasons[0].Monitored {
+		t.Errorf("series not updated: %+v", series)
+	}
+
+	res, err = r.Reconcile(req)
+	if err != nil {
+		t.Fatalf("reconcile: (%v)", err)
+	}
+	if res.RequeueAfter.Minutes() != 5 {
+		t.Errorf("unexpected requeue: %v", res)
+	}
+	cr = getSeries()
+	if cr.Status.Phase != "Available" || cr.Status.EpisodeCount != 12 || cr.Status.EpisodeFileCount != 5 || cr.Status.TotalEpisodeCount != 24 ||
+		cr.Status.DownloadProgress != 41 || cr.Status.SizeOnDisk != 1<<30 {
+		t.Errorf("unexpected status: %+v", cr.Status)
+	}
+
+	// Missing quality profiles degrade the series
+	cr.Spec.QualityProfile = "Ultra-HD"
+	if err := r.client.Update(context.TODO(), cr); err != nil {
+		t.Fatalf("update series: (%v)", err)
+	}
+	if _, err := r.Reconcile(req); err != nil {
+		t.Fatalf("reconcile: (%v)", err)
+	}
+	if cr = getSeries(); cr.Status.Phase != "Degraded" || cr.Status.Reason != "quality profile Ultra-HD not found" {
+		t.Errorf("missing quality profile not reported: %s %s", cr.Status.Phase, cr.Status.Reason)
+	}
+}
+
+func TestSeriesOfSonarr(t *testing.T) {
+	newSeries := func(name string, sonarr string) *sonarrv1alpha1.SonarrSeries {
+		return &sonarrv1alpha1.SonarrSeries{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "sonarr"},
+			Spec:       sonarrv1alpha1.SonarrSeriesSpec{Sonarr: sonarr},
+		}
+	}
+	s := scheme.Scheme
+	s.AddKnownTypes(sonarrv1alpha1.SchemeGroupVersion, &sonarrv1alpha1.SonarrSeries{}, &sonarrv1alpha1.SonarrSeriesList{})
+	cl := fake.NewFakeClientWithScheme(s, newSeries("the-expanse", "sonarr-operator"), newSeries("firefly", "sonarr-anime"))
+
+	// A change to a Sonarr requeues only the series it owns
+	requests := seriesOfSonarr(cl, "sonarr", "sonarr-operator")
+	if len(requests) != 1 || requests[0].Name != "the-expanse" || requests[0].Namespace != "sonarr" {
+		t.Errorf("unexpected requests: %v", requests)
+	}
+	if requests := seriesOfSonarr(cl, "other", "sonarr-operator"); len(requests) != 0 {
+		t.Errorf("unexpected requests in other namespace: %v", requests)
+	}
+}
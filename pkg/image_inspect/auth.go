@@ -0,0 +1,107 @@
+package image_inspect
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/containers/image/v5/docker/reference"
+	"github.com/containers/image/v5/types"
+	corev1 "k8s.io/api/core/v1"
+)
+
+// dockerConfigEntry is a single registry entry of a docker config file
+type dockerConfigEntry struct {
+	Username string `json:"username,omitempty"`
+	Password string `json:"password,omitempty"`
+	Auth     string `json:"auth,omitempty"`
+}
+
+// dockerConfigJSON is the content of a kubernetes.io/dockerconfigjson Secret
+type dockerConfigJSON struct {
+	Auths map[string]dockerConfigEntry `json:"auths"`
+}
+
+// AuthFromSecrets returns the registry credentials for image found in the given image pull secrets, using the
+// same lookup rules as the kubelet: the most specific registry entry matching the image wins.  nil is returned
+// when none of the secrets hold credentials for the image registry.
+func AuthFromSecrets(image string, secrets []corev1.Secret) (*types.DockerAuthConfig, error) {
+	named, err := reference.ParseNormalizedNamed(image)
+	if err != nil {
+		return nil, err
+	}
+	target := reference.Domain(named) + "/" + reference.Path(named)
+
+	var found *dockerConfigEntry
+	foundLen := -1
+	for _, secret := range secrets {
+		auths, err := parseDockerConfig(secret)
+		if err != nil {
+			return nil, fmt.Errorf("secret %s: %v", secret.Name, err)
+		}
+		for key, entry := range auths {
+			registry := normalizeRegistry(key)
+			if target != registry && !strings.HasPrefix(target, registry+"/") {
+				continue
+			}
+			if len(registry) > foundLen {
+				e := entry
+				found = &e
+				foundLen = len(registry)
+			}
+		}
+	}
+	if found == nil {
+		return nil, nil
+	}
+
+	if found.Auth != "" && (found.Username == "" || found.Password == "") {
+		decoded, err := base64.StdEncoding.DecodeString(found.Auth)
+		if err != nil {
+			return nil, fmt.Errorf("decoding registry auth: %v", err)
+		}
+		parts := strings.SplitN(string(decoded), ":", 2)
+		if len(parts) != 2 {
+			return nil, fmt.Errorf("invalid registry auth, expected username:password")
+		}
+		return &types.DockerAuthConfig{Username: parts[0], Password: parts[1]}, nil
+	}
+	return &types.DockerAuthConfig{Username: found.Username, Password: found.Password}, nil
+}
+
+func parseDockerConfig(secret corev1.Secret) (map[string]dockerConfigEntry, error) {
+	switch secret.Type {
+	case corev1.SecretTypeDockerConfigJson:
+		config := dockerConfigJSON{}
+		if err := json.Unmarshal(secret.Data[corev1.DockerConfigJsonKey], &config); err != nil {
+			return nil, err
+		}
+		return config.Auths, nil
+	case corev1.SecretTypeDockercfg:
+		auths := map[string]dockerConfigEntry{}
+		if err := json.Unmarshal(secret.Data[corev1.DockerConfigKey], &auths); err != nil {
+			return nil, err
+		}
+		return auths, nil
+	}
+	return nil, fmt.Errorf("unsupported secret type %s", secret.Type)
+}
+
+// normalizeRegistry turns a docker config key (https://index.docker.io/v1/, quay.io/org, ...) into the
+// domain[/path] form used by normalized image references
+func normalizeRegistry(key string) string {
+	key = strings.TrimPrefix(key, "https://")
+	key = strings.TrimPrefix(key, "http://")
+	key = strings.TrimSuffix(key, "/")
+	if strings.HasSuffix(key, "/v1") || strings.HasSuffix(key, "/v2") {
+		key = key[:len(key)-3]
+	}
+
+	parts := strings.SplitN(key, "/", 2)
+	switch parts[0] {
+	case "index.docker.io", "registry-1.docker.io":
+		parts[0] = "docker.io"
+	}
+	return strings.Join(parts, "/")
+}
@@ -0,0 +1,152 @@
+package image_inspect
+
+import (
+	"context"
+	"strings"
+
+	"github.com/containers/image/v5/docker"
+	"github.com/containers/image/v5/docker/reference"
+	cimage "github.com/containers/image/v5/image"
+	"github.com/containers/image/v5/manifest"
+	"github.com/containers/image/v5/types"
+	"github.com/opencontainers/go-digest"
+	"github.com/parflesh/sonarr-operator/version"
+)
+
+// ImageInspector looks up container image information from the registry hosting the image.  auth may be nil
+// to access the registry anonymously.
+type ImageInspector interface {
+	// GetImageLabels returns the image configuration, including labels, of image
+	GetImageLabels(ctx context.Context, image string, auth *types.DockerAuthConfig) (*types.ImageInspectInfo, error)
+
+	// GetDigest returns the manifest digest the registry currently serves for image
+	GetDigest(ctx context.Context, image string, auth *types.DockerAuthConfig) (string, error)
+
+	// GetTags returns all tags of the repository of image
+	GetTags(ctx context.Context, image string, auth *types.DockerAuthConfig) ([]string, error)
+}
+
+// blank assignment to verify that RegistryImageInspector implements ImageInspector
+var _ ImageInspector = &RegistryImageInspector{}
+
+// RegistryImageInspector inspects images by talking to their registry over the docker/OCI distribution API
+type RegistryImageInspector struct {
+	// SystemContext used for every registry request
+	SystemContext *types.SystemContext
+}
+
+// NewImageInspector returns an ImageInspector backed by the image registries
+func NewImageInspector() ImageInspector {
+	return &RegistryImageInspector{
+		SystemContext: &types.SystemContext{
+			DockerRegistryUserAgent: "sonarr-operator/" + version.Version,
+		},
+	}
+}
+
+func (i *RegistryImageInspector) GetImageLabels(ctx context.Context, image string, auth *types.DockerAuthConfig) (*types.ImageInspectInfo, error) {
+	src, err := i.newImageSource(ctx, image, auth)
+	if err != nil {
+		return nil, err
+	}
+
+	img, err := cimage.FromSource(ctx, i.systemContext(auth), src)
+	if err != nil {
+		_ = src.Close()
+		return nil, err
+	}
+	defer img.Close()
+
+	return img.Inspect(ctx)
+}
+
+func (i *RegistryImageInspector) GetDigest(ctx context.Context, image string, auth *types.DockerAuthConfig) (string, error) {
+	src, err := i.newImageSource(ctx, image, auth)
+	if err != nil {
+		return "", err
+	}
+	defer src.Close()
+
+	rawManifest, _, err := src.GetManifest(ctx, nil)
+	if err != nil {
+		return "", err
+	}
+
+	d, err := manifest.Digest(rawManifest)
+	if err != nil {
+		return "", err
+	}
+	return d.String(), nil
+}
+
+func (i *RegistryImageInspector) GetTags(ctx context.Context, image string, auth *types.DockerAuthConfig) ([]string, error) {
+	ref, err := docker.ParseReference("//" + image)
+	if err != nil {
+		return nil, err
+	}
+	return docker.GetRepositoryTags(ctx, i.systemContext(auth), ref)
+}
+
+func (i *RegistryImageInspector) newImageSource(ctx context.Context, image string, auth *types.DockerAuthConfig) (types.ImageSource, error) {
+	ref, err := docker.ParseReference("//" + image)
+	if err != nil {
+		return nil, err
+	}
+	return ref.NewImageSource(ctx, i.systemContext(auth))
+}
+
+// systemContext returns a copy of the inspector SystemContext using auth for registry requests
+func (i *RegistryImageInspector) systemContext(auth *types.DockerAuthConfig) *types.SystemContext {
+	sys := &types.SystemContext{}
+	if i.SystemContext != nil {
+		*sys = *i.SystemContext
+	}
+	if auth != nil {
+		sys.DockerAuthConfig = auth
+	}
+	return sys
+}
+
+// HasDigest reports whether image is already pinned to a digest
+func HasDigest(image string) bool {
+	return strings.Contains(image, "@")
+}
+
+// TrimDigest returns image without its digest
+func TrimDigest(image string) string {
+	return strings.SplitN(image, "@", 2)[0]
+}
+
+// Repository returns image without its tag and digest
+func Repository(image string) string {
+	name := TrimDigest(image)
+	if i := strings.LastIndex(name, ":"); i > strings.LastIndex(name, "/") {
+		return name[:i]
+	}
+	return name
+}
+
+// Tag returns the tag of image, or an empty string for untagged images
+func Tag(image string) string {
+	name := TrimDigest(image)
+	if repository := Repository(name); repository != name {
+		return name[len(repository)+1:]
+	}
+	return ""
+}
+
+// WithTag returns image referring to tag instead of its current tag and digest
+func WithTag(image string, tag string) string {
+	return Repository(image) + ":" + tag
+}
+
+// PinDigest returns image pinned to digest while keeping its tag for readability (name:tag@digest)
+func PinDigest(image string, d string) (string, error) {
+	if _, err := reference.ParseNormalizedNamed(image); err != nil {
+		return "", err
+	}
+	if _, err := digest.Parse(d); err != nil {
+		return "", err
+	}
+	return TrimDigest(image) + "@" + d, nil
+}
@@ -0,0 +1,220 @@
+package image_inspect
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/containers/image/v5/manifest"
+	"github.com/containers/image/v5/types"
+	"github.com/opencontainers/go-digest"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const testConfig = `{"architecture":"amd64","os":"linux","config":{"Labels":{"org.opencontainers.image.version":"3.0.3.693"}},"rootfs":{"type":"layers","diff_ids":[]}}`
+
+var testManifest = fmt.Sprintf(`{
+   "schemaVersion": 2,
+   "mediaType": "application/vnd.docker.distribution.manifest.v2+json",
+   "config": {
+      "mediaType": "application/vnd.docker.container.image.v1+json",
+      "size": %d,
+      "digest": "%s"
+   },
+   "layers": []
+}`, len(testConfig), digest.FromString(testConfig))
+
+// newTestRegistry starts a registry stand-in serving testManifest for every tag of every repository.  When
+// username is set requests must use basic auth.
+func newTestRegistry(t *testing.T, username string, password string) *httptest.Server {
+	return httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if username != "" {
+			u, p, ok := req.BasicAuth()
+			if !ok || u != username || p != password {
+				w.Header().Set("WWW-Authenticate", `Basic realm="test"`)
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+		}
+
+		switch {
+		case req.URL.Path == "/v2/":
+			w.WriteHeader(http.StatusOK)
+		case strings.Contains(req.URL.Path, "/manifests/"):
+			w.Header().Set("Content-Type", manifest.DockerV2Schema2MediaType)
+			_, _ = w.Write([]byte(testManifest))
+		case strings.HasSuffix(req.URL.Path, "/tags/list"):
+			w.Header().Set("Content-Type", "application/json")
+			_, _ = w.Write([]byte(`{"name":"parflesh/sonarr","tags":["latest","3.0.3","3.0.4"]}`))
+		case strings.HasSuffix(req.URL.Path, "/blobs/"+digest.FromString(testConfig).String()):
+			_, _ = w.Write([]byte(testConfig))
+		default:
+			t.Logf("unexpected registry request %s", req.URL.Path)
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+}
+
+func newTestInspector() *RegistryImageInspector {
+	return &RegistryImageInspector{
+		SystemContext: &types.SystemContext{DockerInsecureSkipTLSVerify: types.OptionalBoolTrue},
+	}
+}
+
+func TestGetDigest(t *testing.T) {
+	registry := newTestRegistry(t, "", "")
+	defer registry.Close()
+
+	image := strings.TrimPrefix(registry.URL, "https://") + "/parflesh/sonarr:latest"
+
+	d, err := newTestInspector().GetDigest(context.TODO(), image, nil)
+	if err != nil {
+		t.Fatalf("get digest: (%v)", err)
+	}
+	expected, _ := manifest.Digest([]byte(testManifest))
+	if d != expected.String() {
+		t.Errorf("digest mismatch: got %s, expected %s", d, expected)
+	}
+}
+
+func TestGetTags(t *testing.T) {
+	registry := newTestRegistry(t, "", "")
+	defer registry.Close()
+
+	image := strings.TrimPrefix(registry.URL, "https://") + "/parflesh/sonarr:latest"
+
+	tags, err := newTestInspector().GetTags(context.TODO(), image, nil)
+	if err != nil {
+		t.Fatalf("get tags: (%v)", err)
+	}
+	if strings.Join(tags, ",") != "latest,3.0.3,3.0.4" {
+		t.Errorf("unexpected tags: %v", tags)
+	}
+}
+
+func TestGetImageLabelsWithAuth(t *testing.T) {
+	registry := newTestRegistry(t, "user", "secret")
+	defer registry.Close()
+
+	image := strings.TrimPrefix(registry.URL, "https://") + "/parflesh/sonarr:latest"
+	i := newTestInspector()
+
+	if _, err := i.GetImageLabels(context.TODO(), image, nil); err == nil {
+		t.Error("anonymous request to authenticated registry succeeded")
+	}
+
+	info, err := i.GetImageLabels(context.TODO(), image, &types.DockerAuthConfig{Username: "user", Password: "secret"})
+	if err != nil {
+		t.Fatalf("get image labels: (%v)", err)
+	}
+	if info.Labels["org.opencontainers.image.version"] != "3.0.3.693" {
+		t.Errorf("unexpected labels: %v", info.Labels)
+	}
+}
+
+func TestAuthFromSecrets(t *testing.T) {
+	dockerConfigJSON := func(name string, config string) corev1.Secret {
+		return corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: name},
+			Type:       corev1.SecretTypeDockerConfigJson,
+			Data:       map[string][]byte{corev1.DockerConfigJsonKey: []byte(config)},
+		}
+	}
+	secrets := []corev1.Secret{
+		dockerConfigJSON("hub", `{"auths":{"https://index.docker.io/v1/":{"username":"hub","password":"hubpass"}}}`),
+		dockerConfigJSON("quay", `{"auths":{"quay.io":{"auth":"`+base64.StdEncoding.EncodeToString([]byte("quay:quaypass"))+`"}}}`),
+		dockerConfigJSON("quay-org", `{"auths":{"quay.io/parflesh":{"username":"org","password":"orgpass"}}}`),
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "legacy"},
+			Type:       corev1.SecretTypeDockercfg,
+			Data:       map[string][]byte{corev1.DockerConfigKey: []byte(`{"registry.example.com":{"username":"legacy","password":"legacypass"}}`)},
+		},
+	}
+
+	tests := []struct {
+		image    string
+		expected *types.DockerAuthConfig
+	}{
+		{image: "sonarr:latest", expected: &types.DockerAuthConfig{Username: "hub", Password: "hubpass"}},
+		{image: "quay.io/other/sonarr:latest", expected: &types.DockerAuthConfig{Username: "quay", Password: "quaypass"}},
+		{image: "quay.io/parflesh/sonarr:latest", expected: &types.DockerAuthConfig{Username: "org", Password: "orgpass"}},
+		{image: "quay.io/parflesh-other/sonarr", expected: &types.DockerAuthConfig{Username: "quay", Password: "quaypass"}},
+		{image: "registry.example.com/sonarr", expected: &types.DockerAuthConfig{Username: "legacy", Password: "legacypass"}},
+		{image: "ghcr.io/parflesh/sonarr", expected: nil},
+	}
+
+	for _, test := range tests {
+		auth, err := AuthFromSecrets(test.image, secrets)
+		if err != nil {
+			t.Errorf("%s: (%v)", test.image, err)
+			continue
+		}
+		if (auth == nil) != (test.expected == nil) || (auth != nil && *auth != *test.expected) {
+			t.Errorf("%s: got %v, expected %v", test.image, auth, test.expected)
+		}
+	}
+
+	if _, err := AuthFromSecrets("sonarr", []corev1.Secret{{Type: corev1.SecretTypeOpaque}}); err == nil {
+		t.Error("expected error for non docker config secret")
+	}
+}
+
+func TestPinDigest(t *testing.T) {
+	d := "sha256:0000000000000000000000000000000000000000000000000000000000000001"
+	tests := []struct {
+		image    string
+		expected string
+		err      bool
+	}{
+		{image: "quay.io/parflesh/sonarr:latest", expected: "quay.io/parflesh/sonarr:latest@" + d},
+		{image: "sonarr", expected: "sonarr@" + d},
+		{image: "quay.io/parflesh/sonarr:latest@sha256:0000000000000000000000000000000000000000000000000000000000000002", expected: "quay.io/parflesh/sonarr:latest@" + d},
+		{image: "Invalid:Image", err: true},
+	}
+
+	for _, test := range tests {
+		image, err := PinDigest(test.image, d)
+		if test.err {
+			if err == nil {
+				t.Errorf("%s: expected error", test.image)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: (%v)", test.image, err)
+		}
+		if image != test.expected {
+			t.Errorf("%s: got %s, expected %s", test.image, image, test.expected)
+		}
+	}
+}
+
+func TestTag(t *testing.T) {
+	tests := []struct {
+		image      string
+		repository string
+		tag        string
+	}{
+		{image: "sonarr", repository: "sonarr", tag: ""},
+		{image: "sonarr:3.0.3", repository: "sonarr", tag: "3.0.3"},
+		{image: "localhost:5000/sonarr", repository: "localhost:5000/sonarr", tag: ""},
+		{image: "localhost:5000/sonarr:latest@sha256:0000000000000000000000000000000000000000000000000000000000000001", repository: "localhost:5000/sonarr", tag: "latest"},
+	}
+
+	for _, test := range tests {
+		if repository := Repository(test.image); repository != test.repository {
+			t.Errorf("%s: repository %s, expected %s", test.image, repository, test.repository)
+		}
+		if tag := Tag(test.image); tag != test.tag {
+			t.Errorf("%s: tag %s, expected %s", test.image, tag, test.tag)
+		}
+		if image := WithTag(test.image, "3.0.4"); image != test.repository+":3.0.4" {
+			t.Errorf("%s: with tag %s", test.image, image)
+		}
+	}
+}
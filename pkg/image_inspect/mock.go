@@ -0,0 +1,41 @@
+package image_inspect
+
+import (
+	"context"
+
+	"github.com/containers/image/v5/types"
+)
+
+// blank assignment to verify that MockImageInspector implements ImageInspector
+var _ ImageInspector = &MockImageInspector{}
+
+// MockImageInspector is an ImageInspector returning preset values, for use in tests.  The credentials of the
+// last call are kept in Auth, and the number of calls in Calls.
+type MockImageInspector struct {
+	GetImageLabelsOutput *types.ImageInspectInfo
+	GetImageLabelsError  error
+	GetDigestOutput      string
+	GetDigestError       error
+	GetTagsOutput        []string
+	GetTagsError         error
+	Auth                 *types.DockerAuthConfig
+	Calls                int
+}
+
+func (m *MockImageInspector) GetImageLabels(ctx context.Context, image string, auth *types.DockerAuthConfig) (*types.ImageInspectInfo, error) {
+	m.Auth = auth
+	m.Calls++
+	return m.GetImageLabelsOutput, m.GetImageLabelsError
+}
+
+func (m *MockImageInspector) GetDigest(ctx context.Context, image string, auth *types.DockerAuthConfig) (string, error) {
+	m.Auth = auth
+	m.Calls++
+	return m.GetDigestOutput, m.GetDigestError
+}
+
+func (m *MockImageInspector) GetTags(ctx context.Context, image string, auth *types.DockerAuthConfig) ([]string, error) {
+	m.Auth = auth
+	m.Calls++
+	return m.GetTagsOutput, m.GetTagsError
+}
@@ -0,0 +1,64 @@
+package sonarrapi
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// BackupCommand is the name of the command creating a backup
+const BackupCommand = "Backup"
+
+// Backup is a backup archive created by Sonarr
+type Backup struct {
+	ID   int       `json:"id"`
+	Name string    `json:"name"`
+	Path string    `json:"path"`
+	Type string    `json:"type"`
+	Time time.Time `json:"time"`
+}
+
+// GetBackups returns the backups known to Sonarr
+func (c *Client) GetBackups(ctx context.Context) ([]Backup, error) {
+	var backups []Backup
+	if err := c.do(ctx, http.MethodGet, "/system/backup", nil, &backups); err != nil {
+		return nil, err
+	}
+	return backups, nil
+}
+
+// Backup runs the backup command, waits for it to complete and returns the newest backup
+func (c *Client) Backup(ctx context.Context, pollInterval time.Duration) (*Backup, error) {
+	command, err := c.RunCommand(ctx, BackupCommand)
+	if err != nil {
+		return nil, err
+	}
+	command, err = c.WaitForCommand(ctx, command.ID, pollInterval)
+	if err != nil {
+		return nil, err
+	}
+	if command.Status != CommandCompleted {
+		return nil, fmt.Errorf("backup %s: %s", command.Status, command.Message)
+	}
+
+	return c.NewestBackup(ctx)
+}
+
+// NewestBackup returns the most recent backup known to Sonarr
+func (c *Client) NewestBackup(ctx context.Context) (*Backup, error) {
+	backups, err := c.GetBackups(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var newest *Backup
+	for i := range backups {
+		if newest == nil || backups[i].Time.After(newest.Time) {
+			newest = &backups[i]
+		}
+	}
+	if newest == nil {
+		return nil, fmt.Errorf("no backup found")
+	}
+	return newest, nil
+}
@@ -0,0 +1,92 @@
+package sonarrapi
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// DefaultTimeout limits requests of clients returned by NewClient that are not limited by their context
+const DefaultTimeout = 30 * time.Second
+
+// Client talks to the HTTP API (v3) of a Sonarr instance
+type Client struct {
+	// BaseURL of the Sonarr instance, including the URL base if configured (e.g. http://sonarr:8989)
+	BaseURL string
+
+	// APIKey from the Sonarr settings
+	APIKey string
+
+	HTTPClient *http.Client
+
+	// Timeout limits each request whose context has no deadline, unless zero
+	Timeout time.Duration
+}
+
+// NewClient returns a Client for the Sonarr instance at baseURL
+func NewClient(baseURL string, apiKey string) *Client {
+	return &Client{
+		BaseURL:    strings.TrimSuffix(baseURL, "/"),
+		APIKey:     apiKey,
+		HTTPClient: http.DefaultClient,
+		Timeout:    DefaultTimeout,
+	}
+}
+
+// do sends a request to path, below /api/v3, encoding in as JSON body if set and decoding the response into out if
+// set
+func (c *Client) do(ctx context.Context, method string, path string, in interface{}, out interface{}) error {
+	var body io.Reader
+	if in != nil {
+		data, err := json.Marshal(in)
+		if err != nil {
+			return err
+		}
+		body = bytes.NewReader(data)
+	}
+
+	if _, ok := ctx.Deadline(); !ok && c.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, c.Timeout)
+		defer cancel()
+	}
+
+	req, err := http.NewRequest(method, c.BaseURL+"/api/v3"+path, body)
+	if err != nil {
+		return err
+	}
+	req = req.WithContext(ctx)
+	req.Header.Set("X-Api-Key", c.APIKey)
+	req.Header.Set("Accept", "application/json")
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := c.HTTPClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	data, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		apiErr := &APIError{Method: method, Path: path, StatusCode: resp.StatusCode, Message: strings.TrimSpace(string(data))}
+		if resp.StatusCode == http.StatusBadRequest {
+			_ = json.Unmarshal(data, &apiErr.Failures)
+		}
+		return apiErr
+	}
+
+	if out == nil || len(data) == 0 {
+		return nil
+	}
+	return json.Unmarshal(data, out)
+}
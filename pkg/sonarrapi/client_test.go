@@ -0,0 +1,82 @@
+package sonarrapi
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+const testAPIKey = "0123456789abcdef0123456789abcdef"
+
+// newTestServer starts a Sonarr stand-in whose backup command ends in commandStatus
+func newTestServer(t *testing.T, commandStatus string) *httptest.Server {
+	polls := 0
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if req.Header.Get("X-Api-Key") != testAPIKey {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case req.Method == http.MethodPost && req.URL.Path == "/api/v3/command":
+			command := Command{}
+			if err := json.NewDecoder(req.Body).Decode(&command); err != nil || command.Name != BackupCommand {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			_, _ = w.Write([]byte(`{"id":7,"name":"Backup","status":"queued"}`))
+		case req.Method == http.MethodGet && req.URL.Path == "/api/v3/command/7":
+			polls++
+			status := CommandStarted
+			if polls > 1 {
+				status = commandStatus
+			}
+			_ = json.NewEncoder(w).Encode(Command{ID: 7, Name: BackupCommand, Status: status})
+		case req.Method == http.MethodGet && req.URL.Path == "/api/v3/system/backup":
+			_, _ = w.Write([]byte(`[
+				{"id":1,"name":"sonarr_backup_2020.03.01_03.00.00.zip","path":"/backup/scheduled/sonarr_backup_2020.03.01_03.00.00.zip","type":"scheduled","time":"2020-03-01T03:00:00Z"},
+				{"id":2,"name":"sonarr_backup_2020.03.02_20.00.00.zip","path":"/backup/manual/sonarr_backup_2020.03.02_20.00.00.zip","type":"manual","time":"2020-03-02T20:00:00Z"}
+			]`))
+		default:
+			t.Logf("unexpected request %s %s", req.Method, req.URL.Path)
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+}
+
+func TestBackup(t *testing.T) {
+	server := newTestServer(t, CommandCompleted)
+	defer server.Close()
+
+	backup, err := NewClient(server.URL, testAPIKey).Backup(context.TODO(), time.Millisecond)
+	if err != nil {
+		t.Fatalf("backup: (%v)", err)
+	}
+	if backup.Name != "sonarr_backup_2020.03.02_20.00.00.zip" {
+		t.Errorf("unexpected backup: %s", backup.Name)
+	}
+}
+
+func TestBackupFailed(t *testing.T) {
+	server := newTestServer(t, CommandFailed)
+	defer server.Close()
+
+	if _, err := NewClient(server.URL, testAPIKey).Backup(context.TODO(), time.Millisecond); err == nil {
+		t.Error("failed backup command did not return an error")
+	}
+}
+
+func TestBackupUnauthorized(t *testing.T) {
+	server := newTestServer(t, CommandCompleted)
+	defer server.Close()
+
+	_, err := NewClient(server.URL, "wrong").Backup(context.TODO(), time.Millisecond)
+	if err == nil || !strings.Contains(err.Error(), "401") {
+		t.Errorf("expected unauthorized error, got %v", err)
+	}
+}
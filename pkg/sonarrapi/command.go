@@ -0,0 +1,75 @@
+package sonarrapi
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// Command states reported by Sonarr
+const (
+	CommandQueued    = "queued"
+	CommandStarted   = "started"
+	CommandCompleted = "completed"
+	CommandFailed    = "failed"
+	CommandAborted   = "aborted"
+	CommandCancelled = "cancelled"
+	CommandOrphaned  = "orphaned"
+)
+
+// Command is a task queued in Sonarr
+type Command struct {
+	ID      int        `json:"id,omitempty"`
+	Name    string     `json:"name"`
+	Status  string     `json:"status,omitempty"`
+	Message string     `json:"message,omitempty"`
+	Queued  *time.Time `json:"queued,omitempty"`
+	Ended   *time.Time `json:"ended,omitempty"`
+}
+
+// Done reports whether the command stopped running
+func (c *Command) Done() bool {
+	switch c.Status {
+	case CommandQueued, CommandStarted:
+		return false
+	}
+	return true
+}
+
+// RunCommand queues the command name
+func (c *Client) RunCommand(ctx context.Context, name string) (*Command, error) {
+	command := &Command{}
+	if err := c.do(ctx, http.MethodPost, "/command", &Command{Name: name}, command); err != nil {
+		return nil, err
+	}
+	return command, nil
+}
+
+// GetCommand returns the command with id
+func (c *Client) GetCommand(ctx context.Context, id int) (*Command, error) {
+	command := &Command{}
+	if err := c.do(ctx, http.MethodGet, fmt.Sprintf("/command/%d", id), nil, command); err != nil {
+		return nil, err
+	}
+	return command, nil
+}
+
+// WaitForCommand polls the command with id every interval until it stops running or ctx is done
+func (c *Client) WaitForCommand(ctx context.Context, id int, interval time.Duration) (*Command, error) {
+	for {
+		command, err := c.GetCommand(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+		if command.Done() {
+			return command, nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return command, ctx.Err()
+		case <-time.After(interval):
+		}
+	}
+}
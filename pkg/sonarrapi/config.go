@@ -0,0 +1,108 @@
+package sonarrapi
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+)
+
+// HostConfig holds the settings Sonarr keeps in config.xml.  Fields not declared are kept when decoding, so the
+// settings can be sent back unchanged.
+type HostConfig struct {
+	ID                   int    `json:"id"`
+	Port                 int    `json:"port"`
+	URLBase              string `json:"urlBase"`
+	AuthenticationMethod string `json:"authenticationMethod"`
+	LogLevel             string `json:"logLevel"`
+	Branch               string `json:"branch"`
+	AnalyticsEnabled     bool   `json:"analyticsEnabled"`
+	EnableSSL            bool   `json:"enableSsl"`
+	SSLPort              int    `json:"sslPort"`
+	SSLCertPath          string `json:"sslCertPath"`
+
+	raw map[string]json.RawMessage
+}
+
+// UnmarshalJSON keeps the fields not declared by HostConfig
+func (h *HostConfig) UnmarshalJSON(data []byte) error {
+	type hostConfig HostConfig
+	raw, err := decodeKeepingFields(data, (*hostConfig)(h))
+	h.raw = raw
+	return err
+}
+
+// MarshalJSON adds the fields kept by UnmarshalJSON
+func (h HostConfig) MarshalJSON() ([]byte, error) {
+	type hostConfig HostConfig
+	return encodeKeptFields(hostConfig(h), h.raw)
+}
+
+// MediaManagementConfig holds the settings Sonarr uses when importing and organizing episode files.  Fields not
+// declared are kept when decoding, so the settings can be sent back unchanged.
+type MediaManagementConfig struct {
+	ID                            int    `json:"id"`
+	CreateEmptySeriesFolders      bool   `json:"createEmptySeriesFolders"`
+	DeleteEmptyFolders            bool   `json:"deleteEmptyFolders"`
+	CopyUsingHardlinks            bool   `json:"copyUsingHardlinks"`
+	ImportExtraFiles              bool   `json:"importExtraFiles"`
+	ExtraFileExtensions           string `json:"extraFileExtensions"`
+	RecycleBin                    string `json:"recycleBin"`
+	RecycleBinCleanupDays         int    `json:"recycleBinCleanupDays"`
+	MinimumFreeSpaceWhenImporting int    `json:"minimumFreeSpaceWhenImporting"`
+	SetPermissionsLinux           bool   `json:"setPermissionsLinux"`
+	ChmodFolder                   string `json:"chmodFolder"`
+
+	raw map[string]json.RawMessage
+}
+
+// UnmarshalJSON keeps the fields not declared by MediaManagementConfig
+func (m *MediaManagementConfig) UnmarshalJSON(data []byte) error {
+	type mediaManagementConfig MediaManagementConfig
+	raw, err := decodeKeepingFields(data, (*mediaManagementConfig)(m))
+	m.raw = raw
+	return err
+}
+
+// MarshalJSON adds the fields kept by UnmarshalJSON
+func (m MediaManagementConfig) MarshalJSON() ([]byte, error) {
+	type mediaManagementConfig MediaManagementConfig
+	return encodeKeptFields(mediaManagementConfig(m), m.raw)
+}
+
+// GetHostConfig returns the host settings of Sonarr
+func (c *Client) GetHostConfig(ctx context.Context) (*HostConfig, error) {
+	config := &HostConfig{}
+	if err := c.do(ctx, http.MethodGet, "/config/host", nil, config); err != nil {
+		return nil, err
+	}
+	return config, nil
+}
+
+// UpdateHostConfig replaces the host settings of Sonarr.  Sonarr applies changes to the port, URL base and SSL
+// settings when it restarts.
+func (c *Client) UpdateHostConfig(ctx context.Context, config *HostConfig) (*HostConfig, error) {
+	updated := &HostConfig{}
+	if err := c.do(ctx, http.MethodPut, fmt.Sprintf("/config/host/%d", config.ID), config, updated); err != nil {
+		return nil, err
+	}
+	return updated, nil
+}
+
+// GetMediaManagementConfig returns the media management settings of Sonarr
+func (c *Client) GetMediaManagementConfig(ctx context.Context) (*MediaManagementConfig, error) {
+	config := &MediaManagementConfig{}
+	if err := c.do(ctx, http.MethodGet, "/config/mediamanagement", nil, config); err != nil {
+		return nil, err
+	}
+	return config, nil
+}
+
+// UpdateMediaManagementConfig replaces the media management settings of Sonarr
+func (c *Client) UpdateMediaManagementConfig(ctx context.Context, config *MediaManagementConfig) (*MediaManagementConfig, error) {
+	updated := &MediaManagementConfig{}
+	if err := c.do(ctx, http.MethodPut, fmt.Sprintf("/config/mediamanagement/%d", config.ID), config, updated); err != nil {
+		return nil, err
+	}
+	return updated, nil
+}
@@ -0,0 +1,89 @@
+package sonarrapi
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+)
+
+// CustomFormatSpecification is a condition releases must meet to match a custom format
+type CustomFormatSpecification struct {
+	Name           string  `json:"name"`
+	Implementation string  `json:"implementation"`
+	Negate         bool    `json:"negate"`
+	Required       bool    `json:"required"`
+	Fields         []Field `json:"fields"`
+}
+
+// UnmarshalJSON also accepts fields as an object of names and values, as used by Sonarr exports and the TRaSH
+// guides
+func (s *CustomFormatSpecification) UnmarshalJSON(data []byte) error {
+	type specification CustomFormatSpecification
+	var raw struct {
+		specification
+		Fields json.RawMessage `json:"fields"`
+	}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+	*s = CustomFormatSpecification(raw.specification)
+	s.Fields = nil
+	if len(raw.Fields) == 0 || string(raw.Fields) == "null" {
+		return nil
+	}
+	if raw.Fields[0] == '[' {
+		return json.Unmarshal(raw.Fields, &s.Fields)
+	}
+
+	var fields map[string]interface{}
+	if err := json.Unmarshal(raw.Fields, &fields); err != nil {
+		return err
+	}
+	for name, value := range fields {
+		s.Fields = append(s.Fields, Field{Name: name, Value: value})
+	}
+	sort.Slice(s.Fields, func(i, j int) bool { return s.Fields[i].Name < s.Fields[j].Name })
+	return nil
+}
+
+// CustomFormat scores releases in quality profiles.  Custom formats are only supported by Sonarr v4.
+type CustomFormat struct {
+	ID                              int                         `json:"id,omitempty"`
+	Name                            string                      `json:"name"`
+	IncludeCustomFormatWhenRenaming bool                        `json:"includeCustomFormatWhenRenaming"`
+	Specifications                  []CustomFormatSpecification `json:"specifications"`
+}
+
+// GetCustomFormats returns the custom formats of Sonarr
+func (c *Client) GetCustomFormats(ctx context.Context) ([]CustomFormat, error) {
+	var formats []CustomFormat
+	if err := c.do(ctx, http.MethodGet, "/customformat", nil, &formats); err != nil {
+		return nil, err
+	}
+	return formats, nil
+}
+
+// CreateCustomFormat adds format and returns it as stored by Sonarr
+func (c *Client) CreateCustomFormat(ctx context.Context, format *CustomFormat) (*CustomFormat, error) {
+	created := &CustomFormat{}
+	if err := c.do(ctx, http.MethodPost, "/customformat", format, created); err != nil {
+		return nil, err
+	}
+	return created, nil
+}
+
+// UpdateCustomFormat replaces the custom format with the ID of format
+func (c *Client) UpdateCustomFormat(ctx context.Context, format *CustomFormat) (*CustomFormat, error) {
+	updated := &CustomFormat{}
+	if err := c.do(ctx, http.MethodPut, fmt.Sprintf("/customformat/%d", format.ID), format, updated); err != nil {
+		return nil, err
+	}
+	return updated, nil
+}
+
+// DeleteCustomFormat removes the custom format with id
+func (c *Client) DeleteCustomFormat(ctx context.Context, id int) error {
+	return c.do(ctx, http.MethodDelete, fmt.Sprintf("/customformat/%d", id), nil, nil)
+}
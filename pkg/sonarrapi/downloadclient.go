@@ -0,0 +1,67 @@
+package sonarrapi
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+)
+
+// DownloadClient is a usenet or torrent client Sonarr sends releases to
+type DownloadClient struct {
+	Provider
+	Enable bool `json:"enable"`
+
+	raw map[string]json.RawMessage
+}
+
+// UnmarshalJSON keeps the fields not declared by DownloadClient
+func (d *DownloadClient) UnmarshalJSON(data []byte) error {
+	type downloadClient DownloadClient
+	raw, err := decodeKeepingFields(data, (*downloadClient)(d))
+	d.raw = raw
+	return err
+}
+
+// MarshalJSON adds the fields kept by UnmarshalJSON
+func (d DownloadClient) MarshalJSON() ([]byte, error) {
+	type downloadClient DownloadClient
+	return encodeKeptFields(downloadClient(d), d.raw)
+}
+
+// GetDownloadClients returns the download clients of Sonarr
+func (c *Client) GetDownloadClients(ctx context.Context) ([]DownloadClient, error) {
+	var clients []DownloadClient
+	if err := c.do(ctx, http.MethodGet, "/downloadclient", nil, &clients); err != nil {
+		return nil, err
+	}
+	return clients, nil
+}
+
+// CreateDownloadClient adds dc and returns it as stored by Sonarr
+func (c *Client) CreateDownloadClient(ctx context.Context, dc *DownloadClient) (*DownloadClient, error) {
+	created := &DownloadClient{}
+	if err := c.do(ctx, http.MethodPost, "/downloadclient", dc, created); err != nil {
+		return nil, err
+	}
+	return created, nil
+}
+
+// UpdateDownloadClient replaces the download client with the ID of dc
+func (c *Client) UpdateDownloadClient(ctx context.Context, dc *DownloadClient) (*DownloadClient, error) {
+	updated := &DownloadClient{}
+	if err := c.do(ctx, http.MethodPut, fmt.Sprintf("/downloadclient/%d", dc.ID), dc, updated); err != nil {
+		return nil, err
+	}
+	return updated, nil
+}
+
+// DeleteDownloadClient removes the download client with id
+func (c *Client) DeleteDownloadClient(ctx context.Context, id int) error {
+	return c.do(ctx, http.MethodDelete, fmt.Sprintf("/downloadclient/%d", id), nil, nil)
+}
+
+// TestDownloadClient has Sonarr test the connection to dc.  Failures are returned as an APIError.
+func (c *Client) TestDownloadClient(ctx context.Context, dc *DownloadClient) error {
+	return c.do(ctx, http.MethodPost, "/downloadclient/test", dc, nil)
+}
@@ -0,0 +1,63 @@
+package sonarrapi
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// APIError is returned for requests Sonarr answered with a status other than 2xx
+type APIError struct {
+	Method     string
+	Path       string
+	StatusCode int
+
+	// Message is the response body
+	Message string
+
+	// Failures are the validation failures of 400 responses
+	Failures []ValidationFailure
+}
+
+// ValidationFailure is a setting Sonarr rejected, or a failed connection test
+type ValidationFailure struct {
+	PropertyName string `json:"propertyName"`
+	ErrorMessage string `json:"errorMessage"`
+	Severity     string `json:"severity,omitempty"`
+}
+
+func (e *APIError) Error() string {
+	message := e.Message
+	if len(e.Failures) > 0 {
+		messages := make([]string, 0, len(e.Failures))
+		for _, f := range e.Failures {
+			messages = append(messages, f.ErrorMessage)
+		}
+		message = strings.Join(messages, "; ")
+	}
+	return fmt.Sprintf("%s %s: %d %s: %s", e.Method, e.Path, e.StatusCode, http.StatusText(e.StatusCode), message)
+}
+
+func statusCode(err error) int {
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		return apiErr.StatusCode
+	}
+	return 0
+}
+
+// IsNotFound reports whether err is an APIError for a resource that does not exist
+func IsNotFound(err error) bool {
+	return statusCode(err) == http.StatusNotFound
+}
+
+// IsUnauthorized reports whether err is an APIError for a rejected API key
+func IsUnauthorized(err error) bool {
+	return statusCode(err) == http.StatusUnauthorized
+}
+
+// IsBadRequest reports whether err is an APIError for a resource Sonarr failed to validate
+func IsBadRequest(err error) bool {
+	return statusCode(err) == http.StatusBadRequest
+}
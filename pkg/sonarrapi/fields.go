@@ -0,0 +1,35 @@
+package sonarrapi
+
+import "encoding/json"
+
+// decodeKeepingFields decodes data into v, a pointer to a type without custom decoding, and returns all fields of
+// data, so the fields v does not declare can be sent back with encodeKeptFields
+func decodeKeepingFields(data []byte, v interface{}) (map[string]json.RawMessage, error) {
+	if err := json.Unmarshal(data, v); err != nil {
+		return nil, err
+	}
+	raw := map[string]json.RawMessage{}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return nil, err
+	}
+	return raw, nil
+}
+
+// encodeKeptFields encodes v, a type without custom encoding, adding the fields of raw it does not declare
+func encodeKeptFields(v interface{}, raw map[string]json.RawMessage) ([]byte, error) {
+	data, err := json.Marshal(v)
+	if err != nil || raw == nil {
+		return data, err
+	}
+
+	fields := map[string]json.RawMessage{}
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return nil, err
+	}
+	for name, value := range raw {
+		if _, ok := fields[name]; !ok {
+			fields[name] = value
+		}
+	}
+	return json.Marshal(fields)
+}
@@ -0,0 +1,69 @@
+package sonarrapi
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+)
+
+// Indexer is a usenet indexer or torrent tracker Sonarr searches for releases
+type Indexer struct {
+	Provider
+	EnableRss               bool `json:"enableRss"`
+	EnableAutomaticSearch   bool `json:"enableAutomaticSearch"`
+	EnableInteractiveSearch bool `json:"enableInteractiveSearch"`
+
+	raw map[string]json.RawMessage
+}
+
+// UnmarshalJSON keeps the fields not declared by Indexer
+func (i *Indexer) UnmarshalJSON(data []byte) error {
+	type indexer Indexer
+	raw, err := decodeKeepingFields(data, (*indexer)(i))
+	i.raw = raw
+	return err
+}
+
+// MarshalJSON adds the fields kept by UnmarshalJSON
+func (i Indexer) MarshalJSON() ([]byte, error) {
+	type indexer Indexer
+	return encodeKeptFields(indexer(i), i.raw)
+}
+
+// GetIndexers returns the indexers of Sonarr
+func (c *Client) GetIndexers(ctx context.Context) ([]Indexer, error) {
+	var indexers []Indexer
+	if err := c.do(ctx, http.MethodGet, "/indexer", nil, &indexers); err != nil {
+		return nil, err
+	}
+	return indexers, nil
+}
+
+// CreateIndexer adds indexer and returns it as stored by Sonarr
+func (c *Client) CreateIndexer(ctx context.Context, indexer *Indexer) (*Indexer, error) {
+	created := &Indexer{}
+	if err := c.do(ctx, http.MethodPost, "/indexer", indexer, created); err != nil {
+		return nil, err
+	}
+	return created, nil
+}
+
+// UpdateIndexer replaces the indexer with the ID of indexer
+func (c *Client) UpdateIndexer(ctx context.Context, indexer *Indexer) (*Indexer, error) {
+	updated := &Indexer{}
+	if err := c.do(ctx, http.MethodPut, fmt.Sprintf("/indexer/%d", indexer.ID), indexer, updated); err != nil {
+		return nil, err
+	}
+	return updated, nil
+}
+
+// DeleteIndexer removes the indexer with id
+func (c *Client) DeleteIndexer(ctx context.Context, id int) error {
+	return c.do(ctx, http.MethodDelete, fmt.Sprintf("/indexer/%d", id), nil, nil)
+}
+
+// TestIndexer has Sonarr test the connection to indexer.  Failures are returned as an APIError.
+func (c *Client) TestIndexer(ctx context.Context, indexer *Indexer) error {
+	return c.do(ctx, http.MethodPost, "/indexer/test", indexer, nil)
+}
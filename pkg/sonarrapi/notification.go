@@ -0,0 +1,72 @@
+package sonarrapi
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+)
+
+// Notification is a connection Sonarr reports events to, e.g. a webhook or chat service
+type Notification struct {
+	Provider
+	OnGrab                bool `json:"onGrab"`
+	OnDownload            bool `json:"onDownload"`
+	OnUpgrade             bool `json:"onUpgrade"`
+	OnRename              bool `json:"onRename"`
+	OnHealthIssue         bool `json:"onHealthIssue"`
+	IncludeHealthWarnings bool `json:"includeHealthWarnings"`
+
+	raw map[string]json.RawMessage
+}
+
+// UnmarshalJSON keeps the fields not declared by Notification
+func (n *Notification) UnmarshalJSON(data []byte) error {
+	type notification Notification
+	raw, err := decodeKeepingFields(data, (*notification)(n))
+	n.raw = raw
+	return err
+}
+
+// MarshalJSON adds the fields kept by UnmarshalJSON
+func (n Notification) MarshalJSON() ([]byte, error) {
+	type notification Notification
+	return encodeKeptFields(notification(n), n.raw)
+}
+
+// GetNotifications returns the notifications of Sonarr
+func (c *Client) GetNotifications(ctx context.Context) ([]Notification, error) {
+	var notifications []Notification
+	if err := c.do(ctx, http.MethodGet, "/notification", nil, &notifications); err != nil {
+		return nil, err
+	}
+	return notifications, nil
+}
+
+// CreateNotification adds notification and returns it as stored by Sonarr
+func (c *Client) CreateNotification(ctx context.Context, notification *Notification) (*Notification, error) {
+	created := &Notification{}
+	if err := c.do(ctx, http.MethodPost, "/notification", notification, created); err != nil {
+		return nil, err
+	}
+	return created, nil
+}
+
+// UpdateNotification replaces the notification with the ID of notification
+func (c *Client) UpdateNotification(ctx context.Context, notification *Notification) (*Notification, error) {
+	updated := &Notification{}
+	if err := c.do(ctx, http.MethodPut, fmt.Sprintf("/notification/%d", notification.ID), notification, updated); err != nil {
+		return nil, err
+	}
+	return updated, nil
+}
+
+// DeleteNotification removes the notification with id
+func (c *Client) DeleteNotification(ctx context.Context, id int) error {
+	return c.do(ctx, http.MethodDelete, fmt.Sprintf("/notification/%d", id), nil, nil)
+}
+
+// TestNotification has Sonarr send a test message through notification.  Failures are returned as an APIError.
+func (c *Client) TestNotification(ctx context.Context, notification *Notification) error {
+	return c.do(ctx, http.MethodPost, "/notification/test", notification, nil)
+}
@@ -0,0 +1,47 @@
+package sonarrapi
+
+// Download protocols of download clients and indexers
+const (
+	ProtocolUsenet  = "usenet"
+	ProtocolTorrent = "torrent"
+)
+
+// Field is a setting of a download client, indexer or notification.  The fields available depend on the implementation.
+type Field struct {
+	Name  string      `json:"name"`
+	Value interface{} `json:"value,omitempty"`
+}
+
+// Provider holds the settings Sonarr shares between download clients, indexers and notifications.  The types embedding
+// it keep the fields they do not declare when decoding, so providers returned by Sonarr can be sent back unchanged.
+type Provider struct {
+	ID             int     `json:"id,omitempty"`
+	Name           string  `json:"name"`
+	Implementation string  `json:"implementation"`
+	ConfigContract string  `json:"configContract"`
+	Protocol       string  `json:"protocol,omitempty"`
+	Priority       int     `json:"priority,omitempty"`
+	Fields         []Field `json:"fields"`
+	Tags           []int   `json:"tags"`
+}
+
+// Field returns the value of the field name, or nil when the provider has no such field
+func (p *Provider) Field(name string) interface{} {
+	for _, f := range p.Fields {
+		if f.Name == name {
+			return f.Value
+		}
+	}
+	return nil
+}
+
+// SetField sets the value of the field name, adding the field when missing
+func (p *Provider) SetField(name string, value interface{}) {
+	for i := range p.Fields {
+		if p.Fields[i].Name == name {
+			p.Fields[i].Value = value
+			return
+		}
+	}
+	p.Fields = append(p.Fields, Field{Name: name, Value: value})
+}
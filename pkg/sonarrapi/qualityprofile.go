@@ -0,0 +1,105 @@
+package sonarrapi
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+)
+
+// Quality is a release quality known to Sonarr, like HDTV-720p
+type Quality struct {
+	ID         int    `json:"id"`
+	Name       string `json:"name"`
+	Source     string `json:"source,omitempty"`
+	Resolution int    `json:"resolution,omitempty"`
+}
+
+// QualityProfileItem is a quality, or a group of qualities, of a quality profile
+type QualityProfileItem struct {
+	// ID and Name are set for groups only
+	ID      int                  `json:"id,omitempty"`
+	Name    string               `json:"name,omitempty"`
+	Quality *Quality             `json:"quality,omitempty"`
+	Items   []QualityProfileItem `json:"items"`
+	Allowed bool                 `json:"allowed"`
+}
+
+// ProfileFormatItem is the score of a custom format in a quality profile
+type ProfileFormatItem struct {
+	Format int    `json:"format"`
+	Name   string `json:"name,omitempty"`
+	Score  int    `json:"score"`
+}
+
+// QualityProfile selects the qualities Sonarr downloads and upgrades to.  The custom format scores are only
+// supported by Sonarr v4.  Fields not declared are kept when decoding, so profiles returned by Sonarr can be sent back
+// unchanged.
+type QualityProfile struct {
+	ID                int                  `json:"id,omitempty"`
+	Name              string               `json:"name"`
+	UpgradeAllowed    bool                 `json:"upgradeAllowed"`
+	Cutoff            int                  `json:"cutoff"`
+	Items             []QualityProfileItem `json:"items"`
+	MinFormatScore    int                  `json:"minFormatScore,omitempty"`
+	CutoffFormatScore int                  `json:"cutoffFormatScore,omitempty"`
+	FormatItems       []ProfileFormatItem  `json:"formatItems,omitempty"`
+
+	raw map[string]json.RawMessage
+}
+
+// UnmarshalJSON keeps the fields not declared by QualityProfile
+func (p *QualityProfile) UnmarshalJSON(data []byte) error {
+	type qualityProfile QualityProfile
+	raw, err := decodeKeepingFields(data, (*qualityProfile)(p))
+	p.raw = raw
+	return err
+}
+
+// MarshalJSON adds the fields kept by UnmarshalJSON
+func (p QualityProfile) MarshalJSON() ([]byte, error) {
+	type qualityProfile QualityProfile
+	return encodeKeptFields(qualityProfile(p), p.raw)
+}
+
+// GetQualityProfiles returns the quality profiles of Sonarr
+func (c *Client) GetQualityProfiles(ctx context.Context) ([]QualityProfile, error) {
+	var profiles []QualityProfile
+	if err := c.do(ctx, http.MethodGet, "/qualityprofile", nil, &profiles); err != nil {
+		return nil, err
+	}
+	return profiles, nil
+}
+
+// CreateQualityProfile adds profile and returns it as stored by Sonarr
+func (c *Client) CreateQualityProfile(ctx context.Context, profile *QualityProfile) (*QualityProfile, error) {
+	created := &QualityProfile{}
+	if err := c.do(ctx, http.MethodPost, "/qualityprofile", profile, created); err != nil {
+		return nil, err
+	}
+	return created, nil
+}
+
+// UpdateQualityProfile replaces the quality profile with the ID of profile
+func (c *Client) UpdateQualityProfile(ctx context.Context, profile *QualityProfile) (*QualityProfile, error) {
+	updated := &QualityProfile{}
+	if err := c.do(ctx, http.MethodPut, fmt.Sprintf("/qualityprofile/%d", profile.ID), profile, updated); err != nil {
+		return nil, err
+	}
+	return updated, nil
+}
+
+// DeleteQualityProfile removes the quality profile with id
+func (c *Client) DeleteQualityProfile(ctx context.Context, id int) error {
+	return c.do(ctx, http.MethodDelete, fmt.Sprintf("/qualityprofile/%d", id), nil, nil)
+}
+
+// GetQualityProfileSchema returns a quality profile holding all qualities known to Sonarr, none of them allowed, to
+// base new quality profiles on
+func (c *Client) GetQualityProfileSchema(ctx context.Context) (*QualityProfile, error) {
+	profile := &QualityProfile{}
+	if err := c.do(ctx, http.MethodGet, "/qualityprofile/schema", nil, profile); err != nil {
+		return nil, err
+	}
+	return profile, nil
+}
@@ -0,0 +1,68 @@
+package sonarrapi
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+)
+
+// ReleaseProfile requires or rejects releases by terms in their titles.  Fields not declared are kept when decoding, so
+// profiles returned by Sonarr can be sent back unchanged.
+type ReleaseProfile struct {
+	ID        int      `json:"id,omitempty"`
+	Name      string   `json:"name"`
+	Enabled   bool     `json:"enabled"`
+	Required  []string `json:"required"`
+	Ignored   []string `json:"ignored"`
+	IndexerID int      `json:"indexerId"`
+	Tags      []int    `json:"tags"`
+
+	raw map[string]json.RawMessage
+}
+
+// UnmarshalJSON keeps the fields not declared by ReleaseProfile
+func (p *ReleaseProfile) UnmarshalJSON(data []byte) error {
+	type releaseProfile ReleaseProfile
+	raw, err := decodeKeepingFields(data, (*releaseProfile)(p))
+	p.raw = raw
+	return err
+}
+
+// MarshalJSON adds the fields kept by UnmarshalJSON
+func (p ReleaseProfile) MarshalJSON() ([]byte, error) {
+	type releaseProfile ReleaseProfile
+	return encodeKeptFields(releaseProfile(p), p.raw)
+}
+
+// GetReleaseProfiles returns the release profiles of Sonarr
+func (c *Client) GetReleaseProfiles(ctx context.Context) ([]ReleaseProfile, error) {
+	var profiles []ReleaseProfile
+	if err := c.do(ctx, http.MethodGet, "/releaseprofile", nil, &profiles); err != nil {
+		return nil, err
+	}
+	return profiles, nil
+}
+
+// CreateReleaseProfile adds profile and returns it as stored by Sonarr
+func (c *Client) CreateReleaseProfile(ctx context.Context, profile *ReleaseProfile) (*ReleaseProfile, error) {
+	created := &ReleaseProfile{}
+	if err := c.do(ctx, http.MethodPost, "/releaseprofile", profile, created); err != nil {
+		return nil, err
+	}
+	return created, nil
+}
+
+// UpdateReleaseProfile replaces the release profile with the ID of profile
+func (c *Client) UpdateReleaseProfile(ctx context.Context, profile *ReleaseProfile) (*ReleaseProfile, error) {
+	updated := &ReleaseProfile{}
+	if err := c.do(ctx, http.MethodPut, fmt.Sprintf("/releaseprofile/%d", profile.ID), profile, updated); err != nil {
+		return nil, err
+	}
+	return updated, nil
+}
+
+// DeleteReleaseProfile removes the release profile with id
+func (c *Client) DeleteReleaseProfile(ctx context.Context, id int) error {
+	return c.do(ctx, http.MethodDelete, fmt.Sprintf("/releaseprofile/%d", id), nil, nil)
+}
@@ -0,0 +1,356 @@
+package sonarrapi_test
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/parflesh/sonarr-operator/pkg/sonarrapi"
+	"github.com/parflesh/sonarr-operator/pkg/sonarrapi/sonarrapitest"
+)
+
+func TestSystemStatusAndHealth(t *testing.T) {
+	server := sonarrapitest.NewServer()
+	defer server.Close()
+	server.Health = []sonarrapi.HealthCheck{{Source: "IndexerCheck", Type: sonarrapi.HealthWarning, Message: "No indexers available"}}
+
+	status, err := server.Client().GetSystemStatus(context.TODO())
+	if err != nil {
+		t.Fatalf("get status: (%v)", err)
+	}
+	if status.Version != server.Status.Version {
+		t.Errorf("unexpected version: %s", status.Version)
+	}
+	checks, err := server.Client().GetHealth(context.TODO())
+	if err != nil {
+		t.Fatalf("get health: (%v)", err)
+	}
+	if len(checks) != 1 || checks[0].Type != sonarrapi.HealthWarning {
+		t.Errorf("unexpected health checks: %v", checks)
+	}
+}
+
+func TestRootFolders(t *testing.T) {
+	server := sonarrapitest.NewServer()
+	defer server.Close()
+	c := server.Client()
+
+	folder, err := c.CreateRootFolder(context.TODO(), "/tv")
+	if err != nil {
+		t.Fatalf("create root folder: (%v)", err)
+	}
+	if folder.ID == 0 || folder.Path != "/tv" {
+		t.Errorf("unexpected root folder: %v", folder)
+	}
+	if err := c.DeleteRootFolder(context.TODO(), folder.ID); err != nil {
+		t.Fatalf("delete root folder: (%v)", err)
+	}
+	folders, err := c.GetRootFolders(context.TODO())
+	if err != nil || len(folders) != 0 {
+		t.Errorf("root folder not deleted: %v (%v)", folders, err)
+	}
+	if err := c.DeleteRootFolder(context.TODO(), folder.ID); !sonarrapi.IsNotFound(err) {
+		t.Errorf("expected not found error, got %v", err)
+	}
+}
+
+func TestDownloadClients(t *testing.T) {
+	server := sonarrapitest.NewServer()
+	defer server.Close()
+	c := server.Client()
+
+	dc := &sonarrapi.DownloadClient{
+		Provider: sonarrapi.Provider{
+			Name:           "sabnzbd",
+			Implementation: "Sabnzbd",
+			ConfigContract: "SabnzbdSettings",
+			Protocol:       sonarrapi.ProtocolUsenet,
+			Fields:         []sonarrapi.Field{{Name: "host", Value: "sabnzbd"}},
+		},
+		Enable: true,
+	}
+	created, err := c.CreateDownloadClient(context.TODO(), dc)
+	if err != nil {
+		t.Fatalf("create download client: (%v)", err)
+	}
+	created.SetField("port", float64(8080))
+	if _, err := c.UpdateDownloadClient(context.TODO(), created); err != nil {
+		t.Fatalf("update download client: (%v)", err)
+	}
+	clients, err := c.GetDownloadClients(context.TODO())
+	if err != nil {
+		t.Fatalf("get download clients: (%v)", err)
+	}
+	if len(clients) != 1 || clients[0].Field("host") != "sabnzbd" || clients[0].Field("port") != float64(8080) || !clients[0].Enable {
+		t.Errorf("unexpected download clients: %v", clients)
+	}
+	if err := c.TestDownloadClient(context.TODO(), created); err != nil {
+		t.Errorf("test download client: (%v)", err)
+	}
+	server.TestFailures = map[string]string{"sabnzbd": "Unable to connect to SABnzbd"}
+	err = c.TestDownloadClient(context.TODO(), created)
+	if apiErr, ok := err.(*sonarrapi.APIError); !ok || !sonarrapi.IsBadRequest(err) || len(apiErr.Failures) != 1 || apiErr.Failures[0].ErrorMessage != "Unable to connect to SABnzbd" {
+		t.Errorf("unexpected test error: %v", err)
+	}
+	if err := c.DeleteDownloadClient(context.TODO(), created.ID); err != nil {
+		t.Errorf("delete download client: (%v)", err)
+	}
+}
+
+func TestIndexers(t *testing.T) {
+	server := sonarrapitest.NewServer()
+	defer server.Close()
+	server.Add(sonarrapitest.Indexers, sonarrapi.Indexer{Provider: sonarrapi.Provider{Name: "nzbgeek", Implementation: "Newznab"}, EnableRss: true})
+
+	indexers, err := server.Client().GetIndexers(context.TODO())
+	if err != nil {
+		t.Fatalf("get indexers: (%v)", err)
+	}
+	if len(indexers) != 1 || indexers[0].Name != "nzbgeek" || !indexers[0].EnableRss || indexers[0].ID == 0 {
+		t.Fatalf("unexpected indexers: %v", indexers)
+	}
+	indexers[0].EnableRss = false
+	if _, err := server.Client().UpdateIndexer(context.TODO(), &indexers[0]); err != nil {
+		t.Fatalf("update indexer: (%v)", err)
+	}
+	var stored []sonarrapi.Indexer
+	server.List(sonarrapitest.Indexers, &stored)
+	if len(stored) != 1 || stored[0].EnableRss {
+		t.Errorf("indexer not updated: %v", stored)
+	}
+}
+
+func TestNotifications(t *testing.T) {
+	server := sonarrapitest.NewServer()
+	defer server.Close()
+	c := server.Client()
+
+	notification := &sonarrapi.Notification{
+		Provider: sonarrapi.Provider{
+			Name:           "discord",
+			Implementation: "Discord",
+			ConfigContract: "DiscordSettings",
+			Fields:         []sonarrapi.Field{{Name: "webHookUrl", Value: "https://discord.com/api/webhooks/1/token"}},
+		},
+		OnGrab: true,
+	}
+	created, err := c.CreateNotification(context.TODO(), notification)
+	if err != nil {
+		t.Fatalf("create notification: (%v)", err)
+	}
+	created.OnDownload = true
+	if _, err := c.UpdateNotification(context.TODO(), created); err != nil {
+		t.Fatalf("update notification: (%v)", err)
+	}
+	notifications, err := c.GetNotifications(context.TODO())
+	if err != nil {
+		t.Fatalf("get notifications: (%v)", err)
+	}
+	if len(notifications) != 1 || !notifications[0].OnGrab || !notifications[0].OnDownload || notifications[0].OnUpgrade {
+		t.Errorf("unexpected notifications: %v", notifications)
+	}
+	server.TestFailures = map[string]string{"discord": "Unable to send test message"}
+	if err := c.TestNotification(context.TODO(), created); !sonarrapi.IsBadRequest(err) {
+		t.Errorf("unexpected test error: %v", err)
+	}
+	if err := c.DeleteNotification(context.TODO(), created.ID); err != nil {
+		t.Errorf("delete notification: (%v)", err)
+	}
+}
+
+func TestQualityProfiles(t *testing.T) {
+	server := sonarrapitest.NewServer()
+	defer server.Close()
+
+	profile := &sonarrapi.QualityProfile{
+		Name:   "HD",
+		Cutoff: 4,
+		Items: []sonarrapi.QualityProfileItem{
+			{Quality: &sonarrapi.Quality{ID: 4, Name: "HDTV-720p"}, Allowed: true},
+		},
+	}
+	created, err := server.Client().CreateQualityProfile(context.TODO(), profile)
+	if err != nil {
+		t.Fatalf("create quality profile: (%v)", err)
+	}
+	profiles, err := server.Client().GetQualityProfiles(context.TODO())
+	if err != nil {
+		t.Fatalf("get quality profiles: (%v)", err)
+	}
+	if len(profiles) != 1 || profiles[0].ID != created.ID || profiles[0].Items[0].Quality.Name != "HDTV-720p" {
+		t.Errorf("unexpected quality profiles: %v", profiles)
+	}
+}
+
+func TestKeptFields(t *testing.T) {
+	server := sonarrapitest.NewServer()
+	defer server.Close()
+	c := server.Client()
+
+	// Fields not declared by providers and profiles survive updates
+	server.Add(sonarrapitest.DownloadClients, map[string]interface{}{"name": "sabnzbd", "implementation": "Sabnzbd", "removeCompletedDownloads": true})
+	server.Add(sonarrapitest.QualityProfiles, map[string]interface{}{"name": "HD", "language": "English"})
+	server.Add(sonarrapitest.ReleaseProfiles, map[string]interface{}{"name": "x265", "includePreferredWhenRenaming": true})
+
+	clients, err := c.GetDownloadClients(context.TODO())
+	if err != nil || len(clients) != 1 {
+		t.Fatalf("get download clients: %v (%v)", clients, err)
+	}
+	clients[0].Enable = true
+	if _, err := c.UpdateDownloadClient(context.TODO(), &clients[0]); err != nil {
+		t.Fatalf("update download client: (%v)", err)
+	}
+	qualityProfiles, err := c.GetQualityProfiles(context.TODO())
+	if err != nil || len(qualityProfiles) != 1 {
+		t.Fatalf("get quality profiles: %v (%v)", qualityProfiles, err)
+	}
+	qualityProfiles[0].UpgradeAllowed = true
+	if _, err := c.UpdateQualityProfile(context.TODO(), &qualityProfiles[0]); err != nil {
+		t.Fatalf("update quality profile: (%v)", err)
+	}
+	releaseProfiles, err := c.GetReleaseProfiles(context.TODO())
+	if err != nil || len(releaseProfiles) != 1 {
+		t.Fatalf("get release profiles: %v (%v)", releaseProfiles, err)
+	}
+	releaseProfiles[0].Enabled = true
+	if _, err := c.UpdateReleaseProfile(context.TODO(), &releaseProfiles[0]); err != nil {
+		t.Fatalf("update release profile: (%v)", err)
+	}
+
+	var stored []map[string]interface{}
+	server.List(sonarrapitest.DownloadClients, &stored)
+	if stored[0]["removeCompletedDownloads"] != true || stored[0]["enable"] != true {
+		t.Errorf("unexpected download client: %v", stored[0])
+	}
+	server.List(sonarrapitest.QualityProfiles, &stored)
+	if stored[0]["language"] != "English" || stored[0]["upgradeAllowed"] != true {
+		t.Errorf("unexpected quality profile: %v", stored[0])
+	}
+	server.List(sonarrapitest.ReleaseProfiles, &stored)
+	if stored[0]["includePreferredWhenRenaming"] != true || stored[0]["enabled"] != true {
+		t.Errorf("unexpected release profile: %v", stored[0])
+	}
+}
+
+func TestSeries(t *testing.T) {
+	server := sonarrapitest.NewServer()
+	defer server.Close()
+	c := server.Client()
+	server.Lookup = []sonarrapi.Series{{Title: "The Expanse", TvdbID: 280619}}
+
+	if series, err := c.LookupSeries(context.TODO(), 1); err != nil || series != nil {
+		t.Errorf("unexpected lookup result: %v (%v)", series, err)
+	}
+	series, err := c.LookupSeries(context.TODO(), 280619)
+	if err != nil || series == nil {
+		t.Fatalf("lookup series: %v (%v)", series, err)
+	}
+	series.QualityProfileID = 1
+	if _, err := c.AddSeries(context.TODO(), series); err != nil {
+		t.Fatalf("add series: (%v)", err)
+	}
+
+	// Fields not declared by Series survive updates
+	server.Add(sonarrapitest.Series, map[string]interface{}{"title": "Firefly", "tvdbId": 78874, "network": "FOX"})
+	all, err := c.GetSeries(context.TODO())
+	if err != nil || len(all) != 2 {
+		t.Fatalf("get series: %v (%v)", all, err)
+	}
+	firefly, err := c.GetSeriesByTVDBID(context.TODO(), 78874)
+	if err != nil || firefly == nil || firefly.Title != "Firefly" {
+		t.Fatalf("get series by TVDB id: %v (%v)", firefly, err)
+	}
+	if missing, err := c.GetSeriesByTVDBID(context.TODO(), 1); err != nil || missing != nil {
+		t.Errorf("unexpected series: %v (%v)", missing, err)
+	}
+	all[1].Monitored = true
+	if _, err := c.UpdateSeries(context.TODO(), &all[1]); err != nil {
+		t.Fatalf("update series: (%v)", err)
+	}
+	var stored []map[string]interface{}
+	server.List(sonarrapitest.Series, &stored)
+	if stored[1]["network"] != "FOX" || stored[1]["monitored"] != true {
+		t.Errorf("unexpected series: %v", stored[1])
+	}
+}
+
+func TestSettings(t *testing.T) {
+	server := sonarrapitest.NewServer()
+	defer server.Close()
+	c := server.Client()
+
+	host, err := c.GetHostConfig(context.TODO())
+	if err != nil {
+		t.Fatalf("get host config: (%v)", err)
+	}
+	host.LogLevel = "debug"
+	if _, err := c.UpdateHostConfig(context.TODO(), host); err != nil {
+		t.Fatalf("update host config: (%v)", err)
+	}
+	// Fields not declared by HostConfig survive updates
+	stored := map[string]interface{}{}
+	server.Settings(sonarrapitest.HostConfig, &stored)
+	if stored["logLevel"] != "debug" || stored["apiKey"] != sonarrapitest.APIKey {
+		t.Errorf("unexpected host config: %v", stored)
+	}
+
+	media, err := c.GetMediaManagementConfig(context.TODO())
+	if err != nil {
+		t.Fatalf("get media management config: (%v)", err)
+	}
+	media.CopyUsingHardlinks = false
+	updated, err := c.UpdateMediaManagementConfig(context.TODO(), media)
+	if err != nil {
+		t.Fatalf("update media management config: (%v)", err)
+	}
+	if updated.CopyUsingHardlinks || updated.ChmodFolder != "755" {
+		t.Errorf("unexpected media management config: %+v", updated)
+	}
+}
+
+func TestFakeServerBackup(t *testing.T) {
+	server := sonarrapitest.NewServer()
+	defer server.Close()
+
+	backup, err := server.Client().Backup(context.TODO(), time.Millisecond)
+	if err != nil {
+		t.Fatalf("backup: (%v)", err)
+	}
+	if backup.Type != "manual" {
+		t.Errorf("unexpected backup: %v", backup)
+	}
+}
+
+func TestErrors(t *testing.T) {
+	server := sonarrapitest.NewServer()
+	defer server.Close()
+
+	_, err := sonarrapi.NewClient(server.URL, "wrong").GetSystemStatus(context.TODO())
+	if !sonarrapi.IsUnauthorized(err) {
+		t.Errorf("expected unauthorized error, got %v", err)
+	}
+	if sonarrapi.IsNotFound(err) {
+		t.Error("unauthorized error reported as not found")
+	}
+	_, err = server.Client().UpdateIndexer(context.TODO(), &sonarrapi.Indexer{Provider: sonarrapi.Provider{ID: 42}})
+	if apiErr, ok := err.(*sonarrapi.APIError); !ok || apiErr.StatusCode != http.StatusNotFound || apiErr.Method != http.MethodPut {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
+
+func TestTimeout(t *testing.T) {
+	block := make(chan struct{})
+	server := sonarrapitest.NewServer()
+	server.Config.Handler = http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		<-block
+	})
+	defer server.Close()
+	defer close(block)
+
+	c := server.Client()
+	c.Timeout = 10 * time.Millisecond
+	if _, err := c.GetSystemStatus(context.TODO()); err == nil {
+		t.Error("request did not time out")
+	}
+}
@@ -0,0 +1,38 @@
+package sonarrapi
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+)
+
+// RootFolder is a directory Sonarr keeps series in
+type RootFolder struct {
+	ID         int    `json:"id,omitempty"`
+	Path       string `json:"path"`
+	Accessible bool   `json:"accessible,omitempty"`
+	FreeSpace  int64  `json:"freeSpace,omitempty"`
+}
+
+// GetRootFolders returns the root folders of Sonarr
+func (c *Client) GetRootFolders(ctx context.Context) ([]RootFolder, error) {
+	var folders []RootFolder
+	if err := c.do(ctx, http.MethodGet, "/rootfolder", nil, &folders); err != nil {
+		return nil, err
+	}
+	return folders, nil
+}
+
+// CreateRootFolder adds the root folder at path
+func (c *Client) CreateRootFolder(ctx context.Context, path string) (*RootFolder, error) {
+	folder := &RootFolder{}
+	if err := c.do(ctx, http.MethodPost, "/rootfolder", &RootFolder{Path: path}, folder); err != nil {
+		return nil, err
+	}
+	return folder, nil
+}
+
+// DeleteRootFolder removes the root folder with id, leaving its files in place
+func (c *Client) DeleteRootFolder(ctx context.Context, id int) error {
+	return c.do(ctx, http.MethodDelete, fmt.Sprintf("/rootfolder/%d", id), nil, nil)
+}
@@ -0,0 +1,146 @@
+package sonarrapi
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+)
+
+// Series types
+const (
+	SeriesStandard = "standard"
+	SeriesDaily    = "daily"
+	SeriesAnime    = "anime"
+)
+
+// Season is a season of a series
+type Season struct {
+	SeasonNumber int  `json:"seasonNumber"`
+	Monitored    bool `json:"monitored"`
+}
+
+// SeriesStatistics counts the episodes of a series
+type SeriesStatistics struct {
+	SeasonCount       int     `json:"seasonCount"`
+	EpisodeFileCount  int     `json:"episodeFileCount"`
+	EpisodeCount      int     `json:"episodeCount"`
+	TotalEpisodeCount int     `json:"totalEpisodeCount"`
+	SizeOnDisk        int64   `json:"sizeOnDisk"`
+	PercentOfEpisodes float64 `json:"percentOfEpisodes"`
+}
+
+// AddSeriesOptions selects the episodes monitored and searched when adding a series
+type AddSeriesOptions struct {
+	// Monitor is one of all, future, missing, existing, pilot, firstSeason, latestSeason or none
+	Monitor                  string `json:"monitor,omitempty"`
+	SearchForMissingEpisodes bool   `json:"searchForMissingEpisodes"`
+}
+
+// Series is a TV series in Sonarr.  Fields not declared are kept when decoding, so series returned by Sonarr can be
+// sent back unchanged.
+type Series struct {
+	ID                int               `json:"id,omitempty"`
+	Title             string            `json:"title"`
+	TvdbID            int               `json:"tvdbId"`
+	Year              int               `json:"year,omitempty"`
+	Path              string            `json:"path,omitempty"`
+	RootFolderPath    string            `json:"rootFolderPath,omitempty"`
+	QualityProfileID  int               `json:"qualityProfileId"`
+	LanguageProfileID int               `json:"languageProfileId,omitempty"`
+	Monitored         bool              `json:"monitored"`
+	SeasonFolder      bool              `json:"seasonFolder"`
+	SeriesType        string            `json:"seriesType,omitempty"`
+	Seasons           []Season          `json:"seasons"`
+	Tags              []int             `json:"tags"`
+	Statistics        *SeriesStatistics `json:"statistics,omitempty"`
+	AddOptions        *AddSeriesOptions `json:"addOptions,omitempty"`
+
+	raw map[string]json.RawMessage
+}
+
+// UnmarshalJSON keeps the fields not declared by Series
+func (s *Series) UnmarshalJSON(data []byte) error {
+	type series Series
+	raw, err := decodeKeepingFields(data, (*series)(s))
+	s.raw = raw
+	return err
+}
+
+// MarshalJSON adds the fields kept by UnmarshalJSON
+func (s Series) MarshalJSON() ([]byte, error) {
+	type series Series
+	return encodeKeptFields(series(s), s.raw)
+}
+
+// GetSeries returns the series in Sonarr
+func (c *Client) GetSeries(ctx context.Context) ([]Series, error) {
+	var series []Series
+	if err := c.do(ctx, http.MethodGet, "/series", nil, &series); err != nil {
+		return nil, err
+	}
+	return series, nil
+}
+
+// GetSeriesByTVDBID returns the series in Sonarr with tvdbID, or nil when there is none
+func (c *Client) GetSeriesByTVDBID(ctx context.Context, tvdbID int) (*Series, error) {
+	var series []Series
+	if err := c.do(ctx, http.MethodGet, fmt.Sprintf("/series?tvdbId=%d", tvdbID), nil, &series); err != nil {
+		return nil, err
+	}
+	for i := range series {
+		if series[i].TvdbID == tvdbID {
+			return &series[i], nil
+		}
+	}
+	return nil, nil
+}
+
+// LookupSeries searches TheTVDB, through Sonarr, for the series with tvdbID.  It returns nil when there is none.
+func (c *Client) LookupSeries(ctx context.Context, tvdbID int) (*Series, error) {
+	var series []Series
+	path := "/series/lookup?term=" + url.QueryEscape(fmt.Sprintf("tvdb:%d", tvdbID))
+	if err := c.do(ctx, http.MethodGet, path, nil, &series); err != nil {
+		return nil, err
+	}
+	for i := range series {
+		if series[i].TvdbID == tvdbID {
+			return &series[i], nil
+		}
+	}
+	return nil, nil
+}
+
+// AddSeries adds series, usually returned by LookupSeries, to Sonarr
+func (c *Client) AddSeries(ctx context.Context, series *Series) (*Series, error) {
+	added := &Series{}
+	if err := c.do(ctx, http.MethodPost, "/series", series, added); err != nil {
+		return nil, err
+	}
+	return added, nil
+}
+
+// UpdateSeries replaces the series with the ID of series
+func (c *Client) UpdateSeries(ctx context.Context, series *Series) (*Series, error) {
+	updated := &Series{}
+	if err := c.do(ctx, http.MethodPut, fmt.Sprintf("/series/%d", series.ID), series, updated); err != nil {
+		return nil, err
+	}
+	return updated, nil
+}
+
+// LanguageProfile selects the languages Sonarr v3 downloads.  Sonarr v4 has no language profiles.
+type LanguageProfile struct {
+	ID   int    `json:"id"`
+	Name string `json:"name"`
+}
+
+// GetLanguageProfiles returns the language profiles of Sonarr v3
+func (c *Client) GetLanguageProfiles(ctx context.Context) ([]LanguageProfile, error) {
+	var profiles []LanguageProfile
+	if err := c.do(ctx, http.MethodGet, "/languageprofile", nil, &profiles); err != nil {
+		return nil, err
+	}
+	return profiles, nil
+}
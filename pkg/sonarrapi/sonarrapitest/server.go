@@ -0,0 +1,426 @@
+// Package sonarrapitest provides an in-memory Sonarr API for tests of code using sonarrapi.
+package sonarrapitest
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/parflesh/sonarr-operator/pkg/sonarrapi"
+)
+
+// Resources served by Server, named after their API paths
+const (
+	RootFolders      = "rootfolder"
+	DownloadClients  = "downloadclient"
+	Indexers         = "indexer"
+	QualityProfiles  = "qualityprofile"
+	ReleaseProfiles  = "releaseprofile"
+	CustomFormats    = "customformat"
+	Series           = "series"
+	LanguageProfiles = "languageprofile"
+	Notifications    = "notification"
+	Backups          = "system/backup"
+)
+
+// Settings served by Server, named after their API paths
+const (
+	HostConfig            = "config/host"
+	MediaManagementConfig = "config/mediamanagement"
+)
+
+// APIKey is the API key accepted by servers returned by NewServer
+const APIKey = "0123456789abcdef0123456789abcdef"
+
+// Server is a Sonarr stand-in serving the API paths used by sonarrapi.  Resources are kept as JSON objects, so any
+// fields sent by a client are returned unchanged.  Commands complete immediately with CommandStatus, and backup
+// commands add a backup.
+type Server struct {
+	*httptest.Server
+
+	APIKey        string
+	Status        sonarrapi.SystemStatus
+	Health        []sonarrapi.HealthCheck
+	CommandStatus string
+
+	// Lookup are the series found by series lookups, standing in for TheTVDB
+	Lookup []sonarrapi.Series
+
+	// QualityProfileSchema is returned as template for new quality profiles
+	QualityProfileSchema sonarrapi.QualityProfile
+
+	// TestFailures fails the connection tests of download clients, indexers and notifications with the names of its keys
+	TestFailures map[string]string
+
+	// MaskedFields are the provider fields returned masked, like Sonarr does for passwords and API keys
+	MaskedFields []string
+
+	mu        sync.Mutex
+	nextID    int
+	resources map[string][]map[string]interface{}
+	settings  map[string]map[string]interface{}
+	commands  map[int]sonarrapi.Command
+	requests  []string
+}
+
+// NewServer starts a Server accepting APIKey.  Close it when done.
+func NewServer() *Server {
+	s := &Server{
+		APIKey:        APIKey,
+		Status:        sonarrapi.SystemStatus{Version: "3.0.3.698", Branch: "main", AppData: "/config", IsDocker: true},
+		CommandStatus: sonarrapi.CommandCompleted,
+		QualityProfileSchema: sonarrapi.QualityProfile{
+			Cutoff: 1,
+			Items: []sonarrapi.QualityProfileItem{
+				{Quality: &sonarrapi.Quality{ID: 1, Name: "SDTV", Source: "television", Resolution: 480}, Items: []sonarrapi.QualityProfileItem{}},
+				{Quality: &sonarrapi.Quality{ID: 4, Name: "HDTV-720p", Source: "television", Resolution: 720}, Items: []sonarrapi.QualityProfileItem{}},
+				{Quality: &sonarrapi.Quality{ID: 9, Name: "HDTV-1080p", Source: "television", Resolution: 1080}, Items: []sonarrapi.QualityProfileItem{}},
+				{ID: 1000, Name: "WEB 1080p", Items: []sonarrapi.QualityProfileItem{
+					{Quality: &sonarrapi.Quality{ID: 15, Name: "WEBRip-1080p", Source: "webRip", Resolution: 1080}, Items: []sonarrapi.QualityProfileItem{}},
+					{Quality: &sonarrapi.Quality{ID: 3, Name: "WEBDL-1080p", Source: "web", Resolution: 1080}, Items: []sonarrapi.QualityProfileItem{}},
+				}},
+				{Quality: &sonarrapi.Quality{ID: 7, Name: "Bluray-1080p", Source: "bluray", Resolution: 1080}, Items: []sonarrapi.QualityProfileItem{}},
+			},
+		},
+		nextID:    1,
+		resources: map[string][]map[string]interface{}{},
+		settings: map[string]map[string]interface{}{
+			HostConfig: {
+				"id": 1, "bindAddress": "*", "port": 8989, "urlBase": "", "authenticationMethod": "none",
+				"logLevel": "info", "branch": "main", "analyticsEnabled": true, "enableSsl": false, "sslPort": 9898,
+				"sslCertPath": "", "apiKey": APIKey,
+			},
+			MediaManagementConfig: {
+				"id": 1, "createEmptySeriesFolders": false, "deleteEmptyFolders": false, "copyUsingHardlinks": true,
+				"importExtraFiles": false, "extraFileExtensions": "srt", "recycleBin": "", "recycleBinCleanupDays": 7,
+				"minimumFreeSpaceWhenImporting": 100, "setPermissionsLinux": false, "chmodFolder": "755",
+				"episodeTitleRequired": "always",
+			},
+		},
+		commands: map[int]sonarrapi.Command{},
+	}
+	s.Server = httptest.NewServer(http.HandlerFunc(s.serveHTTP))
+	return s
+}
+
+// Client returns a client for the server
+func (s *Server) Client() *sonarrapi.Client {
+	return sonarrapi.NewClient(s.URL, s.APIKey)
+}
+
+// Add stores item in resource and returns the ID assigned to it
+func (s *Server) Add(resource string, item interface{}) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	object, err := toObject(item)
+	if err != nil {
+		panic(err)
+	}
+	return s.add(resource, object)
+}
+
+// List decodes the items of resource into out, a pointer to a slice
+func (s *Server) List(resource string, out interface{}) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	data, err := json.Marshal(s.list(resource))
+	if err != nil {
+		panic(err)
+	}
+	if err := json.Unmarshal(data, out); err != nil {
+		panic(err)
+	}
+}
+
+// Settings decodes the settings name into out
+func (s *Server) Settings(name string, out interface{}) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	data, err := json.Marshal(s.settings[name])
+	if err != nil {
+		panic(err)
+	}
+	if err := json.Unmarshal(data, out); err != nil {
+		panic(err)
+	}
+}
+
+// SetSettings changes the settings name to the fields of item, e.g. to stand in for changes made in the Sonarr UI
+func (s *Server) SetSettings(name string, item interface{}) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	object, err := toObject(item)
+	if err != nil {
+		panic(err)
+	}
+	for field, value := range object {
+		s.settings[name][field] = value
+	}
+}
+
+// Requests returns the requests served so far as "METHOD PATH", with PATH relative to /api/v3
+func (s *Server) Requests() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]string(nil), s.requests...)
+}
+
+func toObject(item interface{}) (map[string]interface{}, error) {
+	data, err := json.Marshal(item)
+	if err != nil {
+		return nil, err
+	}
+	object := map[string]interface{}{}
+	return object, json.Unmarshal(data, &object)
+}
+
+func (s *Server) add(resource string, object map[string]interface{}) int {
+	id := s.nextID
+	s.nextID++
+	object["id"] = id
+	s.resources[resource] = append(s.resources[resource], object)
+	return id
+}
+
+func (s *Server) list(resource string) []map[string]interface{} {
+	items := s.resources[resource]
+	if items == nil {
+		return []map[string]interface{}{}
+	}
+	return items
+}
+
+// mask returns item with the values of its MaskedFields fields masked
+func (s *Server) mask(item map[string]interface{}) map[string]interface{} {
+	fields, ok := item["fields"].([]interface{})
+	if !ok || len(s.MaskedFields) == 0 {
+		return item
+	}
+	masked := map[string]interface{}{}
+	for k, v := range item {
+		masked[k] = v
+	}
+	maskedFields := make([]interface{}, len(fields))
+	for i, f := range fields {
+		maskedFields[i] = f
+		field, ok := f.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		for _, name := range s.MaskedFields {
+			if field["name"] == name && field["value"] != nil && field["value"] != "" {
+				maskedFields[i] = map[string]interface{}{"name": name, "value": "********"}
+			}
+		}
+	}
+	masked["fields"] = maskedFields
+	return masked
+}
+
+func (s *Server) find(resource string, id int) int {
+	for i, item := range s.resources[resource] {
+		if itemID, ok := item["id"].(int); ok && itemID == id {
+			return i
+		}
+		if itemID, ok := item["id"].(float64); ok && int(itemID) == id {
+			return i
+		}
+	}
+	return -1
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+func (s *Server) serveHTTP(w http.ResponseWriter, req *http.Request) {
+	if req.Header.Get("X-Api-Key") != s.APIKey {
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+	if !strings.HasPrefix(req.URL.Path, "/api/v3/") {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+	path := strings.TrimPrefix(req.URL.Path, "/api/v3/")
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.requests = append(s.requests, req.Method+" /"+path)
+
+	var body map[string]interface{}
+	if req.Method == http.MethodPost || req.Method == http.MethodPut {
+		data, err := ioutil.ReadAll(req.Body)
+		if err == nil {
+			err = json.Unmarshal(data, &body)
+		}
+		if err != nil {
+			writeJSON(w, http.StatusBadRequest, []map[string]string{{"errorMessage": err.Error()}})
+			return
+		}
+	}
+
+	switch {
+	case path == "system/status" && req.Method == http.MethodGet:
+		writeJSON(w, http.StatusOK, s.Status)
+	case path == "health" && req.Method == http.MethodGet:
+		health := s.Health
+		if health == nil {
+			health = []sonarrapi.HealthCheck{}
+		}
+		writeJSON(w, http.StatusOK, health)
+	case path == "command" && req.Method == http.MethodPost:
+		s.runCommand(w, body)
+	case strings.HasPrefix(path, "command/") && req.Method == http.MethodGet:
+		id, _ := strconv.Atoi(strings.TrimPrefix(path, "command/"))
+		command, ok := s.commands[id]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		writeJSON(w, http.StatusOK, command)
+	case path == "series/lookup" && req.Method == http.MethodGet:
+		found := []sonarrapi.Series{}
+		for _, series := range s.Lookup {
+			if req.URL.Query().Get("term") == fmt.Sprintf("tvdb:%d", series.TvdbID) {
+				found = append(found, series)
+			}
+		}
+		writeJSON(w, http.StatusOK, found)
+	case path == Series && req.Method == http.MethodGet && req.URL.Query().Get("tvdbId") != "":
+		found := []map[string]interface{}{}
+		for _, series := range s.list(Series) {
+			if fmt.Sprint(series["tvdbId"]) == req.URL.Query().Get("tvdbId") {
+				found = append(found, series)
+			}
+		}
+		writeJSON(w, http.StatusOK, found)
+	case strings.HasPrefix(path, "config/"):
+		s.serveSettings(w, req.Method, path, body)
+	case path == Backups && req.Method == http.MethodGet:
+		writeJSON(w, http.StatusOK, s.list(Backups))
+	default:
+		s.serveResource(w, req.Method, path, body)
+	}
+}
+
+func (s *Server) runCommand(w http.ResponseWriter, body map[string]interface{}) {
+	name, _ := body["name"].(string)
+	now := time.Now().UTC()
+	command := sonarrapi.Command{ID: s.nextID, Name: name, Status: s.CommandStatus, Queued: &now, Ended: &now}
+	s.nextID++
+	s.commands[command.ID] = command
+
+	if name == sonarrapi.BackupCommand && command.Status == sonarrapi.CommandCompleted {
+		backup := fmt.Sprintf("sonarr_backup_%s.zip", now.Format("2006.01.02_15.04.05"))
+		s.add(Backups, map[string]interface{}{
+			"name": backup,
+			"path": "/backup/manual/" + backup,
+			"type": "manual",
+			"time": now,
+		})
+	}
+	writeJSON(w, http.StatusCreated, command)
+}
+
+// serveSettings serves the get and update requests for the settings
+func (s *Server) serveSettings(w http.ResponseWriter, method string, path string, body map[string]interface{}) {
+	parts := strings.Split(path, "/")
+	settings, ok := s.settings[parts[0]+"/"+parts[1]]
+	if !ok || len(parts) > 3 {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+	switch {
+	case method == http.MethodGet && len(parts) == 2:
+		writeJSON(w, http.StatusOK, settings)
+	case method == http.MethodPut && len(parts) == 3:
+		for field, value := range body {
+			settings[field] = value
+		}
+		writeJSON(w, http.StatusAccepted, settings)
+	default:
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	}
+}
+
+// serveResource serves the list, create, get, update and delete requests for the resources
+func (s *Server) serveResource(w http.ResponseWriter, method string, path string, body map[string]interface{}) {
+	parts := strings.Split(path, "/")
+	resource := parts[0]
+	switch resource {
+	case RootFolders, DownloadClients, Indexers, QualityProfiles, ReleaseProfiles, CustomFormats, Series, LanguageProfiles, Notifications:
+	default:
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+
+	if resource == QualityProfiles && len(parts) == 2 && parts[1] == "schema" && method == http.MethodGet {
+		writeJSON(w, http.StatusOK, s.QualityProfileSchema)
+		return
+	}
+
+	if len(parts) == 1 {
+		switch method {
+		case http.MethodGet:
+			items := []map[string]interface{}{}
+			for _, item := range s.list(resource) {
+				items = append(items, s.mask(item))
+			}
+			writeJSON(w, http.StatusOK, items)
+		case http.MethodPost:
+			s.add(resource, body)
+			writeJSON(w, http.StatusCreated, body)
+		default:
+			w.WriteHeader(http.StatusMethodNotAllowed)
+		}
+		return
+	}
+
+	if len(parts) == 2 && parts[1] == "test" && method == http.MethodPost {
+		name, _ := body["name"].(string)
+		if message, ok := s.TestFailures[name]; ok {
+			writeJSON(w, http.StatusBadRequest, []sonarrapi.ValidationFailure{{ErrorMessage: message, Severity: "error"}})
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+
+	id, err := strconv.Atoi(parts[1])
+	if err != nil || len(parts) > 2 {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+	i := s.find(resource, id)
+	if i < 0 {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+	switch method {
+	case http.MethodGet:
+		writeJSON(w, http.StatusOK, s.mask(s.resources[resource][i]))
+	case http.MethodPut:
+		body["id"] = id
+		s.resources[resource][i] = body
+		writeJSON(w, http.StatusAccepted, body)
+	case http.MethodDelete:
+		items := s.resources[resource]
+		s.resources[resource] = append(items[:i:i], items[i+1:]...)
+		w.WriteHeader(http.StatusOK)
+	default:
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	}
+}
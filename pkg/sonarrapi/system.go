@@ -0,0 +1,53 @@
+package sonarrapi
+
+import (
+	"context"
+	"net/http"
+	"time"
+)
+
+// Health check types reported by Sonarr
+const (
+	HealthOK      = "ok"
+	HealthNotice  = "notice"
+	HealthWarning = "warning"
+	HealthError   = "error"
+)
+
+// SystemStatus describes the running Sonarr instance
+type SystemStatus struct {
+	Version        string    `json:"version"`
+	BuildTime      time.Time `json:"buildTime"`
+	StartTime      time.Time `json:"startTime"`
+	Branch         string    `json:"branch"`
+	AppData        string    `json:"appData"`
+	URLBase        string    `json:"urlBase"`
+	Authentication string    `json:"authentication"`
+	IsDocker       bool      `json:"isDocker"`
+}
+
+// HealthCheck is a problem found by the health checks of Sonarr
+type HealthCheck struct {
+	Source  string `json:"source"`
+	Type    string `json:"type"`
+	Message string `json:"message"`
+	WikiURL string `json:"wikiUrl,omitempty"`
+}
+
+// GetSystemStatus returns the status of Sonarr
+func (c *Client) GetSystemStatus(ctx context.Context) (*SystemStatus, error) {
+	status := &SystemStatus{}
+	if err := c.do(ctx, http.MethodGet, "/system/status", nil, status); err != nil {
+		return nil, err
+	}
+	return status, nil
+}
+
+// GetHealth returns the failing health checks of Sonarr
+func (c *Client) GetHealth(ctx context.Context) ([]HealthCheck, error) {
+	var checks []HealthCheck
+	if err := c.do(ctx, http.MethodGet, "/health", nil, &checks); err != nil {
+		return nil, err
+	}
+	return checks, nil
+}